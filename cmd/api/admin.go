@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/ledger"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/moderation"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	adminKeyHeader       = "X-Admin-Key"
+	adminPubkeyHeader    = "X-Admin-Pubkey"
+	adminTimestampHeader = "X-Admin-Timestamp"
+	adminSignatureHeader = "X-Admin-Signature"
+	adminRequestTTL      = 5 * time.Minute
+	adminActorLocal      = "admin"
+)
+
+var categorySlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// requireAdmin accepts requests carrying an allowlisted API key or signed by an allowlisted
+// wallet. A wallet signs "<METHOD> <path> <unix ms timestamp> <hex sha256 of the body>"
+// and sends the base58 signature, the hash of an empty body is signed for requests without one.
+func (s *server) requireAdmin(c *fiber.Ctx) error {
+	if key := c.Get(adminKeyHeader); key != "" {
+		for i, allowed := range s.adminConfig.ApiKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(allowed)) == 1 {
+				c.Locals(adminActorLocal, "api-key:"+strconv.Itoa(i))
+				return c.Next()
+			}
+		}
+		return fiber.NewError(fiber.StatusUnauthorized, "admin key is not valid")
+	}
+	pubkey, err := solana.PublicKeyFromBase58(c.Get(adminPubkeyHeader))
+	if err != nil || !slices.Contains(s.adminConfig.Pubkeys, pubkey.String()) {
+		return fiber.NewError(fiber.StatusUnauthorized, "admin credentials required")
+	}
+	timestamp, err := strconv.ParseInt(c.Get(adminTimestampHeader), 10, 64)
+	if err != nil || time.Since(time.UnixMilli(timestamp)).Abs() > adminRequestTTL {
+		return fiber.NewError(fiber.StatusUnauthorized, "admin request expired")
+	}
+	signature, err := solana.SignatureFromBase58(c.Get(adminSignatureHeader))
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "admin signature is not valid")
+	}
+	if !pubkey.Verify(signedHeadersMessage(c, timestamp), signature) {
+		return fiber.NewError(fiber.StatusUnauthorized, "admin signature is not valid")
+	}
+	c.Locals(adminActorLocal, pubkey.String())
+	return c.Next()
+}
+
+func adminActor(c *fiber.Ctx) string {
+	actor, _ := c.Locals(adminActorLocal).(string)
+	return actor
+}
+
+func (s *server) adminListMarkets(c *fiber.Ctx) error {
+	filter, err := queryMarketFilter(c)
+	if err != nil {
+		return err
+	}
+	filter.IncludeHidden = true
+	limit, offset, err := queryPage(c)
+	if err != nil {
+		return err
+	}
+	markets, err := s.predictionRepo.ListMarkets(c.UserContext(), filter, limit, offset)
+	if err != nil {
+		return fmt.Errorf("list markets: %w", err)
+	}
+	return c.JSON(fiber.Map{"markets": markets})
+}
+
+func (s *server) adminMarketReports(c *fiber.Ctx) error {
+	reports, err := s.moderationRepo.ListReports(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return fmt.Errorf("list reports: %w", err)
+	}
+	return c.JSON(fiber.Map{"reports": reports})
+}
+
+func (s *server) adminSetModeration(c *fiber.Ctx) error {
+	type Request struct {
+		Status prediction.ModerationStatus `json:"status"`
+		Reason string                      `json:"reason"`
+	}
+	var request Request
+	if err := json.Unmarshal(c.Body(), &request); err != nil {
+		return fmt.Errorf("unmarshal request: %w", err)
+	}
+	if !request.Status.Valid() {
+		return fiber.NewError(fiber.StatusBadRequest, "status must be one of VISIBLE, HIDDEN, FLAGGED, REMOVED")
+	}
+	marketID := c.Params("id")
+	var previous prediction.ModerationStatus
+	err := s.moderationRepo.RunInTx(c.UserContext(), func(ctx context.Context) error {
+		var err error
+		if previous, err = s.moderationRepo.SetModerationStatus(ctx, marketID, request.Status); err != nil {
+			return err
+		}
+		return s.moderationRepo.AddAuditEntry(ctx, moderation.AuditEntry{
+			Actor:      adminActor(c),
+			Action:     moderation.ActionSetModeration,
+			TargetType: moderation.TargetMarket,
+			TargetID:   marketID,
+			Details: map[string]any{
+				"from":   previous,
+				"to":     request.Status,
+				"reason": request.Reason,
+			},
+			CreatedAt: time.Now(),
+		})
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "market not found")
+	} else if err != nil {
+		return fmt.Errorf("set moderation status: %w", err)
+	}
+	return c.JSON(fiber.Map{"previous": previous, "status": request.Status})
+}
+
+func (s *server) adminCreateCategory(c *fiber.Ctx) error {
+	type Request struct {
+		Slug        string `json:"slug"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	var request Request
+	if err := json.Unmarshal(c.Body(), &request); err != nil {
+		return fmt.Errorf("unmarshal request: %w", err)
+	}
+	if !categorySlugPattern.MatchString(request.Slug) || request.Name == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "slug must be lowercase alphanumeric with dashes and name is required")
+	}
+	category := prediction.Category{
+		Slug:        request.Slug,
+		Name:        request.Name,
+		Description: zeronull.Text(request.Description),
+		CreatedAt:   time.Now(),
+	}
+	err := s.predictionRepo.RunInTx(c.UserContext(), func(ctx context.Context) error {
+		if err := s.predictionRepo.CreateCategory(ctx, category); err != nil {
+			return err
+		}
+		return s.moderationRepo.AddAuditEntry(ctx, moderation.AuditEntry{
+			Actor:      adminActor(c),
+			Action:     moderation.ActionCreateCategory,
+			TargetType: moderation.TargetCategory,
+			TargetID:   category.Slug,
+			Details:    map[string]any{"name": category.Name},
+			CreatedAt:  category.CreatedAt,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("create category: %w", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(category)
+}
+
+func (s *server) adminSetFeeSchedule(c *fiber.Ctx) error {
+	var schedule ledger.Schedule
+	if err := json.Unmarshal(c.Body(), &schedule); err != nil {
+		return fmt.Errorf("unmarshal request: %w", err)
+	}
+	schedule.Category = c.Params("category")
+	schedule.UpdatedAt = time.Now()
+	for _, bps := range []int32{
+		schedule.PositionProtocolBps,
+		schedule.PositionCreatorBps,
+		schedule.SettlementProtocolBps,
+		schedule.SettlementCreatorBps,
+	} {
+		if bps < 0 || bps > 10_000 {
+			return fiber.NewError(fiber.StatusBadRequest, "fees must be between 0 and 10000 bps")
+		}
+	}
+	err := s.ledgerRepo.RunInTx(c.UserContext(), func(ctx context.Context) error {
+		if err := s.ledgerRepo.SaveSchedule(ctx, schedule); err != nil {
+			return err
+		}
+		return s.moderationRepo.AddAuditEntry(ctx, moderation.AuditEntry{
+			Actor:      adminActor(c),
+			Action:     moderation.ActionSetFeeSchedule,
+			TargetType: moderation.TargetFeeSchedule,
+			TargetID:   schedule.Category,
+			Details: map[string]any{
+				"position_protocol_bps":   schedule.PositionProtocolBps,
+				"position_creator_bps":    schedule.PositionCreatorBps,
+				"settlement_protocol_bps": schedule.SettlementProtocolBps,
+				"settlement_creator_bps":  schedule.SettlementCreatorBps,
+			},
+			CreatedAt: schedule.UpdatedAt,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("save fee schedule: %w", err)
+	}
+	return c.JSON(schedule)
+}
+
+func (s *server) adminAuditLog(c *fiber.Ctx) error {
+	limit, offset, err := queryPage(c)
+	if err != nil {
+		return err
+	}
+	entries, err := s.moderationRepo.ListAuditEntries(c.UserContext(), limit, offset)
+	if err != nil {
+		return fmt.Errorf("list audit entries: %w", err)
+	}
+	return c.JSON(fiber.Map{"entries": entries})
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/comment"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/moderation"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/nanoid"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultCommentPageSize = 20
+	maxCommentPageSize     = 100
+)
+
+var commentReactions = []string{"like", "dislike", "laugh", "fire", "think"}
+
+func (s *server) listComments(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", defaultCommentPageSize)
+	if limit <= 0 || limit > maxCommentPageSize {
+		return fiber.NewError(fiber.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxCommentPageSize))
+	}
+	var after *comment.Cursor
+	if value := c.Query("cursor"); value != "" {
+		createdAt, id, err := decodeCursor(value)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "cursor is not valid")
+		}
+		after = &comment.Cursor{CreatedAt: createdAt, ID: id}
+	}
+	comments, err := s.commentRepo.ListComments(c.UserContext(), c.Params("id"), c.Query("parent"), after, limit)
+	if err != nil {
+		return fmt.Errorf("list comments: %w", err)
+	}
+	var next string
+	if len(comments) == limit {
+		last := comments[len(comments)-1]
+		next = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return c.JSON(fiber.Map{
+		"comments":    comments,
+		"next_cursor": next,
+	})
+}
+
+func (s *server) createComment(c *fiber.Ctx) error {
+	type CommentData struct {
+		Author   string `json:"author"`
+		MarketID string `json:"marketID"`
+		ParentID string `json:"parentId"`
+		Body     string `json:"body"`
+	}
+	var commentData CommentData
+	authorPubkey, err := verifySignedRequest(c, signedActionCreateComment, &commentData, &commentData.Author)
+	if err != nil {
+		return err
+	}
+	marketID := c.Params("id")
+	if commentData.MarketID != marketID {
+		return fiber.NewError(fiber.StatusBadRequest, "signed market does not match")
+	}
+	body, err := s.commentBody(commentData.Body)
+	if err != nil {
+		return err
+	}
+	ctx := c.UserContext()
+	market, err := s.predictionRepo.GetMarket(ctx, marketID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !commentable(market)) {
+		return fiber.NewError(fiber.StatusNotFound, "market not found")
+	} else if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	var parent comment.Comment
+	if commentData.ParentID != "" {
+		parent, err = s.commentRepo.GetComment(ctx, commentData.ParentID)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && (parent.MarketID != marketID || parent.Status != comment.StatusVisible)) {
+			return fiber.NewError(fiber.StatusBadRequest, "parent comment not found")
+		} else if err != nil {
+			return fmt.Errorf("get parent comment: %w", err)
+		}
+	}
+	now := time.Now()
+	created := comment.Comment{
+		ID:           nanoid.RandomID(),
+		MarketID:     marketID,
+		ParentID:     zeronull.Text(commentData.ParentID),
+		AuthorPubkey: authorPubkey.String(),
+		Body:         body,
+		Status:       comment.StatusVisible,
+		CreatedAt:    now,
+		Reactions:    map[string]int64{},
+	}
+	err = s.commentRepo.RunInTx(ctx, func(ctx context.Context) error {
+		// Concurrent comments of the author wait here, so they all see each other in the count
+		if err := s.commentRepo.LockAuthor(ctx, authorPubkey.String()); err != nil {
+			return fmt.Errorf("lock author: %w", err)
+		}
+		recent, err := s.commentRepo.CountRecent(ctx, authorPubkey.String(), now.Add(-s.commentsConfig.RateWindow))
+		if err != nil {
+			return fmt.Errorf("count recent comments: %w", err)
+		}
+		if recent >= s.commentsConfig.RateLimit {
+			return fiber.NewError(fiber.StatusTooManyRequests, "too many comments, try again later")
+		}
+		if err = s.commentRepo.CreateComment(ctx, created); err != nil {
+			return fmt.Errorf("create comment: %w", err)
+		}
+		if parent.ID == "" {
+			return nil
+		}
+		return s.notifier.CommentReply(ctx, parent, created)
+	})
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+func (s *server) editComment(c *fiber.Ctx) error {
+	type EditData struct {
+		Author    string `json:"author"`
+		CommentID string `json:"commentID"`
+		Body      string `json:"body"`
+	}
+	var editData EditData
+	authorPubkey, err := verifySignedRequest(c, signedActionEditComment, &editData, &editData.Author)
+	if err != nil {
+		return err
+	}
+	commentID := c.Params("id")
+	if editData.CommentID != commentID {
+		return fiber.NewError(fiber.StatusBadRequest, "signed comment does not match")
+	}
+	body, err := s.commentBody(editData.Body)
+	if err != nil {
+		return err
+	}
+	ctx := c.UserContext()
+	edited, err := s.authoredComment(ctx, commentID, authorPubkey.String(), s.commentsConfig.EditWindow)
+	if err != nil {
+		return err
+	}
+	edited.Body = body
+	edited.EditedAt = zeronull.Timestamptz(time.Now())
+	if err = s.commentRepo.EditComment(ctx, commentID, edited.Body, time.Time(edited.EditedAt)); err != nil {
+		return fmt.Errorf("edit comment: %w", err)
+	}
+	return c.JSON(edited)
+}
+
+func (s *server) deleteComment(c *fiber.Ctx) error {
+	type DeleteData struct {
+		Author    string `json:"author"`
+		CommentID string `json:"commentID"`
+	}
+	var deleteData DeleteData
+	authorPubkey, err := verifySignedRequest(c, signedActionDeleteComment, &deleteData, &deleteData.Author)
+	if err != nil {
+		return err
+	}
+	commentID := c.Params("id")
+	if deleteData.CommentID != commentID {
+		return fiber.NewError(fiber.StatusBadRequest, "signed comment does not match")
+	}
+	ctx := c.UserContext()
+	if _, err = s.authoredComment(ctx, commentID, authorPubkey.String(), s.commentsConfig.DeleteWindow); err != nil {
+		return err
+	}
+	if err = s.commentRepo.DeleteComment(ctx, commentID, time.Now()); err != nil {
+		return fmt.Errorf("delete comment: %w", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// reactComment adds a reaction of the wallet to the comment or removes it
+func (s *server) reactComment(c *fiber.Ctx) error {
+	type ReactionData struct {
+		Reactor   string `json:"reactor"`
+		CommentID string `json:"commentID"`
+		Reaction  string `json:"reaction"`
+		Remove    bool   `json:"remove"`
+	}
+	var reactionData ReactionData
+	reactorPubkey, err := verifySignedRequest(c, signedActionReactComment, &reactionData, &reactionData.Reactor)
+	if err != nil {
+		return err
+	}
+	commentID := c.Params("id")
+	if reactionData.CommentID != commentID {
+		return fiber.NewError(fiber.StatusBadRequest, "signed comment does not match")
+	}
+	if !slices.Contains(commentReactions, reactionData.Reaction) {
+		return fiber.NewError(fiber.StatusBadRequest, "reaction must be one of "+strings.Join(commentReactions, ", "))
+	}
+	ctx := c.UserContext()
+	target, err := s.commentRepo.GetComment(ctx, commentID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && target.Status != comment.StatusVisible) {
+		return fiber.NewError(fiber.StatusNotFound, "comment not found")
+	} else if err != nil {
+		return fmt.Errorf("get comment: %w", err)
+	}
+	reaction := comment.Reaction{
+		CommentID:     commentID,
+		ReactorPubkey: reactorPubkey.String(),
+		Reaction:      reactionData.Reaction,
+		CreatedAt:     time.Now(),
+	}
+	if reactionData.Remove {
+		_, err = s.commentRepo.RemoveReaction(ctx, reaction)
+	} else {
+		_, err = s.commentRepo.AddReaction(ctx, reaction)
+	}
+	if err != nil {
+		return fmt.Errorf("react to comment: %w", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (s *server) reportComment(c *fiber.Ctx) error {
+	type ReportData struct {
+		Reporter  string `json:"reporter"`
+		CommentID string `json:"commentID"`
+		Reason    string `json:"reason"`
+	}
+	var reportData ReportData
+	reporterPubkey, err := verifySignedRequest(c, signedActionReportComment, &reportData, &reportData.Reporter)
+	if err != nil {
+		return err
+	}
+	commentID := c.Params("id")
+	if reportData.CommentID != commentID {
+		return fiber.NewError(fiber.StatusBadRequest, "signed comment does not match")
+	}
+	if reportData.Reason == "" || len(reportData.Reason) > maxReportReasonLength {
+		return fiber.NewError(fiber.StatusBadRequest, "reason is required and must be short")
+	}
+	ctx := c.UserContext()
+	if _, err = s.commentRepo.GetComment(ctx, commentID); errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "comment not found")
+	} else if err != nil {
+		return fmt.Errorf("get comment: %w", err)
+	}
+	report := comment.Report{
+		ID:             nanoid.RandomID(),
+		CommentID:      commentID,
+		ReporterPubkey: reporterPubkey.String(),
+		Reason:         reportData.Reason,
+		CreatedAt:      time.Now(),
+	}
+	err = s.commentRepo.RunInTx(ctx, func(ctx context.Context) error {
+		created, err := s.commentRepo.CreateReport(ctx, report)
+		if err != nil || !created {
+			return err
+		}
+		return s.hideReportedComment(ctx, commentID)
+	})
+	if err != nil {
+		return fmt.Errorf("report comment: %w", err)
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// hideReportedComment hides a visible comment once it collected enough reports
+func (s *server) hideReportedComment(ctx context.Context, commentID string) error {
+	reports, err := s.commentRepo.CountReports(ctx, commentID)
+	if err != nil {
+		return fmt.Errorf("count reports: %w", err)
+	}
+	if reports < s.commentsConfig.HideThreshold {
+		return nil
+	}
+	reported, err := s.commentRepo.GetComment(ctx, commentID)
+	if err != nil {
+		return fmt.Errorf("get comment: %w", err)
+	}
+	if reported.Status != comment.StatusVisible {
+		return nil
+	}
+	if _, err = s.commentRepo.SetStatus(ctx, commentID, comment.StatusHidden); err != nil {
+		return fmt.Errorf("hide comment: %w", err)
+	}
+	return s.moderationRepo.AddAuditEntry(ctx, moderation.AuditEntry{
+		Actor:      moderation.ActorSystem,
+		Action:     moderation.ActionAutoHide,
+		TargetType: moderation.TargetComment,
+		TargetID:   commentID,
+		Details:    map[string]any{"reports": reports},
+		CreatedAt:  time.Now(),
+	})
+}
+
+func (s *server) adminSetCommentStatus(c *fiber.Ctx) error {
+	type Request struct {
+		Status comment.Status `json:"status"`
+		Reason string         `json:"reason"`
+	}
+	var request Request
+	if err := json.Unmarshal(c.Body(), &request); err != nil {
+		return fmt.Errorf("unmarshal request: %w", err)
+	}
+	if !request.Status.Valid() {
+		return fiber.NewError(fiber.StatusBadRequest, "status must be one of VISIBLE, HIDDEN, DELETED")
+	}
+	commentID := c.Params("id")
+	var previous comment.Status
+	err := s.commentRepo.RunInTx(c.UserContext(), func(ctx context.Context) error {
+		var err error
+		if previous, err = s.commentRepo.SetStatus(ctx, commentID, request.Status); err != nil {
+			return err
+		}
+		return s.moderationRepo.AddAuditEntry(ctx, moderation.AuditEntry{
+			Actor:      adminActor(c),
+			Action:     moderation.ActionSetComment,
+			TargetType: moderation.TargetComment,
+			TargetID:   commentID,
+			Details: map[string]any{
+				"from":   previous,
+				"to":     request.Status,
+				"reason": request.Reason,
+			},
+			CreatedAt: time.Now(),
+		})
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "comment not found")
+	} else if err != nil {
+		return fmt.Errorf("set comment status: %w", err)
+	}
+	return c.JSON(fiber.Map{"previous": previous, "status": request.Status})
+}
+
+// commentBody trims the body and checks its length
+func (s *server) commentBody(raw string) (string, error) {
+	body := strings.TrimSpace(raw)
+	if body == "" || utf8.RuneCountInString(body) > s.commentsConfig.MaxLength {
+		return "", fiber.NewError(fiber.StatusBadRequest,
+			fmt.Sprintf("comment must be 1 to %d characters", s.commentsConfig.MaxLength))
+	}
+	return body, nil
+}
+
+// authoredComment returns the visible comment of the author while it is younger than window
+func (s *server) authoredComment(ctx context.Context, id, author string, window time.Duration) (comment.Comment, error) {
+	authored, err := s.commentRepo.GetComment(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && authored.Status != comment.StatusVisible) {
+		return comment.Comment{}, fiber.NewError(fiber.StatusNotFound, "comment not found")
+	} else if err != nil {
+		return comment.Comment{}, fmt.Errorf("get comment: %w", err)
+	}
+	if authored.AuthorPubkey != author {
+		return comment.Comment{}, fiber.NewError(fiber.StatusForbidden, "wallet is not the comment author")
+	}
+	if time.Since(authored.CreatedAt) > window {
+		return comment.Comment{}, fiber.NewError(fiber.StatusConflict, "comment can no longer be changed")
+	}
+	return authored, nil
+}
+
+// commentable markets are shown in listings
+func commentable(market prediction.Market) bool {
+	return market.ModerationStatus != prediction.ModerationStatusHidden &&
+		market.ModerationStatus != prediction.ModerationStatusRemoved
+}
@@ -3,11 +3,22 @@ package main
 import (
 	"github.com/IndexStorm/hit-my-bet-back/internal/config"
 	"github.com/rs/zerolog"
+	"time"
 )
 
 type appConfig struct {
 	Environment config.DefaultEnvironment
 	LogLevel    zerolog.Level `env:"LOG_LEVEL,notEmpty"`
 	Telemetry   config.Telemetry
-	Database    config.Database `envPrefix:"DB_" env:"notEmpty"`
+	Database    config.Database  `envPrefix:"DB_" env:"notEmpty"`
+	Solana      config.Solana    `envPrefix:"SOLANA_"`
+	Pricing     config.Pricing   `envPrefix:"PRICING_"`
+	Admin       config.Admin     `envPrefix:"ADMIN_"`
+	Dispute     config.Dispute   `envPrefix:"DISPUTE_"`
+	Oracle      config.Oracle    `envPrefix:"ORACLE_"`
+	Scheduler   config.Scheduler `envPrefix:"SCHEDULER_"`
+	Comments    config.Comments  `envPrefix:"COMMENTS_"`
+	Trending    config.Trending  `envPrefix:"TRENDING_"`
+
+	PortfolioCacheTTL time.Duration `env:"PORTFOLIO_CACHE_TTL" envDefault:"15s"`
 }
@@ -4,8 +4,39 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/arbitration"
+	"github.com/IndexStorm/hit-my-bet-back/internal/chain"
+	"github.com/IndexStorm/hit-my-bet-back/internal/fees"
+	"github.com/IndexStorm/hit-my-bet-back/internal/idl"
+	"github.com/IndexStorm/hit-my-bet-back/internal/indexer"
+	"github.com/IndexStorm/hit-my-bet-back/internal/lifecycle"
+	"github.com/IndexStorm/hit-my-bet-back/internal/notify"
+	"github.com/IndexStorm/hit-my-bet-back/internal/oracle"
+	"github.com/IndexStorm/hit-my-bet-back/internal/portfolio"
 	"github.com/IndexStorm/hit-my-bet-back/internal/postgres"
+	"github.com/IndexStorm/hit-my-bet-back/internal/pricing"
+	"github.com/IndexStorm/hit-my-bet-back/internal/program"
+	"github.com/IndexStorm/hit-my-bet-back/internal/ranking"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/comment"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/dispute"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/feed"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/history"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/leaderboard"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/ledger"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/moderation"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/notification"
 	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/profile"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/relay"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/resolver"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/social"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/trending"
+	"github.com/IndexStorm/hit-my-bet-back/internal/rpcpool"
+	"github.com/IndexStorm/hit-my-bet-back/internal/scheduler"
+	"github.com/IndexStorm/hit-my-bet-back/internal/settlement"
+	"github.com/IndexStorm/hit-my-bet-back/internal/sns"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
@@ -35,10 +66,199 @@ func (b *dependencyBuilder) build(ctx context.Context) (*applicationDependencies
 	predictionRepo := prediction.NewPostgres(db)
 	dependencies.predictionRepo = predictionRepo
 
-	appServer := newServer(b.logger, otel.Tracer("server"), predictionRepo)
+	solanaClient, err := rpcpool.NewSolanaClient(b.config.Solana, b.logger.With().Str("sys", "rpcpool").Logger())
+	if err != nil {
+		return nil, fmt.Errorf("prepare solana client: %w", err)
+	}
+	dependencies.solanaClient = solanaClient
+
+	rebroadcaster := chain.NewRebroadcaster(
+		solanaClient,
+		relay.NewPostgres(db),
+		b.logger.With().Str("sys", "rebroadcast").Logger(),
+		b.config.Solana.RebroadcastInterval,
+	)
+	dependencies.rebroadcaster = rebroadcaster
+
+	decoder, err := b.newProgramDecoder()
+	if err != nil {
+		return nil, fmt.Errorf("prepare program decoder: %w", err)
+	}
+
+	pricingModel, err := pricing.New(pricing.Config{
+		Model:     b.config.Pricing.Model,
+		Liquidity: b.config.Pricing.LmsrLiquidity,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("prepare pricing model: %w", err)
+	}
+	historyRepo := history.NewPostgres(db)
+	ledgerRepo := ledger.NewPostgres(db)
+	accountant := fees.NewAccountant(ledgerRepo)
+	disputeRepo := dispute.NewPostgres(db)
+	notificationRepo := notification.NewPostgres(db)
+	notifier := notify.NewNotifier(notificationRepo, predictionRepo)
+	settler := settlement.NewSettler(predictionRepo, disputeRepo, accountant, notifier)
+	court := arbitration.NewCourt(disputeRepo, predictionRepo, notifier, b.config.Dispute.Period)
+	applier := indexer.NewApplier(
+		decoder,
+		pricingModel,
+		predictionRepo,
+		historyRepo,
+		settler,
+		court,
+		accountant,
+		b.logger.With().Str("sys", "indexer").Logger(),
+	)
+
+	listener, err := b.startChainListener(dependencies, solanaClient, applier, rebroadcaster)
+	if err != nil {
+		return nil, fmt.Errorf("start chain listener: %w", err)
+	}
+
+	resolverRepo := resolver.NewPostgres(db)
+	trendingRepo := trending.NewPostgres(db)
+	if err = b.startScheduler(dependencies, db, predictionRepo, resolverRepo, historyRepo, trendingRepo, notifier); err != nil {
+		return nil, fmt.Errorf("start scheduler: %w", err)
+	}
+
+	pythProgramIDs, err := oracle.ParseProgramIDs(b.config.Oracle.PythProgramIDs)
+	if err != nil {
+		return nil, fmt.Errorf("prepare oracle registry: %w", err)
+	}
+	oracles := oracle.NewRegistry(
+		oracle.NewPythAdapter(solanaClient, pythProgramIDs),
+		oracle.NewHTTPAdapter(oracle.NewHTTPClient(b.config.Oracle.HttpTimeout), b.config.Oracle.HttpAllowedHosts),
+	)
+	appServer := newServer(
+		b.logger,
+		otel.Tracer("server"),
+		predictionRepo,
+		historyRepo,
+		rebroadcaster,
+		listener,
+		decoder,
+		pricingModel,
+		accountant,
+		ledgerRepo,
+		portfolio.NewService(predictionRepo, pricingModel, b.config.PortfolioCacheTTL),
+		leaderboard.NewPostgres(db),
+		moderation.NewPostgres(db),
+		b.config.Admin,
+		court,
+		disputeRepo,
+		b.config.Dispute,
+		resolverRepo,
+		feed.NewPostgres(db),
+		oracles,
+		comment.NewPostgres(db),
+		b.config.Comments,
+		profile.NewPostgres(db),
+		sns.NewResolver(solanaClient),
+		social.NewPostgres(db),
+		notificationRepo,
+		notifier,
+		trendingRepo,
+		b.config.Trending,
+	)
 	dependencies.server = appServer
 
-	return &applicationDependencies{server: appServer}, nil
+	rebroadcaster.Handle(txKindMarketInit, appServer.onMarketInitTx)
+	if err = rebroadcaster.Start(ctx); err != nil {
+		return nil, fmt.Errorf("start rebroadcaster: %w", err)
+	}
+
+	return dependencies, nil
+}
+
+// startChainListener follows the program over websocket when a program id is configured,
+// feeding account updates to the applier and signatures to the rebroadcaster
+func (b *dependencyBuilder) startChainListener(
+	dependencies *applicationDependencies,
+	solanaClient *rpc.Client,
+	applier *indexer.Applier,
+	rebroadcaster *chain.Rebroadcaster,
+) (*chain.Listener, error) {
+	if b.config.Solana.ProgramID == "" {
+		return nil, nil
+	}
+	programID, err := solana.PublicKeyFromBase58(b.config.Solana.ProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("parse program id: %w", err)
+	}
+	bus := chain.NewBus()
+	listener := chain.NewListener(
+		chain.ListenerConfig{
+			WsURL:      b.config.Solana.WsURL,
+			ProgramID:  programID,
+			StaleAfter: b.config.Solana.WsStaleAfter,
+		},
+		solanaClient,
+		bus,
+		b.logger.With().Str("sys", "listener").Logger(),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	dependencies.stopBackground = cancel
+
+	accountEvents, _ := bus.Subscribe(256)
+	go applier.Consume(ctx, accountEvents)
+	signatureEvents, _ := bus.Subscribe(256)
+	go rebroadcaster.Consume(ctx, signatureEvents)
+	go listener.Run(ctx)
+	return listener, nil
+}
+
+// startScheduler runs the lifecycle jobs on whichever replica holds the scheduler lock
+func (b *dependencyBuilder) startScheduler(
+	dependencies *applicationDependencies,
+	db *pgxpool.Pool,
+	predictionRepo prediction.Repository,
+	resolverRepo resolver.Repository,
+	historyRepo history.Repository,
+	trendingRepo trending.Repository,
+	notifier *notify.Notifier,
+) error {
+	metrics, err := scheduler.NewMetrics(otel.Meter("scheduler"))
+	if err != nil {
+		return fmt.Errorf("create scheduler metrics: %w", err)
+	}
+	logger := b.logger.With().Str("sys", "scheduler").Logger()
+	jobs := lifecycle.NewJobs(b.config.Scheduler, predictionRepo, resolverRepo, historyRepo, notifier, logger)
+	trendingJob := ranking.NewTrending(b.config.Trending, trendingRepo, logger)
+	jobScheduler := scheduler.New(
+		scheduler.Config{
+			LockKey:             b.config.Scheduler.LockKey,
+			LeaderCheckInterval: b.config.Scheduler.LeaderCheckInterval,
+		},
+		db,
+		append(jobs.Scheduled(), trendingJob.Scheduled()...),
+		metrics,
+		logger,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	dependencies.stopScheduler = cancel
+	go jobScheduler.Run(ctx)
+	return nil
+}
+
+// newProgramDecoder loads the program IDL when configured, otherwise the decoder
+// falls back to the default Anchor account layout and market init is refused
+func (b *dependencyBuilder) newProgramDecoder() (*program.Decoder, error) {
+	var programID solana.PublicKey
+	if b.config.Solana.ProgramID != "" {
+		var err error
+		if programID, err = solana.PublicKeyFromBase58(b.config.Solana.ProgramID); err != nil {
+			return nil, fmt.Errorf("parse program id: %w", err)
+		}
+	}
+	if b.config.Solana.IdlPath == "" {
+		return program.NewDecoder(programID, nil), nil
+	}
+	programIDL, err := idl.Load(b.config.Solana.IdlPath)
+	if err != nil {
+		return nil, fmt.Errorf("load idl: %w", err)
+	}
+	return program.NewDecoder(programID, programIDL), nil
 }
 
 func (b *dependencyBuilder) newDatabase(ctx context.Context) (*pgxpool.Pool, error) {
@@ -50,6 +270,10 @@ func (b *dependencyBuilder) newDatabase(ctx context.Context) (*pgxpool.Pool, err
 type applicationDependencies struct {
 	database       *pgxpool.Pool
 	predictionRepo prediction.Repository
+	solanaClient   *rpc.Client
+	rebroadcaster  *chain.Rebroadcaster
+	stopBackground context.CancelFunc
+	stopScheduler  context.CancelFunc
 	server         *server
 }
 
@@ -60,6 +284,22 @@ func (d *applicationDependencies) Close() error {
 			errs = append(errs, fmt.Errorf("close server: %w", err))
 		}
 	}
+	if d.stopBackground != nil {
+		d.stopBackground()
+	}
+	if d.stopScheduler != nil {
+		d.stopScheduler()
+	}
+	if d.rebroadcaster != nil {
+		if err := d.rebroadcaster.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close rebroadcaster: %w", err))
+		}
+	}
+	if d.solanaClient != nil {
+		if err := d.solanaClient.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close solana client: %w", err))
+		}
+	}
 	if d.database != nil {
 		d.database.Close()
 	}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/arbitration"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/dispute"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/moderation"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/nanoid"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const (
+	maxEvidenceLength   = 4000
+	maxRulingNoteLength = 1000
+)
+
+func (s *server) marketResolution(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	marketID := c.Params("id")
+	resolution, err := s.disputeRepo.GetResolution(ctx, marketID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "market has no proposed resolution")
+	} else if err != nil {
+		return fmt.Errorf("get resolution: %w", err)
+	}
+	challenges, err := s.disputeRepo.ListChallenges(ctx, marketID)
+	if err != nil {
+		return fmt.Errorf("list challenges: %w", err)
+	}
+	return c.JSON(fiber.Map{
+		"resolution": resolution,
+		"challenges": challenges,
+	})
+}
+
+func (s *server) challengeResolution(c *fiber.Ctx) error {
+	type ChallengeData struct {
+		Challenger  string                      `json:"challenger"`
+		MarketID    string                      `json:"marketID"`
+		Outcome     prediction.MarketResolution `json:"outcome"`
+		OutcomeID   *int16                      `json:"outcomeId"`
+		Value       *float64                    `json:"value"`
+		Evidence    string                      `json:"evidence"`
+		EvidenceURL string                      `json:"evidenceUrl"`
+	}
+	var challengeData ChallengeData
+	challengerPubkey, err := verifySignedRequest(c, signedActionChallengeResolution, &challengeData, &challengeData.Challenger)
+	if err != nil {
+		return err
+	}
+	marketID := c.Params("id")
+	if challengeData.MarketID != marketID {
+		return fiber.NewError(fiber.StatusBadRequest, "signed market does not match")
+	}
+	if challengeData.Evidence == "" || len(challengeData.Evidence) > maxEvidenceLength {
+		return fiber.NewError(fiber.StatusBadRequest, "evidence is required and must be short")
+	}
+	if challengeData.EvidenceURL != "" {
+		if u, err := url.Parse(challengeData.EvidenceURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+			return fiber.NewError(fiber.StatusBadRequest, "evidence url is not valid")
+		}
+	}
+	challenge := dispute.Challenge{
+		ID:               nanoid.RandomID(),
+		MarketID:         marketID,
+		ChallengerPubkey: challengerPubkey.String(),
+		Outcome:          challengeData.Outcome,
+		OutcomeID:        outcomeID(challengeData.OutcomeID),
+		Value:            outcomeValue(challengeData.Value),
+		Evidence:         challengeData.Evidence,
+		EvidenceURL:      zeronull.Text(challengeData.EvidenceURL),
+		CreatedAt:        time.Now(),
+	}
+	if err = s.court.Challenge(c.UserContext(), challenge); err != nil {
+		return arbitrationError(err)
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+func (s *server) ruleResolution(c *fiber.Ctx) error {
+	type RulingData struct {
+		Arbiter   string                      `json:"arbiter"`
+		MarketID  string                      `json:"marketID"`
+		Outcome   prediction.MarketResolution `json:"outcome"`
+		OutcomeID *int16                      `json:"outcomeId"`
+		Value     *float64                    `json:"value"`
+		Note      string                      `json:"note"`
+	}
+	var rulingData RulingData
+	arbiterPubkey, err := verifySignedRequest(c, signedActionRuleResolution, &rulingData, &rulingData.Arbiter)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(s.disputeConfig.ArbiterPubkeys, arbiterPubkey.String()) {
+		return fiber.NewError(fiber.StatusForbidden, "wallet is not an arbiter")
+	}
+	marketID := c.Params("id")
+	if rulingData.MarketID != marketID {
+		return fiber.NewError(fiber.StatusBadRequest, "signed market does not match")
+	}
+	if len(rulingData.Note) > maxRulingNoteLength {
+		return fiber.NewError(fiber.StatusBadRequest, "note is too long")
+	}
+	ruling := dispute.Ruling{
+		MarketID:    marketID,
+		Outcome:     rulingData.Outcome,
+		OutcomeID:   outcomeID(rulingData.OutcomeID),
+		Value:       outcomeValue(rulingData.Value),
+		FinalizedBy: arbiterPubkey.String(),
+		FinalizedAt: time.Now(),
+		Note:        rulingData.Note,
+	}
+	var resolution dispute.Resolution
+	err = s.disputeRepo.RunInTx(c.UserContext(), func(ctx context.Context) error {
+		var err error
+		if resolution, err = s.court.Rule(ctx, ruling); err != nil {
+			return err
+		}
+		return s.moderationRepo.AddAuditEntry(ctx, moderation.AuditEntry{
+			Actor:      ruling.FinalizedBy,
+			Action:     moderation.ActionRuleResolution,
+			TargetType: moderation.TargetMarket,
+			TargetID:   marketID,
+			Details: map[string]any{
+				"proposed":       resolution.ProposedOutcome,
+				"final":          resolution.FinalOutcome,
+				"proposed_id":    resolution.ProposedOutcomeID,
+				"final_id":       resolution.FinalOutcomeID,
+				"proposed_value": resolution.ProposedValue,
+				"final_value":    resolution.FinalValue,
+				"note":           ruling.Note,
+			},
+			CreatedAt: ruling.FinalizedAt,
+		})
+	})
+	if err != nil {
+		return arbitrationError(err)
+	}
+	return c.JSON(resolution)
+}
+
+func arbitrationError(err error) error {
+	switch {
+	case errors.Is(err, arbitration.ErrNotProposed):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, arbitration.ErrAlreadyFinal), errors.Is(err, arbitration.ErrWindowClosed):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, arbitration.ErrNotParticipant):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, arbitration.ErrInvalidOutcome), errors.Is(err, arbitration.ErrAlreadyProposed):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	default:
+		return fmt.Errorf("arbitration: %w", err)
+	}
+}
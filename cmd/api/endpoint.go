@@ -3,6 +3,61 @@ package main
 func (s *server) configureEndpoints() {
 	api := s.app.Group("/v1")
 
+	api.Get("/markets", s.listMarkets)
+	api.Get("/markets/search", s.searchMarkets)
+	api.Get("/markets/trending", s.trendingMarkets)
+	api.Get("/markets/tags", s.tagCloud)
+	api.Get("/categories", s.listCategories)
 	api.Post("/markets/create", s.createMarket)
 	api.Post("/markets/init", s.initMarket)
+	api.Get("/markets/:id/quote", s.quoteMarket)
+	api.Get("/markets/:id/history", s.marketHistory)
+	api.Post("/markets/:id/views", s.recordMarketView)
+	api.Get("/markets/:id/settlement", s.marketSettlement)
+	api.Get("/users/:pubkey/claims", s.userClaims)
+	api.Get("/users/:pubkey/portfolio", s.userPortfolio)
+	api.Get("/users/:pubkey/profile", s.userProfile)
+	api.Post("/users/:pubkey/profile", s.updateProfile)
+	api.Post("/users/:pubkey/follow", s.followUser)
+	api.Post("/users/:pubkey/unfollow", s.unfollowUser)
+	api.Get("/users/:pubkey/following", s.userFollowing)
+	api.Get("/users/:pubkey/followers", s.userFollowers)
+	api.Get("/feed", s.userFeed)
+	api.Get("/notifications", s.listNotifications)
+	api.Post("/notifications/read", s.markNotificationsRead)
+	api.Get("/notifications/preferences", s.notificationPreferences)
+	api.Post("/notifications/preferences", s.setNotificationPreferences)
+
+	api.Get("/fees/schedules", s.feeSchedules)
+	api.Get("/fees/report", s.feeReport)
+	api.Get("/creators/:pubkey/fees", s.creatorFees)
+	api.Post("/creators/fees/claim", s.claimCreatorFees)
+
+	api.Get("/leaderboards/:kind", s.getLeaderboard)
+
+	api.Post("/markets/:id/report", s.reportMarket)
+	api.Get("/markets/:id/resolution", s.marketResolution)
+	api.Post("/markets/:id/challenge", s.challengeResolution)
+	api.Post("/markets/:id/ruling", s.ruleResolution)
+	api.Post("/markets/:id/resolve", s.resolveMarket)
+	api.Get("/markets/:id/comments", s.listComments)
+	api.Post("/markets/:id/comments", s.createComment)
+	api.Post("/comments/:id/edit", s.editComment)
+	api.Post("/comments/:id/delete", s.deleteComment)
+	api.Post("/comments/:id/reactions", s.reactComment)
+	api.Post("/comments/:id/report", s.reportComment)
+	api.Get("/markets/:id/resolver", s.marketResolver)
+	api.Get("/markets/:id/oracle", s.marketOracle)
+	api.Post("/markets/:id/resolver/respond", s.respondResolverAssignment)
+	api.Post("/markets/:id/resolver/delegate", s.delegateResolver)
+	api.Get("/resolvers/:pubkey/reputation", s.resolverReputation)
+
+	admin := api.Group("/admin", s.requireAdmin)
+	admin.Get("/markets", s.adminListMarkets)
+	admin.Get("/markets/:id/reports", s.adminMarketReports)
+	admin.Post("/markets/:id/moderation", s.adminSetModeration)
+	admin.Post("/comments/:id/moderation", s.adminSetCommentStatus)
+	admin.Post("/categories", s.adminCreateCategory)
+	admin.Put("/fees/schedules/:category", s.adminSetFeeSchedule)
+	admin.Get("/audit", s.adminAuditLog)
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/ledger"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+func (s *server) feeSchedules(c *fiber.Ctx) error {
+	schedules, err := s.ledgerRepo.ListSchedules(c.UserContext())
+	if err != nil {
+		return fmt.Errorf("list fee schedules: %w", err)
+	}
+	return c.JSON(fiber.Map{"schedules": schedules})
+}
+
+func (s *server) feeReport(c *fiber.Ctx) error {
+	return s.writeFeeReport(c, "")
+}
+
+func (s *server) creatorFees(c *fiber.Ctx) error {
+	creator, err := solana.PublicKeyFromBase58(c.Params("pubkey"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "pubkey is not valid")
+	}
+	return s.writeFeeReport(c, creator.String())
+}
+
+func (s *server) writeFeeReport(c *fiber.Ctx, creator string) error {
+	period := ledger.Period(c.Query("period", string(ledger.PeriodMonth)))
+	if !period.Valid() {
+		return fiber.NewError(fiber.StatusBadRequest, "period must be one of day, week, month")
+	}
+	to, err := queryTime(c, "to", time.Now())
+	if err != nil {
+		return err
+	}
+	from, err := queryTime(c, "from", to.AddDate(-1, 0, 0))
+	if err != nil {
+		return err
+	}
+	rows, err := s.ledgerRepo.GetReport(c.UserContext(), ledger.ReportFilter{
+		Creator: creator,
+		Period:  period,
+		From:    from,
+		To:      to,
+	})
+	if err != nil {
+		return fmt.Errorf("get fee report: %w", err)
+	}
+	return c.JSON(fiber.Map{"period": period, "rows": rows})
+}
+
+func (s *server) claimCreatorFees(c *fiber.Ctx) error {
+	type ClaimData struct {
+		Creator string `json:"creator"`
+	}
+	var claimData ClaimData
+	creatorPubkey, err := verifySignedRequest(c, signedActionClaimFees, &claimData, &claimData.Creator)
+	if err != nil {
+		return err
+	}
+	amount, err := s.accountant.Claim(c.UserContext(), creatorPubkey.String())
+	if err != nil {
+		return fmt.Errorf("claim creator fees: %w", err)
+	}
+	return c.JSON(fiber.Map{"claimed": amount})
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/history"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+const maxHistoryCandles = 500
+
+func (s *server) marketHistory(c *fiber.Ctx) error {
+	interval := history.Interval(c.Query("interval", string(history.IntervalHour)))
+	if !interval.Valid() {
+		return fiber.NewError(fiber.StatusBadRequest, "interval must be one of 1m, 1h, 1d")
+	}
+	to, err := queryTime(c, "to", time.Now())
+	if err != nil {
+		return err
+	}
+	from, err := queryTime(c, "from", to.Add(-interval.Duration()*maxHistoryCandles))
+	if err != nil {
+		return err
+	}
+	if !from.Before(to) {
+		return fiber.NewError(fiber.StatusBadRequest, "from must be before to")
+	}
+	source, stride := downsample(interval, to.Sub(from))
+	candles, err := s.historyRepo.GetCandles(c.UserContext(), c.Params("id"), source, stride, from, to)
+	if err != nil {
+		return fmt.Errorf("get candles: %w", err)
+	}
+	return c.JSON(fiber.Map{
+		"market_id": c.Params("id"),
+		"interval":  formatStride(stride),
+		"candles":   candles,
+	})
+}
+
+// downsample widens the requested interval so that the range fits into maxHistoryCandles
+// and picks the coarsest stored interval the resulting stride can be built from
+func downsample(interval history.Interval, span time.Duration) (history.Interval, time.Duration) {
+	stride := interval.Duration()
+	if buckets := int64(span / stride); buckets > maxHistoryCandles {
+		stride *= time.Duration((buckets + maxHistoryCandles - 1) / maxHistoryCandles)
+	}
+	source := interval
+	for _, candidate := range history.Intervals {
+		if candidate.Duration() <= stride && stride%candidate.Duration() == 0 {
+			source = candidate
+		}
+	}
+	return source, stride
+}
+
+// formatStride writes the stride like the interval query, so it echoes the requested
+// interval unless the range had to be downsampled, e.g. "1h" or "3h" rather than "1h0m0s"
+func formatStride(stride time.Duration) string {
+	switch {
+	case stride%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", stride/(24*time.Hour))
+	case stride%time.Hour == 0:
+		return fmt.Sprintf("%dh", stride/time.Hour)
+	case stride%time.Minute == 0:
+		return fmt.Sprintf("%dm", stride/time.Minute)
+	default:
+		return stride.String()
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/leaderboard"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *server) getLeaderboard(c *fiber.Ctx) error {
+	kind := leaderboard.Kind(c.Params("kind"))
+	if !kind.Valid() {
+		return fiber.NewError(fiber.StatusNotFound, "unknown leaderboard")
+	}
+	window := leaderboard.Window(c.Query("window", string(leaderboard.Window7d)))
+	if !window.Valid() {
+		return fiber.NewError(fiber.StatusBadRequest, "window must be one of 24h, 7d, all")
+	}
+	limit, offset, err := queryPage(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.UserContext()
+	entries, err := s.leaderboardRepo.GetEntries(ctx, kind, window, limit, offset)
+	if err != nil {
+		return fmt.Errorf("get leaderboard: %w", err)
+	}
+	total, err := s.leaderboardRepo.CountEntries(ctx, kind, window)
+	if err != nil {
+		return fmt.Errorf("count leaderboard: %w", err)
+	}
+	response := fiber.Map{
+		"kind":    kind,
+		"window":  window,
+		"total":   total,
+		"entries": entries,
+	}
+	if wallet := c.Query("wallet"); wallet != "" {
+		owner, err := solana.PublicKeyFromBase58(wallet)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "wallet is not valid")
+		}
+		entry, err := s.leaderboardRepo.GetEntry(ctx, kind, window, owner.String())
+		if err == nil {
+			response["me"] = entry
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("get leaderboard entry: %w", err)
+		}
+	}
+	return c.JSON(response)
+}
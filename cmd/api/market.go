@@ -1,23 +1,62 @@
 package main
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/chain"
+	"github.com/IndexStorm/hit-my-bet-back/internal/oracle"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/feed"
 	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/resolver"
 	"github.com/IndexStorm/hit-my-bet-back/pkg/nanoid"
+	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"strings"
 	"time"
 )
 
+const (
+	// txKindMarketInit names the outcome handler of relayed market init transactions
+	txKindMarketInit = "MARKET_INIT"
+	// initMarketInstruction is the snake case IDL name of the instruction creating a market account
+	initMarketInstruction = "initialize_market"
+	// initMarketResolverAccount is the IDL name of the resolver account of the init instruction
+	initMarketResolverAccount = "resolver"
+)
+
 func (s *server) createMarket(c *fiber.Ctx) error {
+	type OracleData struct {
+		Kind      feed.Kind     `json:"kind"`
+		Source    string        `json:"source"`
+		Path      string        `json:"path"`
+		Operator  feed.Operator `json:"operator"`
+		Threshold float64       `json:"threshold"`
+	}
+	type ScalarData struct {
+		Lower float64 `json:"lower"`
+		Upper float64 `json:"upper"`
+	}
 	type MarketData struct {
-		Title       string `json:"title"`
-		Creator     string `json:"creator"`
-		Description string `json:"description"`
-		OpenThrough int64  `json:"openThrough"`
+		Title       string   `json:"title"`
+		Creator     string   `json:"creator"`
+		Resolver    string   `json:"resolver"`
+		Description string   `json:"description"`
+		OpenThrough int64    `json:"openThrough"`
+		Category    string   `json:"category"`
+		Tags        []string `json:"tags"`
+		// Oracle optionally resolves the market automatically once it closes
+		Oracle *OracleData `json:"oracle"`
+		// Outcomes make the market categorical, binary markets leave them empty
+		Outcomes []string `json:"outcomes"`
+		// Scalar makes the market trade LONG/SHORT on a numeric range
+		Scalar *ScalarData `json:"scalar"`
 	}
 	type Request struct {
 		RawData   string `json:"rawData"`
@@ -38,49 +77,283 @@ func (s *server) createMarket(c *fiber.Ctx) error {
 	if !creatorPubkey.Verify([]byte(request.RawData), solana.SignatureFromBytes(request.Signature)) {
 		return fiber.NewError(fiber.StatusUnauthorized, "signature is not valid")
 	}
+	// Creators resolve their own markets unless they nominate a resolver, who must accept first
+	resolverPubkey, resolverStatus := creatorPubkey, prediction.ResolverStatusAccepted
+	if marketData.Resolver != "" {
+		if resolverPubkey, err = solana.PublicKeyFromBase58(marketData.Resolver); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "resolver pubkey is not valid")
+		}
+		if !resolverPubkey.Equals(creatorPubkey) {
+			resolverStatus = prediction.ResolverStatusPending
+		}
+	}
 	openThrough := time.Unix(marketData.OpenThrough/1000, 0)
 	if time.Now().After(openThrough) {
 		return fiber.NewError(fiber.StatusBadRequest, "market is closed")
 	}
+	tags, err := normalizeTags(marketData.Tags)
+	if err != nil {
+		return err
+	}
+	kind := prediction.MarketKindBinary
+	var outcomes []prediction.MarketOutcome
+	if len(marketData.Outcomes) > 0 {
+		if outcomes, err = normalizeOutcomes(marketData.Outcomes); err != nil {
+			return err
+		}
+		if marketData.Oracle != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "oracles resolve binary markets only")
+		}
+		kind = prediction.MarketKindCategorical
+	}
+	var scalarLower, scalarUpper pgtype.Float8
+	if marketData.Scalar != nil {
+		if kind != prediction.MarketKindBinary || marketData.Oracle != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "scalar markets take neither outcomes nor oracles")
+		}
+		if scalarLower, scalarUpper, err = scalarRange(marketData.Scalar.Lower, marketData.Scalar.Upper); err != nil {
+			return err
+		}
+		kind = prediction.MarketKindScalar
+	}
+	ctx := c.UserContext()
+	if marketData.Category != "" {
+		if _, err = s.predictionRepo.GetCategory(ctx, marketData.Category); errors.Is(err, pgx.ErrNoRows) {
+			return fiber.NewError(fiber.StatusBadRequest, "unknown category")
+		} else if err != nil {
+			return fmt.Errorf("get category: %w", err)
+		}
+	}
 	market := prediction.Market{
 		ID:             nanoid.RandomID(),
 		ChainStatus:    prediction.MarketChainStatusPending,
 		Title:          marketData.Title,
 		Description:    zeronull.Text(marketData.Description),
-		CreatorPubkey:  marketData.Creator,
-		ResolverPubkey: marketData.Creator,
+		CreatorPubkey:  creatorPubkey.String(),
+		ResolverPubkey: resolverPubkey.String(),
+		ResolverStatus: resolverStatus,
 		Resolution:     prediction.MarketResolutionUnresolved,
 		CreatedAt:      time.Now(),
 		OpenThrough:    openThrough,
+		Category:       zeronull.Text(marketData.Category),
+		Tags:           tags,
+		Kind:           kind,
+		Outcomes:       outcomes,
+		ScalarLower:    scalarLower,
+		ScalarUpper:    scalarUpper,
 	}
-	ctx := c.UserContext()
-	if err = s.predictionRepo.CreateMarket(ctx, market); err != nil {
+	assignment := resolver.Assignment{
+		ID:             nanoid.RandomID(),
+		MarketID:       market.ID,
+		ResolverPubkey: market.ResolverPubkey,
+		NominatedBy:    market.CreatorPubkey,
+		Status:         resolver.AssignmentStatusPending,
+		CreatedAt:      market.CreatedAt,
+	}
+	if resolverStatus == prediction.ResolverStatusAccepted {
+		assignment.Status = resolver.AssignmentStatusAccepted
+		assignment.RespondedAt = zeronull.Timestamptz(market.CreatedAt)
+	}
+	var marketFeed *feed.Feed
+	if marketData.Oracle != nil {
+		marketFeed = &feed.Feed{
+			MarketID:   market.ID,
+			Kind:       marketData.Oracle.Kind,
+			Source:     marketData.Oracle.Source,
+			Path:       zeronull.Text(marketData.Oracle.Path),
+			Operator:   marketData.Oracle.Operator,
+			Threshold:  marketData.Oracle.Threshold,
+			EvaluateAt: market.OpenThrough,
+		}
+		err = s.oracles.Validate(ctx, *marketFeed)
+		if errors.Is(err, oracle.ErrInvalidFeed) || errors.Is(err, oracle.ErrUnsupportedKind) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		} else if err != nil {
+			return fmt.Errorf("validate oracle feed: %w", err)
+		}
+	}
+	err = s.predictionRepo.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.predictionRepo.CreateMarket(ctx, market); err != nil {
+			return err
+		}
+		if marketFeed != nil {
+			if err := s.feedRepo.CreateFeed(ctx, *marketFeed); err != nil {
+				return fmt.Errorf("create feed: %w", err)
+			}
+		}
+		return s.resolverRepo.CreateAssignment(ctx, assignment)
+	})
+	if err != nil {
 		return fmt.Errorf("create market: %w", err)
 	}
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": market.ID})
 }
 
+func (s *server) marketOracle(c *fiber.Ctx) error {
+	marketFeed, err := s.feedRepo.GetFeed(c.UserContext(), c.Params("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "market has no oracle")
+	} else if err != nil {
+		return fmt.Errorf("get feed: %w", err)
+	}
+	return c.JSON(marketFeed)
+}
+
 func (s *server) initMarket(c *fiber.Ctx) error {
 	type Request struct {
-		MarketID string `json:"marketID"`
-		TxData   string `json:"txData"`
+		MarketID             string `json:"marketID"`
+		TxData               string `json:"txData"`
+		LastValidBlockHeight uint64 `json:"lastValidBlockHeight"`
 	}
 	var request Request
 	if err := json.Unmarshal(c.Body(), &request); err != nil {
 		return fmt.Errorf("unmarshal request: %w", err)
 	}
-	txHash, err := s.relayTxData(request.TxData)
+	market, err := s.predictionRepo.GetMarket(c.UserContext(), request.MarketID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "market not found")
+	} else if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	if market.ResolverStatus != prediction.ResolverStatusAccepted {
+		return fiber.NewError(fiber.StatusConflict, "nominated resolver has not accepted the market")
+	}
+	if err = s.validateInitMarketTx(request.TxData, market); err != nil {
+		return err
+	}
+	txHash, err := s.relayTxData(c.UserContext(), request.TxData, request.LastValidBlockHeight,
+		txKindMarketInit, request.MarketID)
 	if err != nil {
 		return fmt.Errorf("relay tx: %w", err)
 	}
-	if txHash == "" {
-		return errors.New("tx hash is empty")
+	return c.JSON(fiber.Map{"tx_hash": txHash.String()})
+}
+
+// validateInitMarketTx checks that the relayed transaction initializes the requested market.
+// Binary markets are resolved on chain, so their init must name the accepted resolver.
+// Transactions cannot be checked without a loaded program IDL and are refused.
+func (s *server) validateInitMarketTx(txData string, market prediction.Market) error {
+	if s.decoder.IDL() == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "market init is unavailable without the program idl")
 	}
-	// TODO: Use chain monitoring to confirm markets
-	// TODO: Validate market id from tx data
-	ctx := c.UserContext()
-	if err = s.predictionRepo.SetMarketInitialized(ctx, request.MarketID); err != nil {
-		s.logger.Err(err).Msg("failed to set market initialized")
+	raw, err := base64.StdEncoding.DecodeString(txData)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "tx data is not valid base64")
+	}
+	tx, err := solana.TransactionFromBytes(raw)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "tx data is not a valid transaction")
+	}
+	instructions, err := s.decoder.DecodeInstructions(tx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("decode program instructions: %s", err))
+	}
+	initialized := false
+	for _, ix := range instructions {
+		// Legacy IDLs keep instruction names in camel case
+		if bin.ToSnakeForSighash(ix.Name) != initMarketInstruction {
+			continue
+		}
+		if id, ok := ix.Args["id"].(string); !ok || id != market.ID {
+			return fiber.NewError(fiber.StatusBadRequest, "tx initializes another market")
+		}
+		if market.Binary() && ix.Accounts[initMarketResolverAccount].String() != market.ResolverPubkey {
+			return fiber.NewError(fiber.StatusBadRequest, "tx resolver is not the accepted resolver")
+		}
+		initialized = true
+	}
+	if !initialized {
+		return fiber.NewError(fiber.StatusBadRequest, "tx does not initialize the market")
+	}
+	return nil
+}
+
+// onMarketInitTx records the outcome of the init transaction of the market named by its subject
+func (s *server) onMarketInitTx(ctx context.Context, tx chain.TrackedTx) {
+	marketID := tx.Subject
+	var err error
+	if tx.Status == chain.TxStatusConfirmed {
+		err = s.predictionRepo.SetMarketInitialized(ctx, marketID)
+	} else {
+		err = s.predictionRepo.SetMarketChainStatus(ctx, marketID, prediction.MarketChainStatusNeedRetry)
+	}
+	if err != nil {
+		s.logger.Err(err).
+			Str("market", marketID).
+			Stringer("signature", tx.Signature).
+			Msg("failed to update market chain status")
+	}
+}
+
+func (s *server) listMarkets(c *fiber.Ctx) error {
+	filter, err := queryMarketFilter(c)
+	if err != nil {
+		return err
+	}
+	limit, offset, err := queryPage(c)
+	if err != nil {
+		return err
+	}
+	markets, err := s.predictionRepo.ListMarkets(c.UserContext(), filter, limit, offset)
+	if err != nil {
+		return fmt.Errorf("list markets: %w", err)
+	}
+	return c.JSON(fiber.Map{"markets": markets})
+}
+
+func (s *server) searchMarkets(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "q is required")
+	}
+	filter, err := queryMarketFilter(c)
+	if err != nil {
+		return err
+	}
+	limit, offset, err := queryPage(c)
+	if err != nil {
+		return err
+	}
+	results, err := s.predictionRepo.SearchMarkets(c.UserContext(), query, filter, limit, offset)
+	if err != nil {
+		return fmt.Errorf("search markets: %w", err)
+	}
+	return c.JSON(fiber.Map{"markets": results})
+}
+
+func queryMarketFilter(c *fiber.Ctx) (prediction.MarketFilter, error) {
+	filter := prediction.MarketFilter{
+		Status:   prediction.MarketStatus(strings.ToUpper(c.Query("status"))),
+		Creator:  c.Query("creator"),
+		Category: c.Query("category"),
+		Tag:      c.Query("tag"),
+	}
+	if !filter.Valid() {
+		return prediction.MarketFilter{}, fiber.NewError(fiber.StatusBadRequest, "status must be one of open, closed, resolved")
+	}
+	return filter, nil
+}
+
+func (s *server) listCategories(c *fiber.Ctx) error {
+	categories, err := s.predictionRepo.ListCategories(c.UserContext())
+	if err != nil {
+		return fmt.Errorf("list categories: %w", err)
+	}
+	return c.JSON(fiber.Map{"categories": categories})
+}
+
+func (s *server) tagCloud(c *fiber.Ctx) error {
+	filter, err := queryMarketFilter(c)
+	if err != nil {
+		return err
+	}
+	limit, _, err := queryPage(c)
+	if err != nil {
+		return err
+	}
+	tags, err := s.predictionRepo.GetTagCounts(c.UserContext(), filter, limit)
+	if err != nil {
+		return fmt.Errorf("get tag counts: %w", err)
 	}
-	return c.SendStatus(fiber.StatusOK)
+	return c.JSON(fiber.Map{"tags": tags})
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/moderation"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/nanoid"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+const (
+	maxReportReasonLength  = 64
+	maxReportDetailsLength = 1000
+)
+
+func (s *server) reportMarket(c *fiber.Ctx) error {
+	type ReportData struct {
+		Reporter string `json:"reporter"`
+		MarketID string `json:"marketID"`
+		Reason   string `json:"reason"`
+		Details  string `json:"details"`
+	}
+	var reportData ReportData
+	reporterPubkey, err := verifySignedRequest(c, signedActionReportMarket, &reportData, &reportData.Reporter)
+	if err != nil {
+		return err
+	}
+	marketID := c.Params("id")
+	if reportData.MarketID != marketID {
+		return fiber.NewError(fiber.StatusBadRequest, "signed market does not match")
+	}
+	if reportData.Reason == "" || len(reportData.Reason) > maxReportReasonLength ||
+		len(reportData.Details) > maxReportDetailsLength {
+		return fiber.NewError(fiber.StatusBadRequest, "reason is required and report must be short")
+	}
+	ctx := c.UserContext()
+	if _, err = s.predictionRepo.GetMarket(ctx, marketID); errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "market not found")
+	} else if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	report := moderation.Report{
+		ID:             nanoid.RandomID(),
+		MarketID:       marketID,
+		ReporterPubkey: reporterPubkey.String(),
+		Reason:         reportData.Reason,
+		Details:        zeronull.Text(reportData.Details),
+		CreatedAt:      time.Now(),
+	}
+	err = s.moderationRepo.RunInTx(ctx, func(ctx context.Context) error {
+		created, err := s.moderationRepo.CreateReport(ctx, report)
+		if err != nil || !created {
+			return err
+		}
+		return s.flagReportedMarket(ctx, marketID)
+	})
+	if err != nil {
+		return fmt.Errorf("report market: %w", err)
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// flagReportedMarket flags a visible market once it collected enough reports
+func (s *server) flagReportedMarket(ctx context.Context, marketID string) error {
+	reports, err := s.moderationRepo.CountReports(ctx, marketID)
+	if err != nil {
+		return fmt.Errorf("count reports: %w", err)
+	}
+	if reports < s.adminConfig.FlagThreshold {
+		return nil
+	}
+	market, err := s.predictionRepo.GetMarket(ctx, marketID)
+	if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	if market.ModerationStatus != prediction.ModerationStatusVisible {
+		return nil
+	}
+	if _, err = s.moderationRepo.SetModerationStatus(ctx, marketID, prediction.ModerationStatusFlagged); err != nil {
+		return fmt.Errorf("flag market: %w", err)
+	}
+	return s.moderationRepo.AddAuditEntry(ctx, moderation.AuditEntry{
+		Actor:      moderation.ActorSystem,
+		Action:     moderation.ActionAutoFlag,
+		TargetType: moderation.TargetMarket,
+		TargetID:   marketID,
+		Details:    map[string]any{"reports": reports},
+		CreatedAt:  time.Now(),
+	})
+}
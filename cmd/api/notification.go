@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/notification"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultNotificationPageSize = 20
+	maxNotificationPageSize     = 100
+	maxMarkReadIDs              = 100
+)
+
+// listNotifications returns the inbox of the wallet signing the request headers, newest first
+func (s *server) listNotifications(c *fiber.Ctx) error {
+	recipient, err := verifyWalletHeaders(c)
+	if err != nil {
+		return err
+	}
+	limit := c.QueryInt("limit", defaultNotificationPageSize)
+	if limit <= 0 || limit > maxNotificationPageSize {
+		return fiber.NewError(fiber.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxNotificationPageSize))
+	}
+	var after *notification.Cursor
+	if value := c.Query("cursor"); value != "" {
+		createdAt, id, err := decodeCursor(value)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "cursor is not valid")
+		}
+		after = &notification.Cursor{CreatedAt: createdAt, ID: id}
+	}
+	ctx := c.UserContext()
+	notifications, err := s.notificationRepo.ListNotifications(ctx, recipient.String(), c.QueryBool("unread"), after, limit)
+	if err != nil {
+		return fmt.Errorf("list notifications: %w", err)
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, recipient.String())
+	if err != nil {
+		return fmt.Errorf("count unread notifications: %w", err)
+	}
+	var next string
+	if len(notifications) == limit {
+		last := notifications[len(notifications)-1]
+		next = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return c.JSON(fiber.Map{
+		"notifications": notifications,
+		"unread":        unread,
+		"next_cursor":   next,
+	})
+}
+
+// markNotificationsRead marks the listed notifications of the signing wallet as read,
+// or all of them when no ids are given
+func (s *server) markNotificationsRead(c *fiber.Ctx) error {
+	type ReadData struct {
+		Pubkey string   `json:"pubkey"`
+		IDs    []string `json:"ids"`
+	}
+	var readData ReadData
+	recipient, err := verifySignedRequest(c, signedActionReadNotifications, &readData, &readData.Pubkey)
+	if err != nil {
+		return err
+	}
+	if len(readData.IDs) > maxMarkReadIDs {
+		return fiber.NewError(fiber.StatusBadRequest, "at most "+strconv.Itoa(maxMarkReadIDs)+" ids can be marked at once")
+	}
+	ctx := c.UserContext()
+	marked, err := s.notificationRepo.MarkRead(ctx, recipient.String(), readData.IDs, time.Now())
+	if err != nil {
+		return fmt.Errorf("mark notifications read: %w", err)
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, recipient.String())
+	if err != nil {
+		return fmt.Errorf("count unread notifications: %w", err)
+	}
+	return c.JSON(fiber.Map{
+		"marked": marked,
+		"unread": unread,
+	})
+}
+
+func (s *server) notificationPreferences(c *fiber.Ctx) error {
+	pubkey, err := verifyWalletHeaders(c)
+	if err != nil {
+		return err
+	}
+	preferences, err := s.notificationRepo.GetPreferences(c.UserContext(), pubkey.String())
+	if err != nil {
+		return fmt.Errorf("get notification preferences: %w", err)
+	}
+	return c.JSON(fiber.Map{"preferences": preferences})
+}
+
+// setNotificationPreferences enables or disables kinds of notifications for the signing
+// wallet, kinds left out keep their current setting
+func (s *server) setNotificationPreferences(c *fiber.Ctx) error {
+	type PreferencesData struct {
+		Pubkey      string                     `json:"pubkey"`
+		Preferences map[notification.Kind]bool `json:"preferences"`
+	}
+	var preferencesData PreferencesData
+	pubkey, err := verifySignedRequest(c, signedActionSetPreferences, &preferencesData, &preferencesData.Pubkey)
+	if err != nil {
+		return err
+	}
+	for kind := range preferencesData.Preferences {
+		if !kind.Valid() {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("notification kind %q is not valid", kind))
+		}
+	}
+	ctx := c.UserContext()
+	err = s.notificationRepo.SetPreferences(ctx, pubkey.String(), preferencesData.Preferences, time.Now())
+	if err != nil {
+		return fmt.Errorf("set notification preferences: %w", err)
+	}
+	preferences, err := s.notificationRepo.GetPreferences(ctx, pubkey.String())
+	if err != nil {
+		return fmt.Errorf("get notification preferences: %w", err)
+	}
+	return c.JSON(fiber.Map{"preferences": preferences})
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	minMarketOutcomes    = 2
+	maxMarketOutcomes    = 16
+	maxOutcomeNameLength = 64
+)
+
+// normalizeOutcomes validates the named outcomes of a categorical market, their order
+// in the request defines the outcome ids
+func normalizeOutcomes(raw []string) ([]prediction.MarketOutcome, error) {
+	if len(raw) < minMarketOutcomes || len(raw) > maxMarketOutcomes {
+		return nil, fiber.NewError(fiber.StatusBadRequest,
+			fmt.Sprintf("categorical market needs %d to %d outcomes", minMarketOutcomes, maxMarketOutcomes))
+	}
+	outcomes := make([]prediction.MarketOutcome, 0, len(raw))
+	seen := make(map[string]struct{}, len(raw))
+	for i, value := range raw {
+		name := strings.Join(strings.Fields(value), " ")
+		if name == "" || len(name) > maxOutcomeNameLength {
+			return nil, fiber.NewError(fiber.StatusBadRequest,
+				fmt.Sprintf("outcome name must be 1 to %d characters", maxOutcomeNameLength))
+		}
+		key := strings.ToLower(name)
+		if _, ok := seen[key]; ok {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("outcome %q is duplicated", name))
+		}
+		seen[key] = struct{}{}
+		outcomes = append(outcomes, prediction.MarketOutcome{ID: int16(i), Name: name})
+	}
+	return outcomes, nil
+}
+
+// scalarRange validates the bounds of a scalar market
+func scalarRange(lower, upper float64) (pgtype.Float8, pgtype.Float8, error) {
+	finite := func(v float64) bool { return !math.IsNaN(v) && !math.IsInf(v, 0) }
+	if !finite(lower) || !finite(upper) || lower >= upper {
+		return pgtype.Float8{}, pgtype.Float8{}, fiber.NewError(fiber.StatusBadRequest,
+			"scalar range must have a finite lower bound below the upper bound")
+	}
+	return pgtype.Float8{Float64: lower, Valid: true}, pgtype.Float8{Float64: upper, Valid: true}, nil
+}
+
+// outcomeID converts an optional outcome id of a request
+func outcomeID(id *int16) pgtype.Int2 {
+	if id == nil {
+		return pgtype.Int2{}
+	}
+	return pgtype.Int2{Int16: *id, Valid: true}
+}
+
+// outcomeValue converts an optional scalar value of a request
+func outcomeValue(value *float64) pgtype.Float8 {
+	if value == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: *value, Valid: true}
+}
+
+// resolveMarket lets the resolver of a closed categorical or scalar market report the
+// winning outcome or the value, which goes through the dispute period like resolutions
+// reported on chain
+func (s *server) resolveMarket(c *fiber.Ctx) error {
+	type ResolveData struct {
+		Resolver  string   `json:"resolver"`
+		MarketID  string   `json:"marketID"`
+		OutcomeID *int16   `json:"outcomeId"`
+		Value     *float64 `json:"value"`
+	}
+	var resolveData ResolveData
+	resolverPubkey, err := verifySignedRequest(c, signedActionResolveMarket, &resolveData, &resolveData.Resolver)
+	if err != nil {
+		return err
+	}
+	marketID := c.Params("id")
+	if resolveData.MarketID != marketID {
+		return fiber.NewError(fiber.StatusBadRequest, "signed market does not match")
+	}
+	ctx := c.UserContext()
+	market, err := s.predictionRepo.GetMarket(ctx, marketID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "market not found")
+	} else if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	if market.Binary() {
+		return fiber.NewError(fiber.StatusBadRequest, "binary markets are resolved on chain")
+	}
+	if market.ResolverPubkey != resolverPubkey.String() || market.ResolverStatus != prediction.ResolverStatusAccepted {
+		return fiber.NewError(fiber.StatusForbidden, "wallet is not the market resolver")
+	}
+	if time.Now().Before(market.OpenThrough) {
+		return fiber.NewError(fiber.StatusConflict, "market is still open")
+	}
+	market.Resolution = prediction.MarketResolutionOutcome
+	if market.Kind == prediction.MarketKindScalar {
+		market.Resolution = prediction.MarketResolutionValue
+	}
+	market.ResolvedOutcome = outcomeID(resolveData.OutcomeID)
+	market.ResolvedValue = outcomeValue(resolveData.Value)
+	if !market.ValidResolution(market.Resolution, market.ResolvedOutcome, market.ResolvedValue) {
+		return fiber.NewError(fiber.StatusBadRequest, "outcome is not valid for the market")
+	}
+	err = s.predictionRepo.RunInTx(ctx, func(ctx context.Context) error {
+		err := s.predictionRepo.ResolveMarket(ctx, market)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fiber.NewError(fiber.StatusConflict, "market is already resolved")
+		} else if err != nil {
+			return fmt.Errorf("resolve market: %w", err)
+		}
+		_, err = s.court.Propose(ctx, market)
+		return err
+	})
+	if err != nil {
+		return arbitrationError(err)
+	}
+	resolution, err := s.disputeRepo.GetResolution(ctx, market.ID)
+	if err != nil {
+		return fmt.Errorf("get resolution: %w", err)
+	}
+	return c.JSON(resolution)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *server) userPortfolio(c *fiber.Ctx) error {
+	owner, err := solana.PublicKeyFromBase58(c.Params("pubkey"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "pubkey is not valid")
+	}
+	ctx := c.UserContext()
+	portfolio, err := s.portfolios.Get(ctx, owner.String())
+	if err != nil {
+		return fmt.Errorf("get portfolio: %w", err)
+	}
+	profiles, err := s.profileSummaries(ctx, []string{portfolio.Owner})
+	if err != nil {
+		return err
+	}
+	if summary, ok := profiles[portfolio.Owner]; ok {
+		portfolio.Profile = &summary
+	}
+	return c.JSON(portfolio)
+}
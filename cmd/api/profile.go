@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/profile"
+	"github.com/IndexStorm/hit-my-bet-back/internal/sns"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxAvatarURLLength    = 512
+	maxBioLength          = 280
+	maxSocialHandleLength = 64
+	maxWebsiteLength      = 256
+)
+
+var (
+	displayNamePattern    = regexp.MustCompile(`^[\p{L}\p{N}_.-]+( [\p{L}\p{N}_.-]+)*$`)
+	socialHandlePattern   = regexp.MustCompile(`^@?[A-Za-z0-9_.-]+$`)
+	profileSocialNetworks = []string{"x", "telegram", "discord", "github", "website"}
+)
+
+func (s *server) userProfile(c *fiber.Ctx) error {
+	owner, err := solana.PublicKeyFromBase58(c.Params("pubkey"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "pubkey is not valid")
+	}
+	userProfile, err := s.profileRepo.GetProfile(c.UserContext(), owner.String())
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "profile not found")
+	} else if err != nil {
+		return fmt.Errorf("get profile: %w", err)
+	}
+	return c.JSON(userProfile)
+}
+
+// updateProfile replaces the profile of the signing wallet. A linked .sol name must be
+// owned by the wallet and is taken over from a profile that linked it before a transfer.
+// Updates signed before the last applied one are rejected.
+func (s *server) updateProfile(c *fiber.Ctx) error {
+	type ProfileData struct {
+		Pubkey      string            `json:"pubkey"`
+		DisplayName string            `json:"displayName"`
+		AvatarURL   string            `json:"avatarUrl"`
+		Bio         string            `json:"bio"`
+		Socials     map[string]string `json:"socials"`
+		SnsName     string            `json:"snsName"`
+		Timestamp   int64             `json:"timestamp"`
+	}
+	var profileData ProfileData
+	ownerPubkey, err := verifySignedRequest(c, signedActionUpdateProfile, &profileData, &profileData.Pubkey)
+	if err != nil {
+		return err
+	}
+	signedAt := time.UnixMilli(profileData.Timestamp)
+	if c.Params("pubkey") != ownerPubkey.String() {
+		return fiber.NewError(fiber.StatusBadRequest, "signed pubkey does not match")
+	}
+	updated := profile.Profile{
+		Pubkey:    ownerPubkey.String(),
+		Bio:       zeronull.Text(strings.TrimSpace(profileData.Bio)),
+		CreatedAt: signedAt,
+		UpdatedAt: signedAt,
+	}
+	if updated.DisplayName, err = profileDisplayName(profileData.DisplayName); err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(string(updated.Bio)) > maxBioLength {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("bio must be at most %d characters", maxBioLength))
+	}
+	if profileData.AvatarURL != "" {
+		if !validHTTPSURL(profileData.AvatarURL, maxAvatarURLLength) {
+			return fiber.NewError(fiber.StatusBadRequest, "avatar url must be an https url")
+		}
+		updated.AvatarURL = zeronull.Text(profileData.AvatarURL)
+	}
+	if updated.Socials, err = profileSocials(profileData.Socials); err != nil {
+		return err
+	}
+	ctx := c.UserContext()
+	if profileData.SnsName != "" {
+		name, err := sns.Normalize(profileData.SnsName)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		owner, err := s.snsResolver.Owner(ctx, name)
+		if errors.Is(err, sns.ErrNameNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		} else if err != nil {
+			return fmt.Errorf("resolve sns name: %w", err)
+		}
+		if !owner.Equals(ownerPubkey) {
+			return fiber.NewError(fiber.StatusForbidden, "sns name is owned by another wallet")
+		}
+		updated.SnsName = zeronull.Text(name)
+	}
+	err = s.profileRepo.RunInTx(ctx, func(ctx context.Context) error {
+		current, err := s.profileRepo.GetProfileForUpdate(ctx, updated.Pubkey)
+		if err == nil {
+			if !signedAt.After(current.UpdatedAt) {
+				return fiber.NewError(fiber.StatusConflict, "a newer profile update was already applied")
+			}
+			updated.CreatedAt = current.CreatedAt
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("get profile: %w", err)
+		}
+		// The wallet owns the name now, so another profile linking it was left behind
+		// by a transfer of the domain
+		if updated.SnsName != "" {
+			if err = s.profileRepo.UnlinkSnsName(ctx, string(updated.SnsName), updated.Pubkey); err != nil {
+				return fmt.Errorf("unlink sns name: %w", err)
+			}
+		}
+		return s.profileRepo.SaveProfile(ctx, updated)
+	})
+	if errors.Is(err, profile.ErrDisplayNameTaken) || errors.Is(err, profile.ErrSnsNameTaken) {
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	} else if err != nil {
+		return fmt.Errorf("save profile: %w", err)
+	}
+	return c.JSON(updated)
+}
+
+// profileSummaries returns the summaries of wallets with a profile, keyed by pubkey
+func (s *server) profileSummaries(ctx context.Context, pubkeys []string) (map[string]profile.Summary, error) {
+	summaries, err := s.profileRepo.GetSummaries(ctx, pubkeys)
+	if err != nil {
+		return nil, fmt.Errorf("get profile summaries: %w", err)
+	}
+	return summaries, nil
+}
+
+func profileDisplayName(raw string) (zeronull.Text, error) {
+	name := strings.Join(strings.Fields(raw), " ")
+	if name == "" {
+		return "", nil
+	}
+	if length := utf8.RuneCountInString(name); length < 3 || length > 32 || !displayNamePattern.MatchString(name) {
+		return "", fiber.NewError(fiber.StatusBadRequest,
+			"display name must be 3 to 32 letters, digits, spaces, dots, dashes or underscores")
+	}
+	return zeronull.Text(name), nil
+}
+
+func profileSocials(raw map[string]string) (map[string]string, error) {
+	socials := make(map[string]string, len(raw))
+	for network, handle := range raw {
+		network = strings.ToLower(strings.TrimSpace(network))
+		handle = strings.TrimSpace(handle)
+		if handle == "" {
+			continue
+		}
+		switch {
+		case network == "website":
+			if !validHTTPSURL(handle, maxWebsiteLength) {
+				return nil, fiber.NewError(fiber.StatusBadRequest, "website must be an https url")
+			}
+		case !slices.Contains(profileSocialNetworks, network):
+			return nil, fiber.NewError(fiber.StatusBadRequest,
+				"socials must be one of "+strings.Join(profileSocialNetworks, ", "))
+		case len(handle) > maxSocialHandleLength || !socialHandlePattern.MatchString(handle):
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s handle is not valid", network))
+		}
+		socials[network] = strings.TrimPrefix(handle, "@")
+	}
+	return socials, nil
+}
+
+func validHTTPSURL(raw string, maxLength int) bool {
+	if len(raw) > maxLength {
+		return false
+	}
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// queryTime reads a unix timestamp in seconds from the query string
+func queryTime(c *fiber.Ctx, key string, fallback time.Time) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return fallback, nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fiber.NewError(fiber.StatusBadRequest, key+" must be a unix timestamp")
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// queryPage reads limit and offset pagination from the query string
+func queryPage(c *fiber.Ctx) (int, int, error) {
+	limit := c.QueryInt("limit", defaultPageSize)
+	if limit <= 0 || limit > maxPageSize {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxPageSize))
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "offset must not be negative")
+	}
+	return limit, offset, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/pricing"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"strconv"
+)
+
+// quoteMarket quotes a bet on a side of a binary market, LONG being YES and SHORT NO for
+// scalar markets, or on an outcome of a categorical market given by its id
+func (s *server) quoteMarket(c *fiber.Ctx) error {
+	amount, err := strconv.ParseUint(c.Query("amount"), 10, 64)
+	if err != nil || amount == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "amount must be a positive integer of lamports")
+	}
+	market, err := s.predictionRepo.GetMarket(c.UserContext(), c.Params("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "market not found")
+	} else if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	if market.Kind == prediction.MarketKindCategorical {
+		return s.quoteOutcome(c, market, amount)
+	}
+	side, err := pricing.ParseSide(c.Query("side"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "side must be YES or NO")
+	}
+	pool := pricing.Pool{Yes: float64(market.YesAmount), No: float64(market.NoAmount)}
+	quote, err := s.pricingModel.Quote(pool, side, float64(amount))
+	if errors.Is(err, pricing.ErrNoLiquidity) {
+		return fiber.NewError(fiber.StatusConflict, "market has no liquidity")
+	} else if err != nil {
+		return fmt.Errorf("quote: %w", err)
+	}
+	yesPrice, err := s.pricingModel.Price(pool, pricing.SideYes)
+	if err != nil {
+		return fmt.Errorf("price: %w", err)
+	}
+	response := fiber.Map{
+		"market_id":       market.ID,
+		"model":           s.pricingModel.Name(),
+		"yes_probability": yesPrice,
+		"no_probability":  1 - yesPrice,
+		"volume":          market.YesAmount + market.NoAmount,
+		"quote":           quote,
+	}
+	// The LONG price of a scalar market places the expected value within its range
+	if market.Kind == prediction.MarketKindScalar {
+		lower, upper := market.ScalarLower.Float64, market.ScalarUpper.Float64
+		response["implied_value"] = lower + yesPrice*(upper-lower)
+	}
+	return c.JSON(response)
+}
+
+func (s *server) quoteOutcome(c *fiber.Ctx, market prediction.Market, amount uint64) error {
+	outcome, err := strconv.Atoi(c.Query("outcome"))
+	if err != nil || outcome < 0 || outcome >= len(market.Outcomes) {
+		return fiber.NewError(fiber.StatusBadRequest, "outcome must be an outcome id of the market")
+	}
+	pools := market.OutcomeAmounts()
+	quote, err := s.pricingModel.QuoteOutcome(pools, outcome, float64(amount))
+	if errors.Is(err, pricing.ErrNoLiquidity) {
+		return fiber.NewError(fiber.StatusConflict, "market has no liquidity")
+	} else if err != nil {
+		return fmt.Errorf("quote: %w", err)
+	}
+	prices, err := s.pricingModel.Prices(pools)
+	if err != nil {
+		return fmt.Errorf("price: %w", err)
+	}
+	var volume int64
+	for _, o := range market.Outcomes {
+		volume += o.Amount
+	}
+	return c.JSON(fiber.Map{
+		"market_id":     market.ID,
+		"model":         s.pricingModel.Name(),
+		"probabilities": prices,
+		"volume":        volume,
+		"quote":         quote,
+	})
+}
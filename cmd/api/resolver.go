@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/resolver"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/nanoid"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+func (s *server) marketResolver(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	market, err := s.predictionRepo.GetMarket(ctx, c.Params("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "market not found")
+	} else if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	assignments, err := s.resolverRepo.ListAssignments(ctx, market.ID)
+	if err != nil {
+		return fmt.Errorf("list assignments: %w", err)
+	}
+	return c.JSON(fiber.Map{
+		"resolver_pubkey": market.ResolverPubkey,
+		"resolver_status": market.ResolverStatus,
+		"assignments":     assignments,
+	})
+}
+
+// respondResolverAssignment lets the nominated resolver co-sign or decline the assignment.
+// Accepting makes the wallet the resolver of record and replaces the previous one.
+func (s *server) respondResolverAssignment(c *fiber.Ctx) error {
+	type ResponseData struct {
+		Resolver string `json:"resolver"`
+		MarketID string `json:"marketID"`
+		Accept   bool   `json:"accept"`
+	}
+	var responseData ResponseData
+	resolverPubkey, err := verifySignedRequest(c, signedActionRespondResolver, &responseData, &responseData.Resolver)
+	if err != nil {
+		return err
+	}
+	marketID := c.Params("id")
+	if responseData.MarketID != marketID {
+		return fiber.NewError(fiber.StatusBadRequest, "signed market does not match")
+	}
+	now := time.Now()
+	var assignment resolver.Assignment
+	err = s.resolverRepo.RunInTx(c.UserContext(), func(ctx context.Context) error {
+		var err error
+		assignment, err = s.resolverRepo.GetPendingAssignment(ctx, marketID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fiber.NewError(fiber.StatusNotFound, "market has no pending resolver assignment")
+		} else if err != nil {
+			return fmt.Errorf("get pending assignment: %w", err)
+		}
+		if assignment.ResolverPubkey != resolverPubkey.String() {
+			return fiber.NewError(fiber.StatusForbidden, "wallet is not the nominated resolver")
+		}
+		if !responseData.Accept {
+			assignment.Status = resolver.AssignmentStatusDeclined
+			return s.resolverRepo.RespondAssignment(ctx, assignment.ID, assignment.Status, now)
+		}
+		if err = s.resolverRepo.ReplaceAccepted(ctx, marketID); err != nil {
+			return fmt.Errorf("replace accepted assignment: %w", err)
+		}
+		assignment.Status = resolver.AssignmentStatusAccepted
+		if err = s.resolverRepo.RespondAssignment(ctx, assignment.ID, assignment.Status, now); err != nil {
+			return fmt.Errorf("accept assignment: %w", err)
+		}
+		return s.resolverRepo.SetMarketResolver(ctx, marketID, assignment.ResolverPubkey, prediction.ResolverStatusAccepted)
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(assignment)
+}
+
+// delegateResolver nominates another resolver for the market. The current resolver signs
+// the delegation, or the creator while its own nomination has not been accepted yet.
+// The delegate becomes the resolver of record only after accepting. Binary markets are
+// resolved on chain by the resolver their init transaction names, so theirs is fixed once accepted.
+func (s *server) delegateResolver(c *fiber.Ctx) error {
+	type DelegationData struct {
+		Signer   string `json:"signer"`
+		MarketID string `json:"marketID"`
+		Delegate string `json:"delegate"`
+	}
+	var delegationData DelegationData
+	signerPubkey, err := verifySignedRequest(c, signedActionDelegateResolver, &delegationData, &delegationData.Signer)
+	if err != nil {
+		return err
+	}
+	delegatePubkey, err := solana.PublicKeyFromBase58(delegationData.Delegate)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "delegate pubkey is not valid")
+	}
+	marketID := c.Params("id")
+	if delegationData.MarketID != marketID {
+		return fiber.NewError(fiber.StatusBadRequest, "signed market does not match")
+	}
+	ctx := c.UserContext()
+	market, err := s.predictionRepo.GetMarket(ctx, marketID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "market not found")
+	} else if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	if market.Resolution != prediction.MarketResolutionUnresolved {
+		return fiber.NewError(fiber.StatusConflict, "market is already resolved")
+	}
+	if market.Binary() && market.ResolverStatus == prediction.ResolverStatusAccepted {
+		return fiber.NewError(fiber.StatusConflict, "resolver of a binary market cannot be delegated once accepted")
+	}
+	delegator := market.ResolverPubkey
+	if market.ResolverStatus == prediction.ResolverStatusPending {
+		delegator = market.CreatorPubkey
+	}
+	if signerPubkey.String() != delegator {
+		return fiber.NewError(fiber.StatusForbidden, "only the current resolver can delegate")
+	}
+	if delegatePubkey.String() == market.ResolverPubkey && market.ResolverStatus == prediction.ResolverStatusAccepted {
+		return fiber.NewError(fiber.StatusBadRequest, "delegate is already the resolver")
+	}
+	now := time.Now()
+	assignment := resolver.Assignment{
+		ID:             nanoid.RandomID(),
+		MarketID:       marketID,
+		ResolverPubkey: delegatePubkey.String(),
+		NominatedBy:    signerPubkey.String(),
+		Status:         resolver.AssignmentStatusPending,
+		CreatedAt:      now,
+	}
+	err = s.resolverRepo.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.resolverRepo.RevokePending(ctx, marketID, now); err != nil {
+			return fmt.Errorf("revoke pending assignment: %w", err)
+		}
+		return s.resolverRepo.CreateAssignment(ctx, assignment)
+	})
+	if err != nil {
+		return fmt.Errorf("create assignment: %w", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(assignment)
+}
+
+func (s *server) resolverReputation(c *fiber.Ctx) error {
+	pubkey, err := solana.PublicKeyFromBase58(c.Params("pubkey"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "pubkey is not valid")
+	}
+	reputation, err := s.resolverRepo.GetReputation(c.UserContext(), pubkey.String())
+	if err != nil {
+		return fmt.Errorf("get reputation: %w", err)
+	}
+	return c.JSON(reputation)
+}
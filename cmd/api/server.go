@@ -1,7 +1,29 @@
 package main
 
 import (
+	"github.com/IndexStorm/hit-my-bet-back/internal/arbitration"
+	"github.com/IndexStorm/hit-my-bet-back/internal/chain"
+	"github.com/IndexStorm/hit-my-bet-back/internal/config"
+	"github.com/IndexStorm/hit-my-bet-back/internal/fees"
+	"github.com/IndexStorm/hit-my-bet-back/internal/notify"
+	"github.com/IndexStorm/hit-my-bet-back/internal/oracle"
+	"github.com/IndexStorm/hit-my-bet-back/internal/portfolio"
+	"github.com/IndexStorm/hit-my-bet-back/internal/pricing"
+	"github.com/IndexStorm/hit-my-bet-back/internal/program"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/comment"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/dispute"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/feed"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/history"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/leaderboard"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/ledger"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/moderation"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/notification"
 	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/profile"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/resolver"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/social"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/trending"
+	"github.com/IndexStorm/hit-my-bet-back/internal/sns"
 	"github.com/goccy/go-json"
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
@@ -10,13 +32,69 @@ import (
 )
 
 type server struct {
-	app            *fiber.App
-	logger         zerolog.Logger
-	tracer         trace.Tracer
-	predictionRepo prediction.Repository
+	app              *fiber.App
+	logger           zerolog.Logger
+	tracer           trace.Tracer
+	predictionRepo   prediction.Repository
+	historyRepo      history.Repository
+	rebroadcaster    *chain.Rebroadcaster
+	listener         *chain.Listener
+	decoder          *program.Decoder
+	pricingModel     pricing.Model
+	accountant       *fees.Accountant
+	ledgerRepo       ledger.Repository
+	portfolios       *portfolio.Service
+	leaderboardRepo  leaderboard.Repository
+	moderationRepo   moderation.Repository
+	adminConfig      config.Admin
+	court            *arbitration.Court
+	disputeRepo      dispute.Repository
+	disputeConfig    config.Dispute
+	resolverRepo     resolver.Repository
+	feedRepo         feed.Repository
+	oracles          *oracle.Registry
+	commentRepo      comment.Repository
+	commentsConfig   config.Comments
+	profileRepo      profile.Repository
+	snsResolver      *sns.Resolver
+	socialRepo       social.Repository
+	notificationRepo notification.Repository
+	notifier         *notify.Notifier
+	trendingRepo     trending.Repository
+	trendingConfig   config.Trending
 }
 
-func newServer(logger zerolog.Logger, tr trace.Tracer, predictionRepo prediction.Repository) *server {
+func newServer(
+	logger zerolog.Logger,
+	tr trace.Tracer,
+	predictionRepo prediction.Repository,
+	historyRepo history.Repository,
+	rebroadcaster *chain.Rebroadcaster,
+	listener *chain.Listener,
+	decoder *program.Decoder,
+	pricingModel pricing.Model,
+	accountant *fees.Accountant,
+	ledgerRepo ledger.Repository,
+	portfolios *portfolio.Service,
+	leaderboardRepo leaderboard.Repository,
+	moderationRepo moderation.Repository,
+	adminConfig config.Admin,
+	court *arbitration.Court,
+	disputeRepo dispute.Repository,
+	disputeConfig config.Dispute,
+	resolverRepo resolver.Repository,
+	feedRepo feed.Repository,
+	oracles *oracle.Registry,
+	commentRepo comment.Repository,
+	commentsConfig config.Comments,
+	profileRepo profile.Repository,
+	snsResolver *sns.Resolver,
+	socialRepo social.Repository,
+	notificationRepo notification.Repository,
+	notifier *notify.Notifier,
+	trendingRepo trending.Repository,
+	trendingConfig config.Trending,
+) *server {
 	app := fiber.New(fiber.Config{
 		DisableStartupMessage: true,
 		ReadTimeout:           time.Second * 15,
@@ -26,10 +104,36 @@ func newServer(logger zerolog.Logger, tr trace.Tracer, predictionRepo prediction
 		JSONDecoder:           json.Unmarshal,
 	})
 	return &server{
-		app:            app,
-		logger:         logger,
-		tracer:         tr,
-		predictionRepo: predictionRepo,
+		app:              app,
+		logger:           logger,
+		tracer:           tr,
+		predictionRepo:   predictionRepo,
+		historyRepo:      historyRepo,
+		rebroadcaster:    rebroadcaster,
+		listener:         listener,
+		decoder:          decoder,
+		pricingModel:     pricingModel,
+		accountant:       accountant,
+		ledgerRepo:       ledgerRepo,
+		portfolios:       portfolios,
+		leaderboardRepo:  leaderboardRepo,
+		moderationRepo:   moderationRepo,
+		adminConfig:      adminConfig,
+		court:            court,
+		disputeRepo:      disputeRepo,
+		disputeConfig:    disputeConfig,
+		resolverRepo:     resolverRepo,
+		feedRepo:         feedRepo,
+		oracles:          oracles,
+		commentRepo:      commentRepo,
+		commentsConfig:   commentsConfig,
+		profileRepo:      profileRepo,
+		snsResolver:      snsResolver,
+		socialRepo:       socialRepo,
+		notificationRepo: notificationRepo,
+		notifier:         notifier,
+		trendingRepo:     trendingRepo,
+		trendingConfig:   trendingConfig,
 	}
 }
 
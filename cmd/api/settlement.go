@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+// marketSettlement returns the stored settlement of the market. Settlements are made by
+// the indexer and the finalizer once the resolution is final, never on read.
+func (s *server) marketSettlement(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	market, err := s.predictionRepo.GetMarket(ctx, c.Params("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "market not found")
+	} else if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	result, err := s.predictionRepo.GetSettlement(ctx, market.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusConflict, "market is not settled yet")
+	} else if err != nil {
+		return fmt.Errorf("get settlement: %w", err)
+	}
+	payouts, err := s.predictionRepo.GetSettlementPayouts(ctx, market.ID)
+	if err != nil {
+		return fmt.Errorf("get payouts: %w", err)
+	}
+	owners := make([]string, 0, len(payouts))
+	for _, payout := range payouts {
+		owners = append(owners, payout.OwnerPubkey)
+	}
+	profiles, err := s.profileSummaries(ctx, owners)
+	if err != nil {
+		return err
+	}
+	for i := range payouts {
+		if summary, ok := profiles[payouts[i].OwnerPubkey]; ok {
+			payouts[i].OwnerProfile = &summary
+		}
+	}
+	return c.JSON(fiber.Map{
+		"settlement": result,
+		"payouts":    payouts,
+	})
+}
+
+func (s *server) userClaims(c *fiber.Ctx) error {
+	owner, err := solana.PublicKeyFromBase58(c.Params("pubkey"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "pubkey is not valid")
+	}
+	claims, err := s.predictionRepo.GetClaims(c.UserContext(), owner.String())
+	if err != nil {
+		return fmt.Errorf("get claims: %w", err)
+	}
+	return c.JSON(fiber.Map{"claims": claims})
+}
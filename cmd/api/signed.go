@@ -0,0 +1,107 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+const (
+	// signedRequestTTL is how long a signed request is accepted after its timestamp
+	signedRequestTTL = 5 * time.Minute
+
+	walletPubkeyHeader    = "X-Wallet-Pubkey"
+	walletTimestampHeader = "X-Wallet-Timestamp"
+	walletSignatureHeader = "X-Wallet-Signature"
+)
+
+// Actions signed into every request payload, so that a signature made for one endpoint
+// cannot be replayed against another one taking a payload with the same fields
+const (
+	signedActionClaimFees           = "claim_fees"
+	signedActionReportMarket        = "report_market"
+	signedActionChallengeResolution = "challenge_resolution"
+	signedActionRuleResolution      = "rule_resolution"
+	signedActionRespondResolver     = "respond_resolver"
+	signedActionDelegateResolver    = "delegate_resolver"
+	signedActionResolveMarket       = "resolve_market"
+	signedActionUpdateProfile       = "update_profile"
+	signedActionCreateComment       = "create_comment"
+	signedActionEditComment         = "edit_comment"
+	signedActionDeleteComment       = "delete_comment"
+	signedActionReactComment        = "react_comment"
+	signedActionReportComment       = "report_comment"
+	signedActionFollow              = "follow"
+	signedActionUnfollow            = "unfollow"
+	signedActionReadNotifications   = "read_notifications"
+	signedActionSetPreferences      = "set_notification_preferences"
+)
+
+// verifySignedRequest reads a {rawData, signature} request into data and checks that the
+// wallet in the signer field of data signed it for the action less than signedRequestTTL ago.
+// Besides its own fields every payload carries the action and a timestamp in milliseconds.
+func verifySignedRequest(c *fiber.Ctx, action string, data any, signer *string) (solana.PublicKey, error) {
+	type SignedData struct {
+		Action    string `json:"action"`
+		Timestamp int64  `json:"timestamp"`
+	}
+	type Request struct {
+		RawData   string `json:"rawData"`
+		Signature []byte `json:"signature"`
+	}
+	var request Request
+	if err := json.Unmarshal(c.Body(), &request); err != nil {
+		return solana.PublicKey{}, fmt.Errorf("unmarshal request: %w", err)
+	}
+	var signedData SignedData
+	if err := json.Unmarshal([]byte(request.RawData), &signedData); err != nil {
+		return solana.PublicKey{}, fmt.Errorf("unmarshal signed data: %w", err)
+	}
+	if err := json.Unmarshal([]byte(request.RawData), data); err != nil {
+		return solana.PublicKey{}, fmt.Errorf("unmarshal %s data: %w", action, err)
+	}
+	signerPubkey, err := solana.PublicKeyFromBase58(*signer)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("invalid signer pubkey: %w", err)
+	}
+	if !signerPubkey.Verify([]byte(request.RawData), solana.SignatureFromBytes(request.Signature)) {
+		return solana.PublicKey{}, fiber.NewError(fiber.StatusUnauthorized, "signature is not valid")
+	}
+	if signedData.Action != action {
+		return solana.PublicKey{}, fiber.NewError(fiber.StatusBadRequest, "signed action does not match")
+	}
+	if time.Since(time.UnixMilli(signedData.Timestamp)).Abs() > signedRequestTTL {
+		return solana.PublicKey{}, fiber.NewError(fiber.StatusUnauthorized, "request expired")
+	}
+	return signerPubkey, nil
+}
+
+// verifyWalletHeaders returns the wallet that signed a request without a payload of its own,
+// such as reading its inbox. The wallet signs the same message as admin wallets do.
+func verifyWalletHeaders(c *fiber.Ctx) (solana.PublicKey, error) {
+	pubkey, err := solana.PublicKeyFromBase58(c.Get(walletPubkeyHeader))
+	if err != nil {
+		return solana.PublicKey{}, fiber.NewError(fiber.StatusUnauthorized, "wallet signature required")
+	}
+	timestamp, err := strconv.ParseInt(c.Get(walletTimestampHeader), 10, 64)
+	if err != nil || time.Since(time.UnixMilli(timestamp)).Abs() > signedRequestTTL {
+		return solana.PublicKey{}, fiber.NewError(fiber.StatusUnauthorized, "request expired")
+	}
+	signature, err := solana.SignatureFromBase58(c.Get(walletSignatureHeader))
+	if err != nil || !pubkey.Verify(signedHeadersMessage(c, timestamp), signature) {
+		return solana.PublicKey{}, fiber.NewError(fiber.StatusUnauthorized, "signature is not valid")
+	}
+	return pubkey, nil
+}
+
+// signedHeadersMessage is "<METHOD> <path> <unix ms timestamp> <hex sha256 of the body>",
+// the message signed by wallets authenticating through headers
+func signedHeadersMessage(c *fiber.Ctx, timestamp int64) []byte {
+	bodyHash := sha256.Sum256(c.Body())
+	return fmt.Appendf(nil, "%s %s %d %s", c.Method(), c.Path(), timestamp, hex.EncodeToString(bodyHash[:]))
+}
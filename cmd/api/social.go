@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/social"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultSocialPageSize = 20
+	maxSocialPageSize     = 100
+	// maxFollowing bounds the fan-out of a feed read
+	maxFollowing = 1000
+)
+
+// followUser follows the wallet of the path by the signing wallet
+func (s *server) followUser(c *fiber.Ctx) error {
+	follow, err := followRequest(c, signedActionFollow)
+	if err != nil {
+		return err
+	}
+	ctx := c.UserContext()
+	following, err := s.socialRepo.CountFollowing(ctx, follow.FollowerPubkey)
+	if err != nil {
+		return fmt.Errorf("count following: %w", err)
+	}
+	if following >= maxFollowing {
+		return fiber.NewError(fiber.StatusConflict, "wallet follows too many wallets")
+	}
+	created, err := s.socialRepo.Follow(ctx, follow)
+	if err != nil {
+		return fmt.Errorf("follow: %w", err)
+	}
+	return c.JSON(fiber.Map{"following": true, "created": created})
+}
+
+func (s *server) unfollowUser(c *fiber.Ctx) error {
+	follow, err := followRequest(c, signedActionUnfollow)
+	if err != nil {
+		return err
+	}
+	removed, err := s.socialRepo.Unfollow(c.UserContext(), follow.FollowerPubkey, follow.FolloweePubkey)
+	if err != nil {
+		return fmt.Errorf("unfollow: %w", err)
+	}
+	return c.JSON(fiber.Map{"following": false, "removed": removed})
+}
+
+// followRequest verifies a follow or unfollow of the wallet of the path, signed for the action
+func followRequest(c *fiber.Ctx, action string) (social.Follow, error) {
+	type FollowData struct {
+		Follower string `json:"follower"`
+		Followee string `json:"followee"`
+	}
+	var followData FollowData
+	followerPubkey, err := verifySignedRequest(c, action, &followData, &followData.Follower)
+	if err != nil {
+		return social.Follow{}, err
+	}
+	followeePubkey, err := solana.PublicKeyFromBase58(c.Params("pubkey"))
+	if err != nil {
+		return social.Follow{}, fiber.NewError(fiber.StatusBadRequest, "pubkey is not valid")
+	}
+	if followData.Followee != followeePubkey.String() {
+		return social.Follow{}, fiber.NewError(fiber.StatusBadRequest, "signed followee does not match")
+	}
+	if followerPubkey.Equals(followeePubkey) {
+		return social.Follow{}, fiber.NewError(fiber.StatusBadRequest, "wallet cannot follow itself")
+	}
+	return social.Follow{
+		FollowerPubkey: followerPubkey.String(),
+		FolloweePubkey: followeePubkey.String(),
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+func (s *server) userFollowing(c *fiber.Ctx) error {
+	return s.listFollows(c, true)
+}
+
+func (s *server) userFollowers(c *fiber.Ctx) error {
+	return s.listFollows(c, false)
+}
+
+// listFollows pages the wallets the wallet of the path follows, or its followers
+func (s *server) listFollows(c *fiber.Ctx, following bool) error {
+	pubkey, err := solana.PublicKeyFromBase58(c.Params("pubkey"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "pubkey is not valid")
+	}
+	after, limit, err := socialPage(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.UserContext()
+	var (
+		follows []social.Follow
+		total   int64
+	)
+	if following {
+		follows, err = s.socialRepo.ListFollowing(ctx, pubkey.String(), after, limit)
+		if err == nil {
+			total, err = s.socialRepo.CountFollowing(ctx, pubkey.String())
+		}
+	} else {
+		follows, err = s.socialRepo.ListFollowers(ctx, pubkey.String(), after, limit)
+		if err == nil {
+			total, err = s.socialRepo.CountFollowers(ctx, pubkey.String())
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("list follows: %w", err)
+	}
+	wallets := make([]string, 0, len(follows))
+	for _, follow := range follows {
+		if following {
+			wallets = append(wallets, follow.FolloweePubkey)
+		} else {
+			wallets = append(wallets, follow.FollowerPubkey)
+		}
+	}
+	profiles, err := s.profileSummaries(ctx, wallets)
+	if err != nil {
+		return err
+	}
+	var next string
+	if len(follows) == limit {
+		next = encodeCursor(follows[len(follows)-1].CreatedAt, wallets[len(wallets)-1])
+	}
+	return c.JSON(fiber.Map{
+		"follows":     follows,
+		"profiles":    profiles,
+		"total":       total,
+		"next_cursor": next,
+	})
+}
+
+// userFeed returns recent markets created and positions taken by the wallets the
+// wallet of the pubkey query follows
+func (s *server) userFeed(c *fiber.Ctx) error {
+	follower, err := solana.PublicKeyFromBase58(c.Query("pubkey"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "pubkey is not valid")
+	}
+	after, limit, err := socialPage(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.UserContext()
+	activities, err := s.socialRepo.ListFeed(ctx, follower.String(), after, limit)
+	if err != nil {
+		return fmt.Errorf("list feed: %w", err)
+	}
+	actors := make([]string, 0, len(activities))
+	for _, activity := range activities {
+		actors = append(actors, activity.ActorPubkey)
+	}
+	profiles, err := s.profileSummaries(ctx, actors)
+	if err != nil {
+		return err
+	}
+	for i := range activities {
+		if summary, ok := profiles[activities[i].ActorPubkey]; ok {
+			activities[i].ActorProfile = &summary
+		}
+	}
+	var next string
+	if len(activities) == limit {
+		last := activities[len(activities)-1]
+		next = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return c.JSON(fiber.Map{
+		"activities":  activities,
+		"next_cursor": next,
+	})
+}
+
+// socialPage reads the cursor and limit of follow and feed listings
+func socialPage(c *fiber.Ctx) (*social.Cursor, int, error) {
+	limit := c.QueryInt("limit", defaultSocialPageSize)
+	if limit <= 0 || limit > maxSocialPageSize {
+		return nil, 0, fiber.NewError(fiber.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxSocialPageSize))
+	}
+	value := c.Query("cursor")
+	if value == "" {
+		return nil, limit, nil
+	}
+	createdAt, id, err := decodeCursor(value)
+	if err != nil {
+		return nil, 0, fiber.NewError(fiber.StatusBadRequest, "cursor is not valid")
+	}
+	return &social.Cursor{CreatedAt: createdAt, ID: id}, limit, nil
+}
@@ -1,78 +1,47 @@
 package main
 
 import (
-	"errors"
+	"context"
+	"encoding/base64"
 	"fmt"
+	"github.com/gagliardetto/solana-go"
 	"github.com/goccy/go-json"
 	"github.com/gofiber/fiber/v2"
-	"github.com/imroc/req/v3"
-	"math/rand/v2"
-	"time"
 )
 
-var solanaClient *req.Client
-
-func init() {
-	solanaClient = req.C().
-		SetTimeout(time.Second * 15).
-		SetJsonMarshal(json.Marshal).
-		SetJsonUnmarshal(json.Unmarshal)
-}
-
 func (s *server) relayTx(c *fiber.Ctx) error {
 	type Request struct {
-		TxData string `json:"txData"`
+		TxData               string `json:"txData"`
+		LastValidBlockHeight uint64 `json:"lastValidBlockHeight"`
 	}
 	var request Request
 	if err := json.Unmarshal(c.Body(), &request); err != nil {
 		return fmt.Errorf("unmarshal request: %w", err)
 	}
-	txHash, err := s.relayTxData(request.TxData)
+	txHash, err := s.relayTxData(c.UserContext(), request.TxData, request.LastValidBlockHeight, "", "")
 	if err != nil {
 		return err
 	}
-	return c.JSON(fiber.Map{"tx_hash": txHash})
+	return c.JSON(fiber.Map{"tx_hash": txHash.String()})
 }
 
-func (s *server) relayTxData(data string) (string, error) {
-	type RpcParams struct {
-		Encoding   string `json:"encoding"`
-		MaxRetries uint8  `json:"maxRetries"`
-	}
-	type RpcRequest struct {
-		Jsonrpc string        `json:"jsonrpc"`
-		Id      int           `json:"id"`
-		Method  string        `json:"method"`
-		Params  []interface{} `json:"params"`
-	}
-	rpcRequest := RpcRequest{
-		Jsonrpc: "2.0",
-		Id:      rand.Int(),
-		Method:  "sendTransaction",
-		Params:  []interface{}{data, RpcParams{Encoding: "base64", MaxRetries: 1}},
-	}
-	resp, err := solanaClient.R().SetBodyJsonMarshal(&rpcRequest).Post("https://api.devnet.solana.com")
+func (s *server) relayTxData(
+	ctx context.Context,
+	data string,
+	lastValidBlockHeight uint64,
+	kind string,
+	subject string,
+) (solana.Signature, error) {
+	raw, err := base64.StdEncoding.DecodeString(data)
 	if err != nil {
-		return "", fmt.Errorf("relay to solana: %w", err)
-	}
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("unexpected status %d: %s", resp.StatusCode, resp.String())
+		return solana.Signature{}, fiber.NewError(fiber.StatusBadRequest, "tx data is not valid base64")
 	}
-	type Response struct {
-		Jsonrpc string          `json:"jsonrpc"`
-		Result  string          `json:"result"`
-		Error   json.RawMessage `json:"error"`
-	}
-	var response Response
-	if err = json.Unmarshal(resp.Bytes(), &response); err != nil {
-		return "", fmt.Errorf("unmarshal response: %w", err)
+	signature, err := s.rebroadcaster.Submit(ctx, raw, lastValidBlockHeight, kind, subject)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("relay to solana: %w", err)
 	}
-	if response.Error != nil {
-		errData, err := json.Marshal(response.Error)
-		if err != nil {
-			return "", fmt.Errorf("failed: %+v", response.Error)
-		}
-		return "", errors.New(string(errData))
+	if s.listener != nil {
+		s.listener.WatchSignature(signature)
 	}
-	return response.Result, nil
+	return signature, nil
 }
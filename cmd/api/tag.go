@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strings"
+	"unicode"
+)
+
+const (
+	maxMarketTags = 5
+	maxTagLength  = 32
+)
+
+// normalizeTags lowercases tags, joins words with dashes, drops other punctuation
+// and duplicates so that "Crypto ", "crypto" and "#crypto" end up the same tag
+func normalizeTags(raw []string) ([]string, error) {
+	tags := make([]string, 0, len(raw))
+	seen := make(map[string]struct{}, len(raw))
+	for _, value := range raw {
+		var b strings.Builder
+		for _, word := range strings.Fields(strings.ToLower(value)) {
+			word = strings.Map(func(r rune) rune {
+				if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+					return r
+				}
+				return -1
+			}, word)
+			if word == "" {
+				continue
+			}
+			if b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteString(word)
+		}
+		tag := strings.Trim(b.String(), "-")
+		if tag == "" {
+			continue
+		}
+		if len(tag) > maxTagLength {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("tag %q is longer than %d", tag, maxTagLength))
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxMarketTags {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("at most %d tags are allowed", maxMarketTags))
+	}
+	return tags, nil
+}
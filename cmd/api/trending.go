@@ -0,0 +1,51 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strings"
+	"time"
+)
+
+// trendingMarkets lists open listed markets by their decayed popularity, the ranking is
+// refreshed by the scheduler so closed or hidden markets drop out right away but new
+// activity shows up after the next refresh
+func (s *server) trendingMarkets(c *fiber.Ctx) error {
+	limit, offset, err := queryPage(c)
+	if err != nil {
+		return err
+	}
+	markets, err := s.predictionRepo.ListTrendingMarkets(c.UserContext(), c.Query("category"), limit, offset)
+	if err != nil {
+		return fmt.Errorf("list trending markets: %w", err)
+	}
+	return c.JSON(fiber.Map{"markets": markets})
+}
+
+// recordMarketView counts a view of the market page towards its trending score. Views are
+// counted once per client address and hour.
+func (s *server) recordMarketView(c *fiber.Ctx) error {
+	address := c.IP()
+	if header := s.trendingConfig.ViewerIPHeader; header != "" {
+		// Proxies append the address they received from, entries left of the one added by
+		// our proxy come from the client and cannot be trusted
+		forwarded := c.Get(header)
+		if i := strings.LastIndexByte(forwarded, ','); i >= 0 {
+			forwarded = forwarded[i+1:]
+		}
+		if forwarded = strings.TrimSpace(forwarded); forwarded != "" {
+			address = forwarded
+		}
+	}
+	viewer := sha256.Sum256([]byte(address))
+	recorded, err := s.trendingRepo.RecordView(c.UserContext(), c.Params("id"), hex.EncodeToString(viewer[:]), time.Now())
+	if err != nil {
+		return fmt.Errorf("record view: %w", err)
+	}
+	if !recorded {
+		return fiber.NewError(fiber.StatusNotFound, "market not found")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
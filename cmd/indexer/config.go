@@ -0,0 +1,28 @@
+package main
+
+import (
+	"github.com/IndexStorm/hit-my-bet-back/internal/config"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+type appConfig struct {
+	Environment    config.DefaultEnvironment
+	LogLevel       zerolog.Level   `env:"LOG_LEVEL,notEmpty"`
+	Database       config.Database `envPrefix:"DB_" env:"notEmpty"`
+	Solana         config.Solana   `envPrefix:"SOLANA_"`
+	Pricing        config.Pricing  `envPrefix:"PRICING_"`
+	Dispute        config.Dispute  `envPrefix:"DISPUTE_"`
+	Oracle         config.Oracle   `envPrefix:"ORACLE_"`
+	PollInterval   time.Duration   `env:"POLL_INTERVAL" envDefault:"5s"`
+	MaxTxAttempts  int32           `env:"MAX_TX_ATTEMPTS" envDefault:"5"`
+	RollupInterval time.Duration   `env:"ROLLUP_INTERVAL" envDefault:"1m"`
+
+	LeaderboardInterval time.Duration `env:"LEADERBOARD_INTERVAL" envDefault:"5m"`
+	FinalizeInterval    time.Duration `env:"FINALIZE_INTERVAL" envDefault:"1m"`
+
+	// SchedulerLockKey elects the replica running the periodic jobs, it must differ from
+	// the lock of the API scheduler so that each service has its own leader
+	SchedulerLockKey    int64         `env:"SCHEDULER_LOCK_KEY" envDefault:"7306"`
+	LeaderCheckInterval time.Duration `env:"SCHEDULER_LEADER_CHECK_INTERVAL" envDefault:"10s"`
+}
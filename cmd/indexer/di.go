@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/arbitration"
+	"github.com/IndexStorm/hit-my-bet-back/internal/candle"
+	"github.com/IndexStorm/hit-my-bet-back/internal/fees"
+	"github.com/IndexStorm/hit-my-bet-back/internal/idl"
+	"github.com/IndexStorm/hit-my-bet-back/internal/indexer"
+	"github.com/IndexStorm/hit-my-bet-back/internal/notify"
+	"github.com/IndexStorm/hit-my-bet-back/internal/oracle"
+	"github.com/IndexStorm/hit-my-bet-back/internal/postgres"
+	"github.com/IndexStorm/hit-my-bet-back/internal/pricing"
+	"github.com/IndexStorm/hit-my-bet-back/internal/program"
+	"github.com/IndexStorm/hit-my-bet-back/internal/ranking"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/checkpoint"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/dispute"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/feed"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/history"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/leaderboard"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/ledger"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/notification"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/internal/rpcpool"
+	"github.com/IndexStorm/hit-my-bet-back/internal/scheduler"
+	"github.com/IndexStorm/hit-my-bet-back/internal/settlement"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type dependencyBuilder struct {
+	config appConfig
+	logger zerolog.Logger
+	tracer trace.Tracer
+}
+
+func newDependencyBuilder(config appConfig, logger zerolog.Logger) *dependencyBuilder {
+	return &dependencyBuilder{
+		config: config,
+		logger: logger,
+		tracer: otel.Tracer("dependency-builder"),
+	}
+}
+
+func (b *dependencyBuilder) build(ctx context.Context) (*applicationDependencies, error) {
+	programID, err := solana.PublicKeyFromBase58(b.config.Solana.ProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("parse program id: %w", err)
+	}
+	decoder := program.NewDecoder(programID, nil)
+	if b.config.Solana.IdlPath != "" {
+		programIDL, err := idl.Load(b.config.Solana.IdlPath)
+		if err != nil {
+			return nil, fmt.Errorf("load idl: %w", err)
+		}
+		decoder = program.NewDecoder(programID, programIDL)
+	}
+	db, err := b.newDatabase(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("prepare database: %w", err)
+	}
+	dependencies := &applicationDependencies{database: db}
+
+	solanaClient, err := rpcpool.NewSolanaClient(b.config.Solana, b.logger.With().Str("sys", "rpcpool").Logger())
+	if err != nil {
+		return nil, fmt.Errorf("prepare solana client: %w", err)
+	}
+	dependencies.solanaClient = solanaClient
+
+	pricingModel, err := pricing.New(pricing.Config{
+		Model:     b.config.Pricing.Model,
+		Liquidity: b.config.Pricing.LmsrLiquidity,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("prepare pricing model: %w", err)
+	}
+	historyRepo := history.NewPostgres(db)
+	indexerLogger := b.logger.With().Str("sys", "indexer").Logger()
+	predictionRepo := prediction.NewPostgres(db)
+	disputeRepo := dispute.NewPostgres(db)
+	accountant := fees.NewAccountant(ledger.NewPostgres(db))
+	notificationRepo := notification.NewPostgres(db)
+	notifier := notify.NewNotifier(notificationRepo, predictionRepo)
+	settler := settlement.NewSettler(predictionRepo, disputeRepo, accountant, notifier)
+	court := arbitration.NewCourt(disputeRepo, predictionRepo, notifier, b.config.Dispute.Period)
+	applier := indexer.NewApplier(
+		decoder,
+		pricingModel,
+		predictionRepo,
+		historyRepo,
+		settler,
+		court,
+		accountant,
+		indexerLogger,
+	)
+	dependencies.indexer = indexer.New(
+		indexer.Config{
+			ProgramID:     programID,
+			PollInterval:  b.config.PollInterval,
+			MaxTxAttempts: b.config.MaxTxAttempts,
+		},
+		solanaClient,
+		indexerLogger,
+		applier,
+		checkpoint.NewPostgres(db),
+	)
+	leaderboardJob := ranking.NewJob(
+		leaderboard.NewPostgres(db),
+		b.logger.With().Str("sys", "leaderboard").Logger(),
+		b.config.LeaderboardInterval,
+	)
+	finalizer := arbitration.NewFinalizer(
+		court,
+		disputeRepo,
+		predictionRepo,
+		settler,
+		b.logger.With().Str("sys", "finalizer").Logger(),
+		b.config.FinalizeInterval,
+	)
+	oracles, err := b.newOracleRegistry(solanaClient)
+	if err != nil {
+		return nil, fmt.Errorf("prepare oracle registry: %w", err)
+	}
+	oracleScheduler := oracle.NewScheduler(
+		oracle.SchedulerConfig{
+			Interval:     b.config.Oracle.Interval,
+			MaxAttempts:  b.config.Oracle.MaxAttempts,
+			MaxStaleness: b.config.Oracle.MaxStaleness,
+		},
+		oracles,
+		feed.NewPostgres(db),
+		predictionRepo,
+		court,
+		b.logger.With().Str("sys", "oracle").Logger(),
+	)
+	rollup := candle.NewRollup(
+		historyRepo,
+		b.logger.With().Str("sys", "rollup").Logger(),
+		b.config.RollupInterval,
+	)
+	var jobs []scheduler.Job
+	jobs = append(jobs, rollup.Scheduled()...)
+	jobs = append(jobs, leaderboardJob.Scheduled()...)
+	jobs = append(jobs, finalizer.Scheduled()...)
+	jobs = append(jobs, oracleScheduler.Scheduled()...)
+	dependencies.scheduler, err = b.newScheduler(db, jobs)
+	if err != nil {
+		return nil, fmt.Errorf("prepare scheduler: %w", err)
+	}
+	return dependencies, nil
+}
+
+// newScheduler runs the periodic jobs on whichever indexer replica holds the scheduler lock
+func (b *dependencyBuilder) newScheduler(db *pgxpool.Pool, jobs []scheduler.Job) (*scheduler.Scheduler, error) {
+	metrics, err := scheduler.NewMetrics(otel.Meter("scheduler"))
+	if err != nil {
+		return nil, fmt.Errorf("create scheduler metrics: %w", err)
+	}
+	return scheduler.New(
+		scheduler.Config{
+			LockKey:             b.config.SchedulerLockKey,
+			LeaderCheckInterval: b.config.LeaderCheckInterval,
+		},
+		db,
+		jobs,
+		metrics,
+		b.logger.With().Str("sys", "scheduler").Logger(),
+	), nil
+}
+
+func (b *dependencyBuilder) newOracleRegistry(solanaClient *rpc.Client) (*oracle.Registry, error) {
+	pythProgramIDs, err := oracle.ParseProgramIDs(b.config.Oracle.PythProgramIDs)
+	if err != nil {
+		return nil, err
+	}
+	return oracle.NewRegistry(
+		oracle.NewPythAdapter(solanaClient, pythProgramIDs),
+		oracle.NewHTTPAdapter(oracle.NewHTTPClient(b.config.Oracle.HttpTimeout), b.config.Oracle.HttpAllowedHosts),
+	), nil
+}
+
+func (b *dependencyBuilder) newDatabase(ctx context.Context) (*pgxpool.Pool, error) {
+	ctx, span := b.tracer.Start(ctx, "db:connect")
+	defer span.End()
+	return postgres.NewPgxPoolWithOtel(ctx, b.config.Database, b.config.Environment.Value)
+}
+
+type applicationDependencies struct {
+	database     *pgxpool.Pool
+	solanaClient *rpc.Client
+	indexer      *indexer.Indexer
+	scheduler    *scheduler.Scheduler
+}
+
+func (d *applicationDependencies) Close() error {
+	var errs []error
+	if d.solanaClient != nil {
+		if err := d.solanaClient.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close solana client: %w", err))
+		}
+	}
+	if d.database != nil {
+		d.database.Close()
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	log.SetupCallerRootRewrite()
+	app, err := newApplication()
+	if err != nil {
+		panic(err)
+	}
+	defer app.stop()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err = app.start(ctx); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,18 @@
+BEGIN;
+
+DROP TABLE IF EXISTS indexer.checkpoints;
+DROP SCHEMA IF EXISTS indexer;
+
+DROP TABLE IF EXISTS prediction.positions;
+
+DROP INDEX IF EXISTS prediction.markets_market_pubkey_idx;
+ALTER TABLE prediction.markets
+  DROP COLUMN IF EXISTS no_amount,
+  DROP COLUMN IF EXISTS yes_amount;
+UPDATE prediction.markets SET market_pubkey = '' WHERE market_pubkey IS NULL;
+ALTER TABLE prediction.markets
+  ALTER COLUMN market_pubkey SET NOT NULL;
+
+DROP TYPE IF EXISTS prediction.position_side;
+
+COMMIT;
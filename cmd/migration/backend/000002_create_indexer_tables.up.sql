@@ -0,0 +1,42 @@
+BEGIN;
+
+CREATE TYPE prediction.position_side AS ENUM (
+  'YES',
+  'NO'
+  );
+
+ALTER TABLE prediction.markets
+  ALTER COLUMN market_pubkey DROP NOT NULL,
+  ADD COLUMN yes_amount BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN no_amount  BIGINT NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX markets_market_pubkey_idx ON prediction.markets (market_pubkey);
+
+CREATE TABLE prediction.positions
+(
+  id              TEXT                     NOT NULL,
+  market_id       TEXT                     NOT NULL REFERENCES prediction.markets (id),
+  position_pubkey TEXT                     NOT NULL,
+  owner_pubkey    TEXT                     NOT NULL,
+  side            prediction.position_side NOT NULL,
+  amount          BIGINT                   NOT NULL,
+  created_at      pg_catalog.timestamptz   NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX positions_position_pubkey_idx ON prediction.positions (position_pubkey);
+CREATE INDEX positions_market_id_idx ON prediction.positions (market_id);
+CREATE INDEX positions_owner_pubkey_idx ON prediction.positions (owner_pubkey);
+
+CREATE SCHEMA indexer;
+
+CREATE TABLE indexer.checkpoints
+(
+  name       TEXT                   NOT NULL,
+  slot       BIGINT                 NOT NULL,
+  signature  TEXT                   NOT NULL,
+  updated_at pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (name)
+);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS prediction.market_candles;
+DROP TABLE IF EXISTS prediction.market_ticks;
+
+COMMIT;
@@ -0,0 +1,31 @@
+BEGIN;
+
+CREATE TABLE prediction.market_ticks
+(
+  id              BIGSERIAL              NOT NULL,
+  market_id       TEXT                   NOT NULL REFERENCES prediction.markets (id),
+  time            pg_catalog.timestamptz NOT NULL,
+  yes_probability DOUBLE PRECISION       NOT NULL,
+  yes_amount      BIGINT                 NOT NULL,
+  no_amount       BIGINT                 NOT NULL,
+  volume          BIGINT                 NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX market_ticks_market_id_time_idx ON prediction.market_ticks (market_id, time);
+CREATE INDEX market_ticks_time_idx ON prediction.market_ticks (time);
+
+CREATE TABLE prediction.market_candles
+(
+  market_id TEXT                   NOT NULL REFERENCES prediction.markets (id),
+  interval  TEXT                   NOT NULL,
+  bucket    pg_catalog.timestamptz NOT NULL,
+  open      DOUBLE PRECISION       NOT NULL,
+  high      DOUBLE PRECISION       NOT NULL,
+  low       DOUBLE PRECISION       NOT NULL,
+  close     DOUBLE PRECISION       NOT NULL,
+  volume    BIGINT                 NOT NULL,
+  PRIMARY KEY (market_id, interval, bucket)
+);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS prediction.settlement_payouts;
+DROP TABLE IF EXISTS prediction.settlements;
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+CREATE TABLE prediction.settlements
+(
+  market_id    TEXT                         NOT NULL REFERENCES prediction.markets (id),
+  resolution   prediction.market_resolution NOT NULL,
+  total_pool   BIGINT                       NOT NULL,
+  winning_pool BIGINT                       NOT NULL,
+  fee_bps      INTEGER                      NOT NULL,
+  fee_amount   BIGINT                       NOT NULL,
+  created_at   pg_catalog.timestamptz       NOT NULL,
+  PRIMARY KEY (market_id)
+);
+
+CREATE TABLE prediction.settlement_payouts
+(
+  market_id    TEXT   NOT NULL REFERENCES prediction.settlements (market_id),
+  owner_pubkey TEXT   NOT NULL,
+  stake        BIGINT NOT NULL,
+  payout       BIGINT NOT NULL,
+  PRIMARY KEY (market_id, owner_pubkey)
+);
+
+CREATE INDEX settlement_payouts_owner_pubkey_idx ON prediction.settlement_payouts (owner_pubkey);
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+DROP TABLE IF EXISTS fee.ledger;
+DROP TABLE IF EXISTS fee.schedules;
+DROP TYPE IF EXISTS fee.status;
+DROP TYPE IF EXISTS fee.source;
+DROP TYPE IF EXISTS fee.kind;
+DROP SCHEMA IF EXISTS fee;
+
+ALTER TABLE prediction.settlements
+  DROP COLUMN IF EXISTS creator_fee_amount,
+  DROP COLUMN IF EXISTS creator_fee_bps;
+
+ALTER TABLE prediction.markets
+  DROP COLUMN IF EXISTS category;
+
+COMMIT;
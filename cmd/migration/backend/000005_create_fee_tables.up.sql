@@ -0,0 +1,60 @@
+BEGIN;
+
+ALTER TABLE prediction.markets
+  ADD COLUMN category TEXT;
+
+ALTER TABLE prediction.settlements
+  ADD COLUMN creator_fee_bps    INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN creator_fee_amount BIGINT  NOT NULL DEFAULT 0;
+
+CREATE SCHEMA fee;
+
+CREATE TYPE fee.kind AS ENUM (
+  'PROTOCOL',
+  'CREATOR'
+  );
+
+CREATE TYPE fee.source AS ENUM (
+  'POSITION',
+  'SETTLEMENT'
+  );
+
+CREATE TYPE fee.status AS ENUM (
+  'ACCRUED',
+  'CLAIMED'
+  );
+
+CREATE TABLE fee.schedules
+(
+  category                TEXT                   NOT NULL,
+  position_protocol_bps   INTEGER                NOT NULL,
+  position_creator_bps    INTEGER                NOT NULL,
+  settlement_protocol_bps INTEGER                NOT NULL,
+  settlement_creator_bps  INTEGER                NOT NULL,
+  updated_at              pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (category)
+);
+
+INSERT INTO fee.schedules
+VALUES ('default', 0, 0, 200, 0, now());
+
+CREATE TABLE fee.ledger
+(
+  id             BIGSERIAL              NOT NULL,
+  market_id      TEXT                   NOT NULL REFERENCES prediction.markets (id),
+  creator_pubkey TEXT                   NOT NULL,
+  kind           fee.kind               NOT NULL,
+  source         fee.source             NOT NULL,
+  source_id      TEXT                   NOT NULL,
+  amount         BIGINT                 NOT NULL,
+  status         fee.status             NOT NULL,
+  created_at     pg_catalog.timestamptz NOT NULL,
+  claimed_at     pg_catalog.timestamptz,
+  PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX ledger_source_kind_idx ON fee.ledger (source, source_id, kind);
+CREATE INDEX ledger_creator_pubkey_idx ON fee.ledger (creator_pubkey, created_at);
+CREATE INDEX ledger_created_at_idx ON fee.ledger (created_at);
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE prediction.leaderboard_entries
+(
+  kind         TEXT                   NOT NULL,
+  period       TEXT                   NOT NULL,
+  owner_pubkey TEXT                   NOT NULL,
+  value        DOUBLE PRECISION       NOT NULL,
+  markets      BIGINT                 NOT NULL,
+  rank         BIGINT                 NOT NULL,
+  computed_at  pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (kind, period, owner_pubkey)
+);
+
+CREATE INDEX leaderboard_entries_rank_idx ON prediction.leaderboard_entries (kind, period, rank);
+
+COMMIT;
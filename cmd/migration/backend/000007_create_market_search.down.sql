@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS prediction.markets_title_trgm_idx;
+DROP INDEX IF EXISTS prediction.markets_search_vector_idx;
+
+ALTER TABLE prediction.markets
+  DROP COLUMN IF EXISTS search_vector;
+
+COMMIT;
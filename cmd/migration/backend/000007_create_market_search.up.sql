@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE prediction.markets
+  ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX markets_search_vector_idx ON prediction.markets USING gin (search_vector);
+CREATE INDEX markets_title_trgm_idx ON prediction.markets USING gin (title gin_trgm_ops);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP TABLE IF EXISTS prediction.market_tags;
+
+DROP INDEX IF EXISTS prediction.markets_category_idx;
+ALTER TABLE prediction.markets
+  DROP CONSTRAINT IF EXISTS markets_category_fkey;
+
+DROP TABLE IF EXISTS prediction.categories;
+
+COMMIT;
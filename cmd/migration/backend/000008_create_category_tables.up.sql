@@ -0,0 +1,35 @@
+BEGIN;
+
+CREATE TABLE prediction.categories
+(
+  slug        TEXT                   NOT NULL,
+  name        TEXT                   NOT NULL,
+  description TEXT,
+  created_at  pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (slug)
+);
+
+INSERT INTO prediction.categories (slug, name, created_at)
+VALUES ('sports', 'Sports', now()),
+       ('crypto', 'Crypto', now()),
+       ('politics', 'Politics', now()),
+       ('economics', 'Economics', now()),
+       ('entertainment', 'Entertainment', now()),
+       ('science', 'Science', now()),
+       ('other', 'Other', now());
+
+ALTER TABLE prediction.markets
+  ADD CONSTRAINT markets_category_fkey FOREIGN KEY (category) REFERENCES prediction.categories (slug);
+
+CREATE INDEX markets_category_idx ON prediction.markets (category);
+
+CREATE TABLE prediction.market_tags
+(
+  market_id TEXT NOT NULL REFERENCES prediction.markets (id),
+  tag       TEXT NOT NULL,
+  PRIMARY KEY (market_id, tag)
+);
+
+CREATE INDEX market_tags_tag_idx ON prediction.market_tags (tag);
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP TABLE IF EXISTS admin.audit_log;
+DROP SCHEMA IF EXISTS admin;
+
+DROP TABLE IF EXISTS prediction.market_reports;
+
+DROP INDEX IF EXISTS prediction.markets_moderation_status_idx;
+ALTER TABLE prediction.markets
+  DROP COLUMN IF EXISTS moderation_status;
+
+DROP TYPE IF EXISTS prediction.moderation_status;
+
+COMMIT;
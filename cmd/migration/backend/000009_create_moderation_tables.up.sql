@@ -0,0 +1,45 @@
+BEGIN;
+
+CREATE TYPE prediction.moderation_status AS ENUM (
+  'VISIBLE',
+  'HIDDEN',
+  'FLAGGED',
+  'REMOVED'
+  );
+
+ALTER TABLE prediction.markets
+  ADD COLUMN moderation_status prediction.moderation_status NOT NULL DEFAULT 'VISIBLE';
+
+CREATE INDEX markets_moderation_status_idx ON prediction.markets (moderation_status);
+
+CREATE TABLE prediction.market_reports
+(
+  id              TEXT                   NOT NULL,
+  market_id       TEXT                   NOT NULL REFERENCES prediction.markets (id),
+  reporter_pubkey TEXT                   NOT NULL,
+  reason          TEXT                   NOT NULL,
+  details         TEXT,
+  created_at      pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX market_reports_market_id_reporter_pubkey_idx ON prediction.market_reports (market_id, reporter_pubkey);
+
+CREATE SCHEMA admin;
+
+CREATE TABLE admin.audit_log
+(
+  id          BIGSERIAL              NOT NULL,
+  actor       TEXT                   NOT NULL,
+  action      TEXT                   NOT NULL,
+  target_type TEXT                   NOT NULL,
+  target_id   TEXT                   NOT NULL,
+  details     JSONB                  NOT NULL DEFAULT '{}',
+  created_at  pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX audit_log_target_idx ON admin.audit_log (target_type, target_id);
+CREATE INDEX audit_log_created_at_idx ON admin.audit_log (created_at);
+
+COMMIT;
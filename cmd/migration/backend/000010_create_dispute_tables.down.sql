@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS prediction.resolution_challenges;
+DROP TABLE IF EXISTS prediction.resolutions;
+
+DROP TYPE IF EXISTS prediction.resolution_status;
+
+COMMIT;
@@ -0,0 +1,65 @@
+BEGIN;
+
+CREATE TYPE prediction.resolution_status AS ENUM (
+  'PROPOSED',
+  'DISPUTED',
+  'FINAL'
+  );
+
+CREATE TABLE prediction.resolutions
+(
+  market_id        TEXT                         NOT NULL REFERENCES prediction.markets (id),
+  status           prediction.resolution_status NOT NULL,
+  proposed_outcome prediction.market_resolution NOT NULL,
+  final_outcome    prediction.market_resolution NOT NULL DEFAULT 'UNRESOLVED',
+  proposed_by      TEXT                         NOT NULL,
+  proposed_at      pg_catalog.timestamptz       NOT NULL,
+  dispute_deadline pg_catalog.timestamptz       NOT NULL,
+  finalized_by     TEXT,
+  finalized_at     pg_catalog.timestamptz,
+  ruling_note      TEXT,
+  PRIMARY KEY (market_id)
+);
+
+CREATE INDEX resolutions_status_dispute_deadline_idx ON prediction.resolutions (status, dispute_deadline);
+
+CREATE TABLE prediction.resolution_challenges
+(
+  id                TEXT                         NOT NULL,
+  market_id         TEXT                         NOT NULL REFERENCES prediction.resolutions (market_id),
+  challenger_pubkey TEXT                         NOT NULL,
+  outcome           prediction.market_resolution NOT NULL,
+  evidence          TEXT                         NOT NULL,
+  evidence_url      TEXT,
+  created_at        pg_catalog.timestamptz       NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX resolution_challenges_market_id_challenger_pubkey_idx
+  ON prediction.resolution_challenges (market_id, challenger_pubkey);
+
+-- Markets resolved before disputes existed are final as they are
+INSERT INTO prediction.resolutions
+(market_id,
+ status,
+ proposed_outcome,
+ final_outcome,
+ proposed_by,
+ proposed_at,
+ dispute_deadline,
+ finalized_by,
+ finalized_at)
+SELECT id,
+       'FINAL',
+       resolution,
+       resolution,
+       resolver_pubkey,
+       now(),
+       now(),
+       'system',
+       now()
+FROM prediction.markets
+WHERE
+  resolution <> 'UNRESOLVED';
+
+COMMIT;
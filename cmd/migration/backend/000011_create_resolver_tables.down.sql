@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP INDEX IF EXISTS prediction.resolutions_proposed_by_idx;
+
+DROP TABLE IF EXISTS prediction.resolver_assignments;
+DROP TYPE IF EXISTS prediction.assignment_status;
+
+DROP INDEX IF EXISTS prediction.markets_resolver_pubkey_idx;
+ALTER TABLE prediction.markets
+  DROP COLUMN IF EXISTS resolver_status;
+
+DROP TYPE IF EXISTS prediction.resolver_status;
+
+COMMIT;
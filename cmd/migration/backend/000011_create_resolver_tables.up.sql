@@ -0,0 +1,39 @@
+BEGIN;
+
+CREATE TYPE prediction.resolver_status AS ENUM (
+  'PENDING',
+  'ACCEPTED'
+  );
+
+ALTER TABLE prediction.markets
+  ADD COLUMN resolver_status prediction.resolver_status NOT NULL DEFAULT 'ACCEPTED';
+
+CREATE INDEX markets_resolver_pubkey_idx ON prediction.markets (resolver_pubkey);
+
+CREATE TYPE prediction.assignment_status AS ENUM (
+  'PENDING',
+  'ACCEPTED',
+  'DECLINED',
+  'REVOKED',
+  'REPLACED'
+  );
+
+CREATE TABLE prediction.resolver_assignments
+(
+  id              TEXT                         NOT NULL,
+  market_id       TEXT                         NOT NULL REFERENCES prediction.markets (id),
+  resolver_pubkey TEXT                         NOT NULL,
+  nominated_by    TEXT                         NOT NULL,
+  status          prediction.assignment_status NOT NULL,
+  created_at      pg_catalog.timestamptz       NOT NULL,
+  responded_at    pg_catalog.timestamptz,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX resolver_assignments_market_id_idx ON prediction.resolver_assignments (market_id, created_at);
+CREATE UNIQUE INDEX resolver_assignments_market_id_pending_idx
+  ON prediction.resolver_assignments (market_id) WHERE status = 'PENDING';
+
+CREATE INDEX resolutions_proposed_by_idx ON prediction.resolutions (proposed_by);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TABLE IF EXISTS prediction.market_feeds;
+
+DROP TYPE IF EXISTS prediction.feed_status;
+DROP TYPE IF EXISTS prediction.feed_operator;
+DROP TYPE IF EXISTS prediction.feed_kind;
+
+COMMIT;
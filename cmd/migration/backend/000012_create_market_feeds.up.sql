@@ -0,0 +1,42 @@
+BEGIN;
+
+CREATE TYPE prediction.feed_kind AS ENUM (
+  'PYTH',
+  'HTTP'
+  );
+
+CREATE TYPE prediction.feed_operator AS ENUM (
+  'GT',
+  'GTE',
+  'LT',
+  'LTE'
+  );
+
+CREATE TYPE prediction.feed_status AS ENUM (
+  'PENDING',
+  'RESOLVED',
+  'FAILED'
+  );
+
+CREATE TABLE prediction.market_feeds
+(
+  market_id      TEXT                         NOT NULL REFERENCES prediction.markets (id),
+  kind           prediction.feed_kind         NOT NULL,
+  source         TEXT                         NOT NULL,
+  path           TEXT,
+  operator       prediction.feed_operator     NOT NULL,
+  threshold      DOUBLE PRECISION             NOT NULL,
+  evaluate_at    pg_catalog.timestamptz       NOT NULL,
+  status         prediction.feed_status       NOT NULL DEFAULT 'PENDING',
+  attempts       INTEGER                      NOT NULL DEFAULT 0,
+  last_error     TEXT,
+  observed_value DOUBLE PRECISION,
+  observed_at    pg_catalog.timestamptz,
+  outcome        prediction.market_resolution NOT NULL DEFAULT 'UNRESOLVED',
+  resolved_at    pg_catalog.timestamptz,
+  PRIMARY KEY (market_id)
+);
+
+CREATE INDEX market_feeds_status_evaluate_at_idx ON prediction.market_feeds (status, evaluate_at);
+
+COMMIT;
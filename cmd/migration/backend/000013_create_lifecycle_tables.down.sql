@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TABLE IF EXISTS prediction.resolver_reminders;
+
+DROP INDEX IF EXISTS prediction.markets_open_through_open_idx;
+ALTER TABLE prediction.markets
+  DROP COLUMN IF EXISTS closed_at;
+
+COMMIT;
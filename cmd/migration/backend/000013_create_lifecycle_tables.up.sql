@@ -0,0 +1,25 @@
+BEGIN;
+
+ALTER TABLE prediction.markets
+  ADD COLUMN closed_at pg_catalog.timestamptz;
+
+UPDATE prediction.markets
+SET
+  closed_at = open_through
+WHERE
+  open_through <= now();
+
+CREATE INDEX markets_open_through_open_idx ON prediction.markets (open_through) WHERE closed_at IS NULL;
+
+CREATE TABLE prediction.resolver_reminders
+(
+  id              BIGSERIAL              NOT NULL,
+  market_id       TEXT                   NOT NULL REFERENCES prediction.markets (id),
+  resolver_pubkey TEXT                   NOT NULL,
+  reminded_at     pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX resolver_reminders_market_id_reminded_at_idx ON prediction.resolver_reminders (market_id, reminded_at);
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+ALTER TABLE prediction.resolution_challenges
+  DROP COLUMN IF EXISTS outcome_id;
+
+ALTER TABLE prediction.resolutions
+  DROP COLUMN IF EXISTS proposed_outcome_id,
+  DROP COLUMN IF EXISTS final_outcome_id;
+
+ALTER TABLE prediction.settlements
+  DROP COLUMN IF EXISTS winning_outcome_id;
+
+ALTER TABLE prediction.positions
+  DROP COLUMN IF EXISTS outcome_id;
+
+DROP TABLE IF EXISTS prediction.market_outcomes;
+
+ALTER TABLE prediction.markets
+  DROP COLUMN IF EXISTS kind,
+  DROP COLUMN IF EXISTS resolved_outcome;
+
+DROP TYPE IF EXISTS prediction.market_kind;
+
+-- Enum values cannot be dropped, OUTCOME stays in prediction.market_resolution
+
+COMMIT;
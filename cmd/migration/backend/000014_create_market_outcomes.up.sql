@@ -0,0 +1,37 @@
+BEGIN;
+
+CREATE TYPE prediction.market_kind AS ENUM (
+  'BINARY',
+  'CATEGORICAL'
+  );
+
+-- OUTCOME resolutions of categorical markets name the winning outcome in a separate column
+ALTER TYPE prediction.market_resolution ADD VALUE 'OUTCOME';
+
+ALTER TABLE prediction.markets
+  ADD COLUMN kind             prediction.market_kind NOT NULL DEFAULT 'BINARY',
+  ADD COLUMN resolved_outcome SMALLINT;
+
+CREATE TABLE prediction.market_outcomes
+(
+  market_id  TEXT     NOT NULL REFERENCES prediction.markets (id),
+  outcome_id SMALLINT NOT NULL,
+  name       TEXT     NOT NULL,
+  amount     BIGINT   NOT NULL DEFAULT 0,
+  PRIMARY KEY (market_id, outcome_id)
+);
+
+ALTER TABLE prediction.positions
+  ADD COLUMN outcome_id SMALLINT;
+
+ALTER TABLE prediction.settlements
+  ADD COLUMN winning_outcome_id SMALLINT;
+
+ALTER TABLE prediction.resolutions
+  ADD COLUMN proposed_outcome_id SMALLINT,
+  ADD COLUMN final_outcome_id    SMALLINT;
+
+ALTER TABLE prediction.resolution_challenges
+  ADD COLUMN outcome_id SMALLINT;
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+ALTER TABLE prediction.resolution_challenges
+  DROP COLUMN IF EXISTS value;
+
+ALTER TABLE prediction.resolutions
+  DROP COLUMN IF EXISTS proposed_value,
+  DROP COLUMN IF EXISTS final_value;
+
+ALTER TABLE prediction.settlements
+  DROP COLUMN IF EXISTS resolved_value;
+
+ALTER TABLE prediction.markets
+  DROP CONSTRAINT IF EXISTS markets_scalar_range_check,
+  DROP COLUMN IF EXISTS scalar_lower,
+  DROP COLUMN IF EXISTS scalar_upper,
+  DROP COLUMN IF EXISTS resolved_value;
+
+-- Enum values cannot be dropped, SCALAR and VALUE stay in their types
+
+COMMIT;
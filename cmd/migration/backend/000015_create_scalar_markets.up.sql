@@ -0,0 +1,24 @@
+BEGIN;
+
+ALTER TYPE prediction.market_kind ADD VALUE 'SCALAR';
+
+-- VALUE resolutions of scalar markets report the numeric answer in a separate column
+ALTER TYPE prediction.market_resolution ADD VALUE 'VALUE';
+
+ALTER TABLE prediction.markets
+  ADD COLUMN scalar_lower   DOUBLE PRECISION,
+  ADD COLUMN scalar_upper   DOUBLE PRECISION,
+  ADD COLUMN resolved_value DOUBLE PRECISION,
+  ADD CONSTRAINT markets_scalar_range_check CHECK (scalar_lower < scalar_upper);
+
+ALTER TABLE prediction.settlements
+  ADD COLUMN resolved_value DOUBLE PRECISION;
+
+ALTER TABLE prediction.resolutions
+  ADD COLUMN proposed_value DOUBLE PRECISION,
+  ADD COLUMN final_value    DOUBLE PRECISION;
+
+ALTER TABLE prediction.resolution_challenges
+  ADD COLUMN value DOUBLE PRECISION;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS prediction.comment_reports;
+DROP TABLE IF EXISTS prediction.comment_reactions;
+DROP TABLE IF EXISTS prediction.comments;
+DROP TYPE IF EXISTS prediction.comment_status;
+
+COMMIT;
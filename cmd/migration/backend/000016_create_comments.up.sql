@@ -0,0 +1,51 @@
+BEGIN;
+
+CREATE TYPE prediction.comment_status AS ENUM (
+  'VISIBLE',
+  'HIDDEN',
+  'DELETED'
+  );
+
+CREATE TABLE prediction.comments
+(
+  id            TEXT                      NOT NULL,
+  market_id     TEXT                      NOT NULL REFERENCES prediction.markets (id),
+  parent_id     TEXT REFERENCES prediction.comments (id),
+  author_pubkey TEXT                      NOT NULL,
+  body          TEXT                      NOT NULL,
+  status        prediction.comment_status NOT NULL DEFAULT 'VISIBLE',
+  created_at    pg_catalog.timestamptz    NOT NULL,
+  edited_at     pg_catalog.timestamptz,
+  deleted_at    pg_catalog.timestamptz,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX comments_market_id_created_at_idx ON prediction.comments (market_id, created_at DESC, id DESC)
+  WHERE parent_id IS NULL;
+CREATE INDEX comments_parent_id_created_at_idx ON prediction.comments (parent_id, created_at DESC, id DESC)
+  WHERE parent_id IS NOT NULL;
+CREATE INDEX comments_author_pubkey_created_at_idx ON prediction.comments (author_pubkey, created_at);
+
+CREATE TABLE prediction.comment_reactions
+(
+  comment_id     TEXT                   NOT NULL REFERENCES prediction.comments (id),
+  reactor_pubkey TEXT                   NOT NULL,
+  reaction       TEXT                   NOT NULL,
+  created_at     pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (comment_id, reactor_pubkey, reaction)
+);
+
+CREATE TABLE prediction.comment_reports
+(
+  id              TEXT                   NOT NULL,
+  comment_id      TEXT                   NOT NULL REFERENCES prediction.comments (id),
+  reporter_pubkey TEXT                   NOT NULL,
+  reason          TEXT                   NOT NULL,
+  created_at      pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX comment_reports_comment_id_reporter_pubkey_idx
+  ON prediction.comment_reports (comment_id, reporter_pubkey);
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE prediction.profiles
+(
+  pubkey       TEXT                   NOT NULL,
+  display_name TEXT,
+  avatar_url   TEXT,
+  bio          TEXT,
+  socials      JSONB                  NOT NULL DEFAULT '{}',
+  sns_name     TEXT,
+  created_at   pg_catalog.timestamptz NOT NULL,
+  updated_at   pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (pubkey)
+);
+
+CREATE UNIQUE INDEX profiles_display_name_idx ON prediction.profiles (lower(display_name));
+CREATE UNIQUE INDEX profiles_sns_name_idx ON prediction.profiles (sns_name);
+
+COMMIT;
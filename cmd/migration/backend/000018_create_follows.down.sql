@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS prediction.positions_owner_pubkey_created_at_idx;
+DROP INDEX IF EXISTS prediction.markets_creator_pubkey_created_at_idx;
+DROP TABLE IF EXISTS prediction.follows;
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE prediction.follows
+(
+  follower_pubkey TEXT                   NOT NULL,
+  followee_pubkey TEXT                   NOT NULL,
+  created_at      pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (follower_pubkey, followee_pubkey),
+  CHECK (follower_pubkey <> followee_pubkey)
+);
+
+CREATE INDEX follows_followee_pubkey_created_at_idx ON prediction.follows (followee_pubkey, created_at DESC);
+
+-- the feed reads the latest markets and positions of every followed wallet
+CREATE INDEX markets_creator_pubkey_created_at_idx ON prediction.markets (creator_pubkey, created_at DESC, id DESC);
+CREATE INDEX positions_owner_pubkey_created_at_idx ON prediction.positions (owner_pubkey, created_at DESC, id DESC);
+
+COMMIT;
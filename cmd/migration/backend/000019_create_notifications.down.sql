@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS prediction.notification_preferences;
+DROP TABLE IF EXISTS prediction.notifications;
+DROP TYPE IF EXISTS prediction.notification_kind;
+
+COMMIT;
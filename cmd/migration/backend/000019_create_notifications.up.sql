@@ -0,0 +1,40 @@
+BEGIN;
+
+CREATE TYPE prediction.notification_kind AS ENUM (
+  'MARKET_RESOLVED',
+  'POSITION_SETTLED',
+  'COMMENT_REPLY',
+  'DISPUTE_OPENED'
+  );
+
+CREATE TABLE prediction.notifications
+(
+  id               TEXT                         NOT NULL,
+  recipient_pubkey TEXT                         NOT NULL,
+  kind             prediction.notification_kind NOT NULL,
+  subject_id       TEXT                         NOT NULL,
+  market_id        TEXT REFERENCES prediction.markets (id),
+  data             JSONB                        NOT NULL DEFAULT '{}',
+  created_at       pg_catalog.timestamptz       NOT NULL,
+  read_at          pg_catalog.timestamptz,
+  PRIMARY KEY (id)
+);
+
+-- an event notifies each recipient once, whichever instance handles it
+CREATE UNIQUE INDEX notifications_recipient_pubkey_kind_subject_id_idx
+  ON prediction.notifications (recipient_pubkey, kind, subject_id);
+CREATE INDEX notifications_recipient_pubkey_created_at_idx
+  ON prediction.notifications (recipient_pubkey, created_at DESC, id DESC);
+CREATE INDEX notifications_unread_idx ON prediction.notifications (recipient_pubkey)
+  WHERE read_at IS NULL;
+
+CREATE TABLE prediction.notification_preferences
+(
+  pubkey     TEXT                         NOT NULL,
+  kind       prediction.notification_kind NOT NULL,
+  enabled    BOOLEAN                      NOT NULL,
+  updated_at pg_catalog.timestamptz       NOT NULL,
+  PRIMARY KEY (pubkey, kind)
+);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS prediction.comments_created_at_idx;
+DROP INDEX IF EXISTS prediction.positions_created_at_idx;
+DROP TABLE IF EXISTS prediction.market_trending;
+DROP TABLE IF EXISTS prediction.market_views;
+
+COMMIT;
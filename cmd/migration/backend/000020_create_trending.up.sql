@@ -0,0 +1,30 @@
+BEGIN;
+
+-- views are counted per hour so the score can decay them without storing every view
+CREATE TABLE prediction.market_views
+(
+  market_id TEXT                   NOT NULL REFERENCES prediction.markets (id),
+  bucket    pg_catalog.timestamptz NOT NULL,
+  views     BIGINT                 NOT NULL,
+  PRIMARY KEY (market_id, bucket)
+);
+
+CREATE INDEX market_views_bucket_idx ON prediction.market_views (bucket);
+
+CREATE TABLE prediction.market_trending
+(
+  market_id   TEXT                   NOT NULL REFERENCES prediction.markets (id),
+  score       DOUBLE PRECISION       NOT NULL,
+  volume      DOUBLE PRECISION       NOT NULL,
+  bettors     DOUBLE PRECISION       NOT NULL,
+  comments    DOUBLE PRECISION       NOT NULL,
+  views       DOUBLE PRECISION       NOT NULL,
+  computed_at pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (market_id)
+);
+
+CREATE INDEX market_trending_score_idx ON prediction.market_trending (score DESC);
+CREATE INDEX positions_created_at_idx ON prediction.positions (created_at);
+CREATE INDEX comments_created_at_idx ON prediction.comments (created_at);
+
+COMMIT;
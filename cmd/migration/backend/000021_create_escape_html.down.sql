@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS prediction.escape_html(TEXT);
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+-- Search highlights are returned as HTML text, so the source text is escaped
+-- before ts_headline wraps matches in <mark> tags
+CREATE FUNCTION prediction.escape_html(value TEXT) RETURNS TEXT
+  LANGUAGE sql
+  IMMUTABLE
+  STRICT
+  PARALLEL SAFE
+AS
+$$
+SELECT replace(replace(replace(value, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')
+$$;
+
+COMMIT;
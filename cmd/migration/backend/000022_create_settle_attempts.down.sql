@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE prediction.resolutions
+  DROP COLUMN IF EXISTS settle_failed_at,
+  DROP COLUMN IF EXISTS settle_error,
+  DROP COLUMN IF EXISTS settle_attempts;
+
+COMMIT;
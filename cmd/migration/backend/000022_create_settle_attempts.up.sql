@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE prediction.resolutions
+  ADD COLUMN settle_attempts  INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN settle_error     TEXT,
+  ADD COLUMN settle_failed_at pg_catalog.timestamptz;
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE indexer.failed_transactions
+(
+  signature        TEXT                   NOT NULL,
+  slot             BIGINT                 NOT NULL,
+  attempts         INTEGER                NOT NULL,
+  last_error       TEXT                   NOT NULL,
+  first_failed_at  pg_catalog.timestamptz NOT NULL,
+  last_failed_at   pg_catalog.timestamptz NOT NULL,
+  dead_lettered_at pg_catalog.timestamptz,
+  PRIMARY KEY (signature)
+);
+
+CREATE INDEX failed_transactions_dead_lettered_at_idx ON indexer.failed_transactions (dead_lettered_at);
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- viewers of the current hour, a view is counted once per viewer and hour
+CREATE TABLE prediction.market_viewers
+(
+  market_id TEXT                   NOT NULL REFERENCES prediction.markets (id),
+  bucket    pg_catalog.timestamptz NOT NULL,
+  viewer    TEXT                   NOT NULL,
+  PRIMARY KEY (market_id, bucket, viewer)
+);
+
+CREATE INDEX market_viewers_bucket_idx ON prediction.market_viewers (bucket);
+
+COMMIT;
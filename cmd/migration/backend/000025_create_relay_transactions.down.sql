@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS relay.transactions;
+DROP SCHEMA IF EXISTS relay;
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+CREATE SCHEMA relay;
+
+-- transactions relayed by the API, kept so rebroadcasting survives restarts
+CREATE TABLE relay.transactions
+(
+  signature               TEXT                   NOT NULL,
+  raw                     BYTEA                  NOT NULL,
+  last_valid_block_height BIGINT                 NOT NULL,
+  status                  TEXT                   NOT NULL,
+  attempts                INTEGER                NOT NULL,
+  slot                    BIGINT                 NOT NULL DEFAULT 0,
+  err                     TEXT,
+  kind                    TEXT,
+  subject                 TEXT,
+  created_at              pg_catalog.timestamptz NOT NULL,
+  updated_at              pg_catalog.timestamptz NOT NULL,
+  PRIMARY KEY (signature)
+);
+
+CREATE INDEX transactions_status_idx ON relay.transactions (status);
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+DELETE
+FROM prediction.notification_preferences
+WHERE
+  kind = 'RESOLUTION_OVERDUE';
+
+DELETE
+FROM prediction.notifications
+WHERE
+  kind = 'RESOLUTION_OVERDUE';
+
+-- Enum values cannot be dropped, RESOLUTION_OVERDUE stays in prediction.notification_kind
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- RESOLUTION_OVERDUE reminds the resolver of a closed market to propose its resolution
+ALTER TYPE prediction.notification_kind ADD VALUE 'RESOLUTION_OVERDUE';
+
+COMMIT;
@@ -0,0 +1,186 @@
+package arbitration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/notify"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/dispute"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+var (
+	ErrNotProposed     = errors.New("market has no proposed resolution")
+	ErrAlreadyFinal    = errors.New("resolution is already final")
+	ErrWindowClosed    = errors.New("dispute period has ended")
+	ErrNotParticipant  = errors.New("challenger holds no position in the market")
+	ErrInvalidOutcome  = errors.New("outcome is not valid")
+	ErrAlreadyProposed = errors.New("challenge must propose another outcome")
+)
+
+// Court tracks the resolution of markets from the resolver's proposal to the final outcome.
+// Participants may challenge a proposal within the dispute period, challenged proposals
+// wait for an arbiter ruling while unchallenged ones become final when the period ends.
+type Court struct {
+	disputeRepo    dispute.Repository
+	predictionRepo prediction.Repository
+	notifier       *notify.Notifier
+	period         time.Duration
+}
+
+func NewCourt(
+	disputeRepo dispute.Repository,
+	predictionRepo prediction.Repository,
+	notifier *notify.Notifier,
+	period time.Duration,
+) *Court {
+	return &Court{
+		disputeRepo:    disputeRepo,
+		predictionRepo: predictionRepo,
+		notifier:       notifier,
+		period:         period,
+	}
+}
+
+// Propose records the resolution reported for the market. Without a dispute period
+// the proposal is final right away.
+func (c *Court) Propose(ctx context.Context, market prediction.Market) (dispute.Resolution, error) {
+	if !market.ValidResolution(market.Resolution, market.ResolvedOutcome, market.ResolvedValue) {
+		return dispute.Resolution{}, ErrInvalidOutcome
+	}
+	now := time.Now()
+	resolution := dispute.Resolution{
+		MarketID:          market.ID,
+		Status:            dispute.StatusProposed,
+		ProposedOutcome:   market.Resolution,
+		FinalOutcome:      prediction.MarketResolutionUnresolved,
+		ProposedBy:        market.ResolverPubkey,
+		ProposedAt:        now,
+		DisputeDeadline:   now.Add(c.period),
+		ProposedOutcomeID: market.ResolvedOutcome,
+		ProposedValue:     market.ResolvedValue,
+	}
+	if c.period <= 0 {
+		resolution.Status = dispute.StatusFinal
+		resolution.FinalOutcome = market.Resolution
+		resolution.FinalOutcomeID = market.ResolvedOutcome
+		resolution.FinalValue = market.ResolvedValue
+		resolution.FinalizedBy = zeronull.Text(dispute.FinalizerSystem)
+		resolution.FinalizedAt = zeronull.Timestamptz(now)
+	}
+	resolution, err := c.disputeRepo.ProposeResolution(ctx, resolution)
+	if err != nil {
+		return dispute.Resolution{}, fmt.Errorf("propose resolution: %w", err)
+	}
+	if err = c.notifier.MarketResolved(ctx, market, resolution); err != nil {
+		return dispute.Resolution{}, fmt.Errorf("notify resolution: %w", err)
+	}
+	return resolution, nil
+}
+
+// Challenge files the challenge against the proposed resolution and marks it disputed
+func (c *Court) Challenge(ctx context.Context, challenge dispute.Challenge) error {
+	if err := c.checkOutcome(ctx, challenge.MarketID, challenge.Outcome, challenge.OutcomeID, challenge.Value); err != nil {
+		return err
+	}
+	positions, err := c.predictionRepo.GetMarketPositions(ctx, challenge.MarketID)
+	if err != nil {
+		return fmt.Errorf("get positions: %w", err)
+	}
+	if !hasPosition(positions, challenge.ChallengerPubkey) {
+		return ErrNotParticipant
+	}
+	return c.disputeRepo.RunInTx(ctx, func(ctx context.Context) error {
+		resolution, err := c.lockResolution(ctx, challenge.MarketID)
+		if err != nil {
+			return err
+		}
+		if !challenge.CreatedAt.Before(resolution.DisputeDeadline) {
+			return ErrWindowClosed
+		}
+		if challenge.Outcome == resolution.ProposedOutcome && challenge.OutcomeID == resolution.ProposedOutcomeID &&
+			challenge.Value == resolution.ProposedValue {
+			return ErrAlreadyProposed
+		}
+		if _, err = c.disputeRepo.CreateChallenge(ctx, challenge); err != nil {
+			return fmt.Errorf("create challenge: %w", err)
+		}
+		if resolution.Status == dispute.StatusDisputed {
+			return nil
+		}
+		if err = c.disputeRepo.SetStatus(ctx, challenge.MarketID, dispute.StatusDisputed); err != nil {
+			return err
+		}
+		if err = c.notifier.DisputeOpened(ctx, challenge); err != nil {
+			return fmt.Errorf("notify dispute: %w", err)
+		}
+		return nil
+	})
+}
+
+// Rule finalizes the resolution with the arbiter's outcome, which either upholds
+// or overturns the proposal. Run it in a transaction.
+func (c *Court) Rule(ctx context.Context, ruling dispute.Ruling) (dispute.Resolution, error) {
+	if err := c.checkOutcome(ctx, ruling.MarketID, ruling.Outcome, ruling.OutcomeID, ruling.Value); err != nil {
+		return dispute.Resolution{}, err
+	}
+	if _, err := c.lockResolution(ctx, ruling.MarketID); err != nil {
+		return dispute.Resolution{}, err
+	}
+	if err := c.disputeRepo.Finalize(ctx, ruling); err != nil {
+		return dispute.Resolution{}, fmt.Errorf("finalize resolution: %w", err)
+	}
+	return c.disputeRepo.GetResolution(ctx, ruling.MarketID)
+}
+
+// FinalizeExpired makes unchallenged proposals final once their dispute period ended
+func (c *Court) FinalizeExpired(ctx context.Context) ([]string, error) {
+	return c.disputeRepo.FinalizeExpired(ctx, time.Now())
+}
+
+// lockResolution returns the resolution of the market as long as it may still change
+func (c *Court) lockResolution(ctx context.Context, market string) (dispute.Resolution, error) {
+	resolution, err := c.disputeRepo.GetResolutionForUpdate(ctx, market)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dispute.Resolution{}, ErrNotProposed
+	} else if err != nil {
+		return dispute.Resolution{}, fmt.Errorf("get resolution: %w", err)
+	}
+	if resolution.Final() {
+		return dispute.Resolution{}, ErrAlreadyFinal
+	}
+	return resolution, nil
+}
+
+// checkOutcome verifies the market may resolve to the outcome
+func (c *Court) checkOutcome(
+	ctx context.Context,
+	market string,
+	outcome prediction.MarketResolution,
+	outcomeID pgtype.Int2,
+	value pgtype.Float8,
+) error {
+	m, err := c.predictionRepo.GetMarket(ctx, market)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotProposed
+	} else if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	if !m.ValidResolution(outcome, outcomeID, value) {
+		return ErrInvalidOutcome
+	}
+	return nil
+}
+
+func hasPosition(positions []prediction.Position, owner string) bool {
+	for _, position := range positions {
+		if position.OwnerPubkey == owner {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package arbitration
+
+import (
+	"context"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/dispute"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/internal/scheduler"
+	"github.com/IndexStorm/hit-my-bet-back/internal/settlement"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+const settleBatchSize = 100
+
+// Finalizer periodically finalizes expired proposals and settles every market
+// whose resolution became final, including the ones ruled on by an arbiter.
+// A market failing to settle is recorded and retried without holding back the others.
+type Finalizer struct {
+	court          *Court
+	disputeRepo    dispute.Repository
+	predictionRepo prediction.Repository
+	settler        *settlement.Settler
+	logger         zerolog.Logger
+	interval       time.Duration
+}
+
+func NewFinalizer(
+	court *Court,
+	disputeRepo dispute.Repository,
+	predictionRepo prediction.Repository,
+	settler *settlement.Settler,
+	logger zerolog.Logger,
+	interval time.Duration,
+) *Finalizer {
+	return &Finalizer{
+		court:          court,
+		disputeRepo:    disputeRepo,
+		predictionRepo: predictionRepo,
+		settler:        settler,
+		logger:         logger,
+		interval:       interval,
+	}
+}
+
+func (f *Finalizer) Scheduled() []scheduler.Job {
+	return []scheduler.Job{
+		{Name: "finalize-resolutions", Interval: f.interval, Run: f.Finalize},
+	}
+}
+
+func (f *Finalizer) Finalize(ctx context.Context) error {
+	finalized, err := f.court.FinalizeExpired(ctx)
+	if err != nil {
+		return fmt.Errorf("finalize expired: %w", err)
+	}
+	for _, market := range finalized {
+		f.logger.Info().Str("market", market).Msg("finalizer:resolution final")
+	}
+	unsettled, err := f.disputeRepo.ListUnsettled(ctx, settleBatchSize)
+	if err != nil {
+		return fmt.Errorf("list unsettled: %w", err)
+	}
+	for _, id := range unsettled {
+		if err = f.settle(ctx, id); err != nil {
+			f.logger.Warn().Err(err).Str("market", id).Msg("finalizer:settle failed")
+			if err = f.disputeRepo.RecordSettleFailure(ctx, id, err.Error(), time.Now()); err != nil {
+				return fmt.Errorf("record settle failure: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *Finalizer) settle(ctx context.Context, id string) error {
+	market, err := f.predictionRepo.GetMarket(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	_, _, err = f.settler.Settle(ctx, market)
+	return err
+}
@@ -0,0 +1,50 @@
+package candle
+
+import (
+	"context"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/history"
+	"github.com/IndexStorm/hit-my-bet-back/internal/scheduler"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// Rollup periodically aggregates market ticks into candles of every interval. Each run
+// rebuilds the buckets touched since the previous one, the first run rebuilds all of them.
+type Rollup struct {
+	historyRepo history.Repository
+	logger      zerolog.Logger
+	interval    time.Duration
+	lastRun     time.Time
+}
+
+func NewRollup(historyRepo history.Repository, logger zerolog.Logger, interval time.Duration) *Rollup {
+	return &Rollup{
+		historyRepo: historyRepo,
+		logger:      logger,
+		interval:    interval,
+	}
+}
+
+func (r *Rollup) Scheduled() []scheduler.Job {
+	return []scheduler.Job{
+		{Name: "rollup-candles", Interval: r.interval, Run: r.RollupCandles},
+	}
+}
+
+func (r *Rollup) RollupCandles(ctx context.Context) error {
+	now := time.Now()
+	for _, interval := range history.Intervals {
+		var since time.Time
+		if !r.lastRun.IsZero() {
+			since = r.lastRun.Add(-interval.Duration())
+		}
+		candles, err := r.historyRepo.RollupCandles(ctx, interval, since)
+		if err != nil {
+			return fmt.Errorf("rollup %s candles: %w", interval, err)
+		}
+		r.logger.Debug().Str("interval", string(interval)).Int64("candles", candles).Msg("rollup:candles")
+	}
+	r.lastRun = now
+	return nil
+}
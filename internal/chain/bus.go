@@ -0,0 +1,107 @@
+package chain
+
+import (
+	"context"
+	"github.com/gagliardetto/solana-go"
+	"sync"
+)
+
+type EventKind string
+
+const (
+	EventAccount   EventKind = "ACCOUNT"
+	EventSignature EventKind = "SIGNATURE"
+	EventSlot      EventKind = "SLOT"
+	// EventBackfill carries the state of every program account after a reconnect
+	EventBackfill EventKind = "BACKFILL"
+)
+
+type Event struct {
+	Kind EventKind
+	Slot uint64
+
+	// Set for EventAccount
+	Pubkey solana.PublicKey
+	Owner  solana.PublicKey
+	Data   []byte
+
+	// Set for EventSignature
+	Signature solana.Signature
+	Err       interface{}
+
+	// Set for EventBackfill
+	Accounts []AccountState
+}
+
+type AccountState struct {
+	Pubkey solana.PublicKey
+	Owner  solana.PublicKey
+	Data   []byte
+}
+
+// Bus fans chain events out to every subscriber. Publishing never blocks,
+// events are dropped for subscribers that do not keep up. Delivering waits
+// for every subscriber instead, for events that must not be lost.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[int]subscription
+	nextID int
+}
+
+type subscription struct {
+	ch   chan Event
+	done chan struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[int]subscription)}
+}
+
+// Subscribe returns a channel receiving every published event and a function
+// that cancels the subscription
+func (b *Bus) Subscribe(buffer int) (<-chan Event, func()) {
+	sub := subscription{ch: make(chan Event, buffer), done: make(chan struct{})}
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = sub
+	b.mu.Unlock()
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			// Releases a Deliver blocked on this subscriber before taking the lock
+			close(sub.done)
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+}
+
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subs {
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+// Deliver hands the event to every subscriber, waiting for the ones that are behind.
+// Subscribers cancelled meanwhile are skipped.
+func (b *Bus) Deliver(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subs {
+		select {
+		case sub.ch <- event:
+		case <-sub.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
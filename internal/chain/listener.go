@@ -0,0 +1,265 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+	"github.com/rs/zerolog"
+	"sync"
+	"time"
+)
+
+const (
+	maxReconnectDelay = time.Second * 30
+	signatureTimeout  = time.Minute * 2
+)
+
+type ListenerConfig struct {
+	WsURL      string
+	ProgramID  solana.PublicKey
+	StaleAfter time.Duration
+}
+
+// Listener follows program accounts, slots and watched signatures over the Solana
+// websocket API and publishes them to the bus. It reconnects and resubscribes on
+// failures and backfills over HTTP whatever may have been missed in between.
+type Listener struct {
+	config ListenerConfig
+	client *rpc.Client
+	bus    *Bus
+	logger zerolog.Logger
+
+	mu         sync.Mutex
+	signatures map[solana.Signature]time.Time
+	conn       *ws.Client
+}
+
+func NewListener(config ListenerConfig, client *rpc.Client, bus *Bus, logger zerolog.Logger) *Listener {
+	return &Listener{
+		config:     config,
+		client:     client,
+		bus:        bus,
+		logger:     logger,
+		signatures: make(map[solana.Signature]time.Time),
+	}
+}
+
+// WatchSignature subscribes to the confirmation of a signature, the subscription
+// is kept across reconnects until the signature is confirmed or times out
+func (l *Listener) WatchSignature(signature solana.Signature) {
+	l.mu.Lock()
+	l.signatures[signature] = time.Now()
+	conn := l.conn
+	l.mu.Unlock()
+	if conn != nil {
+		go l.followSignature(context.Background(), conn, signature)
+	}
+}
+
+func (l *Listener) Run(ctx context.Context) {
+	delay := time.Second
+	connected := false
+	for ctx.Err() == nil {
+		subscribed, err := l.session(ctx, connected)
+		if ctx.Err() != nil {
+			return
+		}
+		connected = true
+		// Back off from the initial delay again once a session got through
+		if subscribed {
+			delay = time.Second
+		}
+		l.logger.Warn().Err(err).Dur("retry_in", delay).Msg("listener:disconnected")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// session runs a single websocket connection until it fails and reports whether
+// it got subscribed. When resync is set the program state is backfilled over HTTP
+// after subscribing.
+func (l *Listener) session(ctx context.Context, resync bool) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	conn, err := ws.Connect(ctx, l.config.WsURL)
+	if err != nil {
+		return false, fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close()
+	programSub, err := conn.ProgramSubscribeWithOpts(l.config.ProgramID, rpc.CommitmentConfirmed, solana.EncodingBase64, nil)
+	if err != nil {
+		return false, fmt.Errorf("program subscribe: %w", err)
+	}
+	defer programSub.Unsubscribe()
+	slotSub, err := conn.SlotSubscribe()
+	if err != nil {
+		return false, fmt.Errorf("slot subscribe: %w", err)
+	}
+	defer slotSub.Unsubscribe()
+
+	l.mu.Lock()
+	l.conn = conn
+	signatures := make([]solana.Signature, 0, len(l.signatures))
+	for signature := range l.signatures {
+		signatures = append(signatures, signature)
+	}
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.conn = nil
+		l.mu.Unlock()
+	}()
+	for _, signature := range signatures {
+		go l.followSignature(ctx, conn, signature)
+	}
+	l.logger.Info().Bool("resync", resync).Msg("listener:connected")
+	if resync {
+		if err = l.backfill(ctx); err != nil {
+			l.logger.Err(err).Msg("listener:backfill failed")
+		}
+	}
+
+	errs := make(chan error, 2)
+	lastSlot := make(chan uint64, 1)
+	go func() {
+		for {
+			res, err := programSub.Recv(ctx)
+			if err != nil {
+				errs <- fmt.Errorf("program recv: %w", err)
+				return
+			}
+			err = l.bus.Deliver(ctx, Event{
+				Kind:   EventAccount,
+				Slot:   res.Context.Slot,
+				Pubkey: res.Value.Pubkey,
+				Owner:  res.Value.Account.Owner,
+				Data:   res.Value.Account.Data.GetBinary(),
+			})
+			if err != nil {
+				errs <- fmt.Errorf("deliver account: %w", err)
+				return
+			}
+		}
+	}()
+	go func() {
+		for {
+			res, err := slotSub.Recv(ctx)
+			if err != nil {
+				errs <- fmt.Errorf("slot recv: %w", err)
+				return
+			}
+			select {
+			case <-lastSlot:
+			default:
+			}
+			lastSlot <- res.Slot
+			l.bus.Publish(Event{Kind: EventSlot, Slot: res.Slot})
+		}
+	}()
+
+	stale := time.NewTimer(l.config.StaleAfter)
+	defer stale.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case err = <-errs:
+			return true, err
+		case <-lastSlot:
+			stale.Reset(l.config.StaleAfter)
+		case <-stale.C:
+			// No slot for too long means the stream silently stalled
+			return true, fmt.Errorf("no slot notifications for %s", l.config.StaleAfter)
+		}
+	}
+}
+
+func (l *Listener) followSignature(ctx context.Context, conn *ws.Client, signature solana.Signature) {
+	sub, err := conn.SignatureSubscribe(signature, rpc.CommitmentConfirmed)
+	if err != nil {
+		l.logger.Warn().Err(err).Stringer("signature", signature).Msg("listener:signature subscribe failed")
+		return
+	}
+	defer sub.Unsubscribe()
+	ctx, cancel := context.WithTimeout(ctx, signatureTimeout)
+	defer cancel()
+	res, err := sub.Recv(ctx)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			l.forgetSignature(signature)
+		}
+		return
+	}
+	l.forgetSignature(signature)
+	l.bus.Publish(Event{
+		Kind:      EventSignature,
+		Slot:      res.Context.Slot,
+		Signature: signature,
+		Err:       res.Value.Err,
+	})
+}
+
+func (l *Listener) forgetSignature(signature solana.Signature) {
+	l.mu.Lock()
+	delete(l.signatures, signature)
+	l.mu.Unlock()
+}
+
+// backfill delivers the current state of every program account and publishes watched
+// signatures, covering updates missed while the websocket was down
+func (l *Listener) backfill(ctx context.Context) error {
+	accounts, err := l.client.GetProgramAccountsWithOpts(ctx, l.config.ProgramID, &rpc.GetProgramAccountsOpts{
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	if err != nil {
+		return fmt.Errorf("get program accounts: %w", err)
+	}
+	slot, err := l.client.GetSlot(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		return fmt.Errorf("get slot: %w", err)
+	}
+	states := make([]AccountState, len(accounts))
+	for i, account := range accounts {
+		states[i] = AccountState{
+			Pubkey: account.Pubkey,
+			Owner:  account.Account.Owner,
+			Data:   account.Account.Data.GetBinary(),
+		}
+	}
+	// Delivered as one event so that markets are applied before their positions
+	if err = l.bus.Deliver(ctx, Event{Kind: EventBackfill, Slot: slot, Accounts: states}); err != nil {
+		return fmt.Errorf("deliver accounts: %w", err)
+	}
+	l.mu.Lock()
+	signatures := make([]solana.Signature, 0, len(l.signatures))
+	for signature := range l.signatures {
+		signatures = append(signatures, signature)
+	}
+	l.mu.Unlock()
+	if len(signatures) == 0 {
+		return nil
+	}
+	statuses, err := l.client.GetSignatureStatuses(ctx, false, signatures...)
+	if err != nil {
+		return fmt.Errorf("get signature statuses: %w", err)
+	}
+	for i, status := range statuses.Value {
+		if status == nil || status.ConfirmationStatus == rpc.ConfirmationStatusProcessed {
+			continue
+		}
+		l.forgetSignature(signatures[i])
+		l.bus.Publish(Event{
+			Kind:      EventSignature,
+			Slot:      status.Slot,
+			Signature: signatures[i],
+			Err:       status.Err,
+		})
+	}
+	return nil
+}
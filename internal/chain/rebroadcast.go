@@ -0,0 +1,405 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/relay"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"github.com/rs/zerolog"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxProcessingAge is the number of blocks a blockhash stays valid for
+	MaxProcessingAge = 150
+	// maxSignatureStatuses is the number of signatures getSignatureStatuses accepts per call
+	maxSignatureStatuses = 256
+)
+
+type TxStatus string
+
+const (
+	TxStatusPending   TxStatus = "PENDING"
+	TxStatusConfirmed TxStatus = "CONFIRMED"
+	TxStatusFailed    TxStatus = "FAILED"
+	TxStatusExpired   TxStatus = "EXPIRED"
+)
+
+var ErrEmptyTransaction = errors.New("transaction has no signatures")
+
+// TrackedTx is a relayed transaction. Kind names the OutcomeHandler called once it
+// reaches a final status and Subject what the transaction acts on, e.g. a market id.
+type TrackedTx struct {
+	Signature            solana.Signature
+	LastValidBlockHeight uint64
+	Status               TxStatus
+	Attempts             int
+	Slot                 uint64
+	Err                  string
+	Kind                 string
+	Subject              string
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+// OutcomeHandler is called once a tracked transaction reaches a final status
+type OutcomeHandler func(ctx context.Context, tx TrackedTx)
+
+type trackedTx struct {
+	TrackedTx
+	raw []byte
+}
+
+// Rebroadcaster resends relayed transactions until they reach a final status. Tracked
+// transactions are stored, so the ones still pending are picked up again after a restart
+// and outcome handlers are registered by kind rather than passed per transaction.
+type Rebroadcaster struct {
+	client    *rpc.Client
+	relayRepo relay.Repository
+	logger    zerolog.Logger
+	interval  time.Duration
+
+	mu       sync.Mutex
+	txs      map[solana.Signature]*trackedTx
+	handlers map[string]OutcomeHandler
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewRebroadcaster(
+	client *rpc.Client,
+	relayRepo relay.Repository,
+	logger zerolog.Logger,
+	interval time.Duration,
+) *Rebroadcaster {
+	return &Rebroadcaster{
+		client:    client,
+		relayRepo: relayRepo,
+		logger:    logger,
+		interval:  interval,
+		txs:       make(map[solana.Signature]*trackedTx),
+		handlers:  make(map[string]OutcomeHandler),
+		stop:      make(chan struct{}),
+	}
+}
+
+// Handle registers the handler of the outcomes of transactions of the kind,
+// register every kind before Start so restored transactions find their handler
+func (r *Rebroadcaster) Handle(kind string, handler OutcomeHandler) {
+	r.mu.Lock()
+	r.handlers[kind] = handler
+	r.mu.Unlock()
+}
+
+// Start restores the pending transactions stored before a restart and starts rebroadcasting
+func (r *Rebroadcaster) Start(ctx context.Context) error {
+	stored, err := r.relayRepo.ListPending(ctx)
+	if err != nil {
+		return fmt.Errorf("list pending transactions: %w", err)
+	}
+	r.mu.Lock()
+	for _, record := range stored {
+		tx, err := trackedFromRecord(record)
+		if err != nil {
+			r.logger.Warn().Err(err).Str("signature", record.Signature).Msg("rebroadcast:skip stored transaction")
+			continue
+		}
+		r.txs[tx.Signature] = tx
+	}
+	r.mu.Unlock()
+	r.logger.Info().Int("transactions", len(stored)).Msg("rebroadcast:restored")
+	r.wg.Add(1)
+	go r.run()
+	return nil
+}
+
+func (r *Rebroadcaster) Close() error {
+	close(r.stop)
+	r.wg.Wait()
+	return nil
+}
+
+// Submit sends a signed transaction and keeps resending it until it is confirmed,
+// fails or its blockhash expires, then calls the handler registered for kind if any.
+// No blockhash is valid for more than MaxProcessingAge blocks past the current height,
+// so lastValidBlockHeight is capped there and taken as that when zero. The estimate is
+// never earlier than the height the blockhash actually expires at.
+func (r *Rebroadcaster) Submit(
+	ctx context.Context,
+	raw []byte,
+	lastValidBlockHeight uint64,
+	kind string,
+	subject string,
+) (solana.Signature, error) {
+	decoded, err := solana.TransactionFromBytes(raw)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("decode transaction: %w", err)
+	}
+	if len(decoded.Signatures) == 0 {
+		return solana.Signature{}, ErrEmptyTransaction
+	}
+	height, err := r.client.GetBlockHeight(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("get block height: %w", err)
+	}
+	if maxHeight := height + MaxProcessingAge; lastValidBlockHeight == 0 || lastValidBlockHeight > maxHeight {
+		lastValidBlockHeight = maxHeight
+	}
+	now := time.Now()
+	tx := &trackedTx{
+		TrackedTx: TrackedTx{
+			Signature:            decoded.Signatures[0],
+			LastValidBlockHeight: lastValidBlockHeight,
+			Status:               TxStatusPending,
+			Attempts:             1,
+			Kind:                 kind,
+			Subject:              subject,
+			CreatedAt:            now,
+			UpdatedAt:            now,
+		},
+		raw: raw,
+	}
+	// Stored before sending so a restart right after cannot lose it
+	if err = r.relayRepo.SaveTransaction(ctx, tx.record()); err != nil {
+		return solana.Signature{}, fmt.Errorf("save transaction: %w", err)
+	}
+	if _, err = r.send(ctx, raw, false); err != nil {
+		tx.Status, tx.Err = TxStatusFailed, err.Error()
+		if err := r.relayRepo.UpdateTransaction(ctx, tx.record()); err != nil {
+			r.logger.Err(err).Stringer("signature", tx.Signature).Msg("rebroadcast:store failed send")
+		}
+		return solana.Signature{}, fmt.Errorf("send transaction: %w", err)
+	}
+	r.mu.Lock()
+	r.txs[tx.Signature] = tx
+	r.mu.Unlock()
+	return tx.Signature, nil
+}
+
+// Status returns the state of a transaction that is still being rebroadcast
+func (r *Rebroadcaster) Status(signature solana.Signature) (TrackedTx, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tx, ok := r.txs[signature]
+	if !ok {
+		return TrackedTx{}, false
+	}
+	return tx.TrackedTx, true
+}
+
+func (r *Rebroadcaster) send(ctx context.Context, raw []byte, skipPreflight bool) (solana.Signature, error) {
+	var maxRetries uint
+	return r.client.SendRawTransactionWithOpts(ctx, raw, rpc.TransactionOpts{
+		Encoding:            solana.EncodingBase64,
+		SkipPreflight:       skipPreflight,
+		PreflightCommitment: rpc.CommitmentConfirmed,
+		MaxRetries:          &maxRetries,
+	})
+}
+
+func (r *Rebroadcaster) run() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), r.interval*4)
+			if err := r.tick(ctx); err != nil {
+				r.logger.Err(err).Msg("rebroadcast:tick failed")
+			}
+			cancel()
+		}
+	}
+}
+
+func (r *Rebroadcaster) pending() []*trackedTx {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	txs := make([]*trackedTx, 0, len(r.txs))
+	for _, tx := range r.txs {
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+func (r *Rebroadcaster) tick(ctx context.Context) error {
+	txs := r.pending()
+	if len(txs) == 0 {
+		return nil
+	}
+	statuses := make([]*rpc.SignatureStatusesResult, 0, len(txs))
+	for chunk := range slices.Chunk(txs, maxSignatureStatuses) {
+		signatures := make([]solana.Signature, len(chunk))
+		for i, tx := range chunk {
+			signatures[i] = tx.Signature
+		}
+		result, err := r.client.GetSignatureStatuses(ctx, false, signatures...)
+		if err != nil {
+			return fmt.Errorf("get signature statuses: %w", err)
+		}
+		// Keep the positions of the chunk aligned when the node returns fewer statuses
+		values := make([]*rpc.SignatureStatusesResult, len(chunk))
+		copy(values, result.Value)
+		statuses = append(statuses, values...)
+	}
+	height, err := r.client.GetBlockHeight(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		return fmt.Errorf("get block height: %w", err)
+	}
+	for i, tx := range txs {
+		r.process(ctx, tx, statuses[i], height)
+	}
+	return nil
+}
+
+func (r *Rebroadcaster) process(ctx context.Context, tx *trackedTx, status *rpc.SignatureStatusesResult, height uint64) {
+	if status != nil {
+		r.mu.Lock()
+		tx.Slot = status.Slot
+		if status.Err != nil {
+			tx.Err = fmt.Sprintf("%v", status.Err)
+		}
+		r.mu.Unlock()
+		if status.Err != nil {
+			r.finish(ctx, tx, TxStatusFailed)
+			return
+		}
+		if status.ConfirmationStatus == rpc.ConfirmationStatusConfirmed ||
+			status.ConfirmationStatus == rpc.ConfirmationStatusFinalized {
+			r.finish(ctx, tx, TxStatusConfirmed)
+			return
+		}
+	}
+	if height > tx.LastValidBlockHeight {
+		r.finish(ctx, tx, TxStatusExpired)
+		return
+	}
+	if status != nil {
+		// Already processed by the cluster, wait for confirmation instead of resending
+		return
+	}
+	if _, err := r.send(ctx, tx.raw, true); err != nil {
+		r.logger.Warn().Err(err).Stringer("signature", tx.Signature).Msg("rebroadcast:resend failed")
+	}
+	r.mu.Lock()
+	if _, ok := r.txs[tx.Signature]; !ok {
+		// Finished by a signature event meanwhile, storing the attempt would make it pending again
+		r.mu.Unlock()
+		return
+	}
+	tx.Attempts++
+	tx.UpdatedAt = time.Now()
+	record := tx.record()
+	r.mu.Unlock()
+	if err := r.relayRepo.UpdateTransaction(ctx, record); err != nil {
+		r.logger.Warn().Err(err).Stringer("signature", tx.Signature).Msg("rebroadcast:store attempt failed")
+	}
+}
+
+// Consume finishes tracked transactions as soon as their signature events arrive,
+// without waiting for the next status poll
+func (r *Rebroadcaster) Consume(ctx context.Context, events <-chan Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.Kind != EventSignature {
+				continue
+			}
+			r.mu.Lock()
+			tx, found := r.txs[event.Signature]
+			if found {
+				tx.Slot = event.Slot
+				if event.Err != nil {
+					tx.Err = fmt.Sprintf("%v", event.Err)
+				}
+			}
+			r.mu.Unlock()
+			if !found {
+				continue
+			}
+			if event.Err != nil {
+				r.finish(ctx, tx, TxStatusFailed)
+			} else {
+				r.finish(ctx, tx, TxStatusConfirmed)
+			}
+		}
+	}
+}
+
+func (r *Rebroadcaster) finish(ctx context.Context, tx *trackedTx, status TxStatus) {
+	r.mu.Lock()
+	if _, ok := r.txs[tx.Signature]; !ok {
+		// Already finished by a concurrent status poll or event
+		r.mu.Unlock()
+		return
+	}
+	tx.Status = status
+	tx.UpdatedAt = time.Now()
+	delete(r.txs, tx.Signature)
+	handler := r.handlers[tx.Kind]
+	r.mu.Unlock()
+	r.logger.Info().
+		Stringer("signature", tx.Signature).
+		Str("status", string(status)).
+		Int("attempts", tx.Attempts).
+		Msg("rebroadcast:finished")
+	if handler != nil {
+		handler(ctx, tx.TrackedTx)
+	}
+	// Stored last, a restart before this point runs the handler again
+	if err := r.relayRepo.UpdateTransaction(ctx, tx.record()); err != nil {
+		r.logger.Err(err).Stringer("signature", tx.Signature).Msg("rebroadcast:store outcome failed")
+	}
+}
+
+func (tx *trackedTx) record() relay.Transaction {
+	return relay.Transaction{
+		Signature:            tx.Signature.String(),
+		Raw:                  tx.raw,
+		LastValidBlockHeight: tx.LastValidBlockHeight,
+		Status:               string(tx.Status),
+		Attempts:             int32(tx.Attempts),
+		Slot:                 tx.Slot,
+		Err:                  zeronull.Text(tx.Err),
+		Kind:                 zeronull.Text(tx.Kind),
+		Subject:              zeronull.Text(tx.Subject),
+		CreatedAt:            tx.CreatedAt,
+		UpdatedAt:            tx.UpdatedAt,
+	}
+}
+
+func trackedFromRecord(record relay.Transaction) (*trackedTx, error) {
+	signature, err := solana.SignatureFromBase58(record.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("parse signature: %w", err)
+	}
+	return &trackedTx{
+		TrackedTx: TrackedTx{
+			Signature:            signature,
+			LastValidBlockHeight: record.LastValidBlockHeight,
+			Status:               TxStatus(record.Status),
+			Attempts:             int(record.Attempts),
+			Slot:                 record.Slot,
+			Err:                  string(record.Err),
+			Kind:                 string(record.Kind),
+			Subject:              string(record.Subject),
+			CreatedAt:            record.CreatedAt,
+			UpdatedAt:            record.UpdatedAt,
+		},
+		raw: record.Raw,
+	}, nil
+}
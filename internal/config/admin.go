@@ -0,0 +1,10 @@
+package config
+
+type Admin struct {
+	// Pubkeys are wallets allowed to sign admin requests
+	Pubkeys []string `env:"PUBKEYS" envSeparator:","`
+	// ApiKeys are static keys accepted in the X-Admin-Key header
+	ApiKeys []string `env:"API_KEYS,unset" envSeparator:","`
+	// FlagThreshold is the number of user reports that flags a visible market
+	FlagThreshold int64 `env:"FLAG_THRESHOLD" envDefault:"3"`
+}
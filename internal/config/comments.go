@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+type Comments struct {
+	MaxLength int `env:"MAX_LENGTH" envDefault:"2000"`
+	// EditWindow and DeleteWindow bound how long after posting authors may change a comment
+	EditWindow   time.Duration `env:"EDIT_WINDOW" envDefault:"15m"`
+	DeleteWindow time.Duration `env:"DELETE_WINDOW" envDefault:"24h"`
+	// A wallet may post at most RateLimit comments per RateWindow
+	RateLimit  int64         `env:"RATE_LIMIT" envDefault:"5"`
+	RateWindow time.Duration `env:"RATE_WINDOW" envDefault:"1m"`
+	// HideThreshold is the number of user reports that hides a comment
+	HideThreshold int64 `env:"HIDE_THRESHOLD" envDefault:"3"`
+}
@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+type Dispute struct {
+	// Period is how long a proposed resolution can be challenged, zero makes proposals final right away
+	Period time.Duration `env:"PERIOD" envDefault:"48h"`
+	// ArbiterPubkeys are wallets allowed to rule on challenged resolutions
+	ArbiterPubkeys []string `env:"ARBITER_PUBKEYS" envSeparator:","`
+}
@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+type Oracle struct {
+	// HttpAllowedHosts are the hosts HTTP JSON feeds may read from
+	HttpAllowedHosts []string      `env:"HTTP_ALLOWED_HOSTS" envSeparator:","`
+	HttpTimeout      time.Duration `env:"HTTP_TIMEOUT" envDefault:"10s"`
+	// PythProgramIDs are the programs trusted to own Pyth price accounts, the legacy
+	// push oracle and the pull oracle receiver by default
+	PythProgramIDs []string      `env:"PYTH_PROGRAM_IDS" envSeparator:"," envDefault:"FsJ3A3u2vn5cTVofAjvy6y5kwABJAqYWpe4975bi2epH,rec5EKMGg6MxZYaMdyBfgwp4d5rB9T1VQH5pJv5LtFJ"`
+	Interval       time.Duration `env:"INTERVAL" envDefault:"1m"`
+	// MaxAttempts is the number of failed evaluations after which a feed is given up
+	MaxAttempts int32 `env:"MAX_ATTEMPTS" envDefault:"30"`
+	// MaxStaleness is how long after the evaluation time a feed may still be observed,
+	// later feeds fail and their market is left to its resolver
+	MaxStaleness time.Duration `env:"MAX_STALENESS" envDefault:"1h"`
+}
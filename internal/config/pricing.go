@@ -0,0 +1,7 @@
+package config
+
+type Pricing struct {
+	Model string `env:"MODEL" envDefault:"cpmm"`
+	// LmsrLiquidity is the LMSR b parameter in lamports
+	LmsrLiquidity float64 `env:"LMSR_LIQUIDITY" envDefault:"100000000000"`
+}
@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+type Scheduler struct {
+	LockKey             int64         `env:"LOCK_KEY" envDefault:"7305"`
+	LeaderCheckInterval time.Duration `env:"LEADER_CHECK_INTERVAL" envDefault:"10s"`
+
+	CloseInterval time.Duration `env:"CLOSE_INTERVAL" envDefault:"15s"`
+	// ResolutionGrace is how long after closing a resolver has before being reminded,
+	// reminders then repeat every ReminderEvery
+	ResolutionGrace     time.Duration `env:"RESOLUTION_GRACE" envDefault:"24h"`
+	ReminderEvery       time.Duration `env:"REMINDER_EVERY" envDefault:"24h"`
+	ReminderInterval    time.Duration `env:"REMINDER_INTERVAL" envDefault:"10m"`
+	MaintenanceInterval time.Duration `env:"MAINTENANCE_INTERVAL" envDefault:"1h"`
+	// MinuteCandleRetention is how long minute candles are kept, coarser candles are kept forever
+	MinuteCandleRetention time.Duration `env:"MINUTE_CANDLE_RETENTION" envDefault:"720h"`
+	// AssignmentTTL is how long a nominated resolver has to accept an assignment
+	AssignmentTTL time.Duration `env:"ASSIGNMENT_TTL" envDefault:"168h"`
+}
@@ -0,0 +1,16 @@
+package config
+
+import "time"
+
+type Solana struct {
+	ProgramID           string          `env:"PROGRAM_ID"`
+	IdlPath             string          `env:"IDL_PATH"`
+	RpcURL              string          `env:"RPC_URL" envDefault:"https://api.devnet.solana.com"`
+	RpcEndpoints        map[string]uint `env:"RPC_ENDPOINTS" envKeyValSeparator:"|"`
+	RpcHedge            int             `env:"RPC_HEDGE" envDefault:"2"`
+	RpcFailureThreshold int             `env:"RPC_FAILURE_THRESHOLD" envDefault:"5"`
+	RpcCooldown         time.Duration   `env:"RPC_COOLDOWN" envDefault:"30s"`
+	WsURL               string          `env:"WS_URL" envDefault:"wss://api.devnet.solana.com"`
+	WsStaleAfter        time.Duration   `env:"WS_STALE_AFTER" envDefault:"30s"`
+	RebroadcastInterval time.Duration   `env:"REBROADCAST_INTERVAL" envDefault:"2s"`
+}
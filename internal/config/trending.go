@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+type Trending struct {
+	RefreshInterval time.Duration `env:"REFRESH_INTERVAL" envDefault:"5m"`
+	// HalfLife is how long it takes for activity to count half as much in the score,
+	// activity older than Window is ignored
+	HalfLife time.Duration `env:"HALF_LIFE" envDefault:"6h"`
+	Window   time.Duration `env:"WINDOW" envDefault:"72h"`
+	// The score adds the log of the decayed volume and views to the decayed counts of
+	// unique bettors and comments, each multiplied by its weight
+	VolumeWeight  float64 `env:"VOLUME_WEIGHT" envDefault:"1"`
+	BettorWeight  float64 `env:"BETTOR_WEIGHT" envDefault:"2"`
+	CommentWeight float64 `env:"COMMENT_WEIGHT" envDefault:"1"`
+	ViewWeight    float64 `env:"VIEW_WEIGHT" envDefault:"0.5"`
+	// ViewerIPHeader names the header the proxy in front of the API appends the client
+	// address to, its last entry is used. Views are counted once per address and hour
+	ViewerIPHeader string `env:"VIEWER_IP_HEADER"`
+}
@@ -0,0 +1,100 @@
+package fees
+
+import (
+	"context"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/ledger"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"time"
+)
+
+const bpsDenominator = 10_000
+
+// Accountant records protocol and creator fees into the ledger according to the
+// fee schedule of the market category
+type Accountant struct {
+	ledgerRepo ledger.Repository
+}
+
+func NewAccountant(ledgerRepo ledger.Repository) *Accountant {
+	return &Accountant{ledgerRepo: ledgerRepo}
+}
+
+func (a *Accountant) Schedule(ctx context.Context, market prediction.Market) (ledger.Schedule, error) {
+	category := string(market.Category)
+	if category == "" {
+		category = ledger.DefaultCategory
+	}
+	schedule, err := a.ledgerRepo.GetSchedule(ctx, category)
+	if err != nil {
+		return ledger.Schedule{}, fmt.Errorf("get fee schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// AccruePosition records the fees taken from a stake. Settlements pay out the stake net of them.
+func (a *Accountant) AccruePosition(ctx context.Context, market prediction.Market, position prediction.Position) error {
+	schedule, err := a.Schedule(ctx, market)
+	if err != nil {
+		return err
+	}
+	entry := ledger.Entry{
+		MarketID:      market.ID,
+		CreatorPubkey: market.CreatorPubkey,
+		Source:        ledger.SourcePosition,
+		SourceID:      position.PositionPubkey,
+		Status:        ledger.StatusAccrued,
+		CreatedAt:     position.CreatedAt,
+	}
+	return a.accrue(ctx, entry, map[ledger.Kind]int64{
+		ledger.KindProtocol: bps(position.Amount, schedule.PositionProtocolBps),
+		ledger.KindCreator:  bps(position.Amount, schedule.PositionCreatorBps),
+	})
+}
+
+// PositionFees returns the fees accrued on each position of the market, keyed by position pubkey
+func (a *Accountant) PositionFees(ctx context.Context, market prediction.Market) (map[string]int64, error) {
+	positionFees, err := a.ledgerRepo.GetPositionFees(ctx, market.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get position fees: %w", err)
+	}
+	return positionFees, nil
+}
+
+// AccrueSettlement records the fees taken from the losing pool of a settled market
+func (a *Accountant) AccrueSettlement(ctx context.Context, market prediction.Market, settlement prediction.Settlement) error {
+	entry := ledger.Entry{
+		MarketID:      market.ID,
+		CreatorPubkey: market.CreatorPubkey,
+		Source:        ledger.SourceSettlement,
+		SourceID:      market.ID,
+		Status:        ledger.StatusAccrued,
+		CreatedAt:     settlement.CreatedAt,
+	}
+	return a.accrue(ctx, entry, map[ledger.Kind]int64{
+		ledger.KindProtocol: settlement.FeeAmount,
+		ledger.KindCreator:  settlement.CreatorFeeAmount,
+	})
+}
+
+func (a *Accountant) accrue(ctx context.Context, entry ledger.Entry, amounts map[ledger.Kind]int64) error {
+	for kind, amount := range amounts {
+		if amount <= 0 {
+			continue
+		}
+		entry.Kind, entry.Amount = kind, amount
+		if err := a.ledgerRepo.UpsertEntry(ctx, entry); err != nil {
+			return fmt.Errorf("upsert %s fee: %w", kind, err)
+		}
+	}
+	return nil
+}
+
+func bps(amount int64, bps int32) int64 {
+	return amount * int64(bps) / bpsDenominator
+}
+
+// Claim marks the accrued creator fees of the wallet claimed and returns the claimed amount
+func (a *Accountant) Claim(ctx context.Context, creator string) (int64, error) {
+	return a.ledgerRepo.ClaimCreatorFees(ctx, creator, time.Now())
+}
@@ -0,0 +1,327 @@
+package idl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"math"
+	"math/big"
+)
+
+var ErrUnexpectedEOF = errors.New("unexpected end of data")
+
+// Decoded is an account decoded into generic Go values. Structs become
+// map[string]any, tuples and vectors []any, enums EnumValue and 128-bit integers *big.Int.
+type Decoded struct {
+	Name   string
+	Fields map[string]any
+}
+
+type DecodedInstruction struct {
+	Name     string
+	Args     map[string]any
+	Accounts map[string]solana.PublicKey
+}
+
+type EnumValue struct {
+	Variant string
+	Fields  any
+}
+
+func (idl *IDL) DecodeAccount(data []byte) (Decoded, error) {
+	if len(data) < discriminatorSize {
+		return Decoded{}, ErrUnknownDiscriminator
+	}
+	for _, account := range idl.Accounts {
+		if bytes.Equal(data[:discriminatorSize], account.Discriminator[:]) {
+			fields, err := idl.decodeDefined(account.Name, data[discriminatorSize:])
+			if err != nil {
+				return Decoded{}, fmt.Errorf("decode account %s: %w", account.Name, err)
+			}
+			return Decoded{Name: account.Name, Fields: fields}, nil
+		}
+	}
+	return Decoded{}, ErrUnknownDiscriminator
+}
+
+// DecodeAccountInto checks that data holds the named account and decodes it with borsh into v
+func (idl *IDL) DecodeAccountInto(name string, data []byte, v any) error {
+	discriminator, ok := idl.AccountDiscriminator(name)
+	if !ok {
+		return fmt.Errorf("%w: account %s", ErrUnknownType, name)
+	}
+	if len(data) < discriminatorSize || !bytes.Equal(data[:discriminatorSize], discriminator[:]) {
+		return ErrUnknownDiscriminator
+	}
+	return bin.NewBorshDecoder(data[discriminatorSize:]).Decode(v)
+}
+
+func (idl *IDL) DecodeInstruction(data []byte, accounts []solana.PublicKey) (DecodedInstruction, error) {
+	if len(data) < discriminatorSize {
+		return DecodedInstruction{}, ErrUnknownDiscriminator
+	}
+	for _, ix := range idl.Instructions {
+		if !bytes.Equal(data[:discriminatorSize], ix.Discriminator[:]) {
+			continue
+		}
+		r := &reader{data: data[discriminatorSize:]}
+		args, err := idl.decodeFields(r, ix.Args)
+		if err != nil {
+			return DecodedInstruction{}, fmt.Errorf("decode instruction %s: %w", ix.Name, err)
+		}
+		decoded := DecodedInstruction{
+			Name:     ix.Name,
+			Args:     args,
+			Accounts: make(map[string]solana.PublicKey, len(ix.Accounts)),
+		}
+		for i, account := range ix.Accounts {
+			if i < len(accounts) {
+				decoded.Accounts[account.Name] = accounts[i]
+			}
+		}
+		return decoded, nil
+	}
+	return DecodedInstruction{}, ErrUnknownDiscriminator
+}
+
+func (idl *IDL) decodeDefined(name string, data []byte) (map[string]any, error) {
+	def, ok := idl.types[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, name)
+	}
+	value, err := idl.decodeTypeDef(&reader{data: data}, def)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return map[string]any{"value": value}, nil
+	}
+	return fields, nil
+}
+
+func (idl *IDL) decodeTypeDef(r *reader, def *TypeDef) (any, error) {
+	switch def.Type.Kind {
+	case "struct":
+		if isTuple(def.Type.Fields) {
+			return idl.decodeTuple(r, def.Type.Fields)
+		}
+		return idl.decodeFields(r, def.Type.Fields)
+	case "enum":
+		tag, err := r.u8()
+		if err != nil {
+			return nil, err
+		}
+		if int(tag) >= len(def.Type.Variants) {
+			return nil, fmt.Errorf("%w: enum %s variant %d", ErrUnknownType, def.Name, tag)
+		}
+		variant := def.Type.Variants[tag]
+		if len(variant.Fields) == 0 {
+			return EnumValue{Variant: variant.Name}, nil
+		}
+		var fields any
+		if isTuple(variant.Fields) {
+			fields, err = idl.decodeTuple(r, variant.Fields)
+		} else {
+			fields, err = idl.decodeFields(r, variant.Fields)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return EnumValue{Variant: variant.Name, Fields: fields}, nil
+	case "type":
+		if def.Type.Alias == nil {
+			return nil, fmt.Errorf("%w: alias %s without type", ErrUnknownType, def.Name)
+		}
+		return idl.decodeType(r, *def.Type.Alias)
+	default:
+		return nil, fmt.Errorf("%w: kind %s", ErrUnknownType, def.Type.Kind)
+	}
+}
+
+func (idl *IDL) decodeFields(r *reader, fields []Field) (map[string]any, error) {
+	values := make(map[string]any, len(fields))
+	for _, field := range fields {
+		value, err := idl.decodeType(r, field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		values[field.Name] = value
+	}
+	return values, nil
+}
+
+func (idl *IDL) decodeTuple(r *reader, fields []Field) ([]any, error) {
+	values := make([]any, len(fields))
+	for i, field := range fields {
+		value, err := idl.decodeType(r, field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %w", i, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func (idl *IDL) decodeType(r *reader, t Type) (any, error) {
+	switch {
+	case t.Primitive != "":
+		return r.primitive(t.Primitive)
+	case t.Vec != nil:
+		n, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]any, 0, min(int(n), len(r.data)))
+		for range n {
+			value, err := idl.decodeType(r, *t.Vec)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case t.Option != nil:
+		tag, err := r.u8()
+		if err != nil || tag == 0 {
+			return nil, err
+		}
+		return idl.decodeType(r, *t.Option)
+	case t.Array != nil:
+		values := make([]any, t.ArrayLen)
+		for i := range values {
+			value, err := idl.decodeType(r, *t.Array)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	case t.Defined != "":
+		def, ok := idl.types[t.Defined]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownType, t.Defined)
+		}
+		return idl.decodeTypeDef(r, def)
+	default:
+		return nil, ErrUnknownType
+	}
+}
+
+func isTuple(fields []Field) bool {
+	return len(fields) > 0 && fields[0].Name == ""
+}
+
+type reader struct {
+	data []byte
+}
+
+func (r *reader) next(n int) ([]byte, error) {
+	if len(r.data) < n {
+		return nil, ErrUnexpectedEOF
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b, nil
+}
+
+func (r *reader) u8() (uint8, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *reader) u32() (uint32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (r *reader) primitive(name string) (any, error) {
+	switch name {
+	case "bool":
+		v, err := r.u8()
+		return v != 0, err
+	case "u8":
+		return r.u8()
+	case "i8":
+		v, err := r.u8()
+		return int8(v), err
+	case "u16", "i16":
+		b, err := r.next(2)
+		if err != nil {
+			return nil, err
+		}
+		if name == "i16" {
+			return int16(binary.LittleEndian.Uint16(b)), nil
+		}
+		return binary.LittleEndian.Uint16(b), nil
+	case "u32", "i32", "f32":
+		v, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "i32":
+			return int32(v), nil
+		case "f32":
+			return math.Float32frombits(v), nil
+		}
+		return v, nil
+	case "u64", "i64", "f64":
+		b, err := r.next(8)
+		if err != nil {
+			return nil, err
+		}
+		v := binary.LittleEndian.Uint64(b)
+		switch name {
+		case "i64":
+			return int64(v), nil
+		case "f64":
+			return math.Float64frombits(v), nil
+		}
+		return v, nil
+	case "u128", "i128":
+		b, err := r.next(16)
+		if err != nil {
+			return nil, err
+		}
+		be := make([]byte, 16)
+		for i := range b {
+			be[15-i] = b[i]
+		}
+		v := new(big.Int).SetBytes(be)
+		if name == "i128" && b[15]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), 128))
+		}
+		return v, nil
+	case "string", "bytes":
+		n, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		b, err := r.next(int(n))
+		if err != nil {
+			return nil, err
+		}
+		if name == "string" {
+			return string(b), nil
+		}
+		return bytes.Clone(b), nil
+	case "pubkey", "publicKey":
+		b, err := r.next(solana.PublicKeyLength)
+		if err != nil {
+			return nil, err
+		}
+		return solana.PublicKeyFromBytes(b), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, name)
+	}
+}
@@ -0,0 +1,363 @@
+package idl
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"maps"
+	"math"
+	"math/big"
+	"testing"
+)
+
+var (
+	testCreator  = solana.MustPublicKeyFromBase58("9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin")
+	testResolver = solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	testMarket   = solana.MustPublicKeyFromBase58("So11111111111111111111111111111111111111112")
+)
+
+// borsh builds borsh encoded test data
+type borsh []byte
+
+func instructionData(name string) borsh {
+	discriminator := bin.SighashTypeID(bin.SIGHASH_GLOBAL_NAMESPACE, name)
+	return borsh(discriminator[:])
+}
+
+func accountData(name string) borsh {
+	discriminator := bin.SighashTypeID(bin.SIGHASH_ACCOUNT_NAMESPACE, name)
+	return borsh(discriminator[:])
+}
+
+func (b borsh) u8(v uint8) borsh   { return append(b, v) }
+func (b borsh) u16(v uint16) borsh { return binary.LittleEndian.AppendUint16(b, v) }
+func (b borsh) u32(v uint32) borsh { return binary.LittleEndian.AppendUint32(b, v) }
+func (b borsh) u64(v uint64) borsh { return binary.LittleEndian.AppendUint64(b, v) }
+func (b borsh) i64(v int64) borsh  { return b.u64(uint64(v)) }
+
+func (b borsh) str(v string) borsh {
+	return append(b.u32(uint32(len(v))), v...)
+}
+
+func (b borsh) pubkey(v solana.PublicKey) borsh {
+	return append(b, v[:]...)
+}
+
+// i128 appends v in two's complement, which also covers u128 values
+func (b borsh) i128(v *big.Int) borsh {
+	v = new(big.Int).And(v, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1)))
+	le := make([]byte, 16)
+	v.FillBytes(le)
+	for i := range 8 {
+		le[i], le[15-i] = le[15-i], le[i]
+	}
+	return append(b, le...)
+}
+
+func bigInt(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid big int " + s)
+	}
+	return v
+}
+
+// snakeKeys renames legacy camelCase fields so both formats decode to the same values
+func snakeKeys[V any](m map[string]V) map[string]V {
+	renamed := make(map[string]V, len(m))
+	for key, value := range m {
+		renamed[bin.ToSnakeForSighash(key)] = value
+	}
+	return renamed
+}
+
+func TestDecodeInstruction(t *testing.T) {
+	maxU128 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	minI128 := new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+	settle := func(outcome borsh) borsh {
+		return append(instructionData("settle_market"), outcome...).
+			i128(maxU128).
+			i128(big.NewInt(-5)).
+			u8(1).str("refund").
+			u8(7).u8(255).
+			u32(2).u64(10).u64(math.MaxUint64)
+	}
+	settleArgs := func(outcome EnumValue) map[string]any {
+		return map[string]any{
+			"outcome":    outcome,
+			"payout":     maxU128,
+			"adjustment": big.NewInt(-5),
+			"memo":       "refund",
+			"seeds":      []any{uint8(7), uint8(255)},
+			"amounts":    []any{uint64(10), uint64(math.MaxUint64)},
+		}
+	}
+	tests := []struct {
+		name         string
+		data         []byte
+		accounts     []solana.PublicKey
+		wantName     string
+		wantArgs     map[string]any
+		wantAccounts map[string]solana.PublicKey
+		wantErr      error
+	}{
+		{
+			name: "initialize market with fee",
+			data: instructionData("initialize_market").
+				str("m-1").str("Will it rain?").i64(1_700_000_000).u8(1).u16(250),
+			accounts: []solana.PublicKey{testMarket, testCreator, testResolver, solana.SystemProgramID},
+			wantName: "initialize_market",
+			wantArgs: map[string]any{
+				"id":           "m-1",
+				"title":        "Will it rain?",
+				"open_through": int64(1_700_000_000),
+				"fee_bps":      uint16(250),
+			},
+			wantAccounts: map[string]solana.PublicKey{
+				"market":         testMarket,
+				"creator":        testCreator,
+				"resolver":       testResolver,
+				"system_program": solana.SystemProgramID,
+			},
+		},
+		{
+			name:     "initialize market without fee and missing accounts",
+			data:     instructionData("initialize_market").str("").str("t").i64(-1).u8(0),
+			accounts: []solana.PublicKey{testMarket, testCreator},
+			wantName: "initialize_market",
+			wantArgs: map[string]any{
+				"id":           "",
+				"title":        "t",
+				"open_through": int64(-1),
+				"fee_bps":      nil,
+			},
+			wantAccounts: map[string]solana.PublicKey{
+				"market":  testMarket,
+				"creator": testCreator,
+			},
+		},
+		{
+			name:     "place bet in composite accounts",
+			data:     instructionData("place_bet").u8(1).u64(5_000),
+			accounts: []solana.PublicKey{testMarket, testResolver, testCreator, solana.SystemProgramID},
+			wantName: "place_bet",
+			wantArgs: map[string]any{
+				"side":   EnumValue{Variant: "No"},
+				"amount": uint64(5_000),
+			},
+			wantAccounts: map[string]solana.PublicKey{
+				"market":         testMarket,
+				"position":       testResolver,
+				"owner":          testCreator,
+				"system_program": solana.SystemProgramID,
+			},
+		},
+		{
+			name:         "settle with unit variant",
+			data:         settle(borsh{}.u8(0)),
+			wantName:     "settle_market",
+			wantArgs:     settleArgs(EnumValue{Variant: "Tie"}),
+			wantAccounts: map[string]solana.PublicKey{},
+		},
+		{
+			name:         "settle with negative tuple variant",
+			data:         settle(borsh{}.u8(1).i128(minI128)),
+			wantName:     "settle_market",
+			wantArgs:     settleArgs(EnumValue{Variant: "Scalar", Fields: []any{minI128}}),
+			wantAccounts: map[string]solana.PublicKey{},
+		},
+		{
+			name:         "settle with positive tuple variant",
+			data:         settle(borsh{}.u8(1).i128(bigInt("170141183460469231731687303715884105727"))),
+			wantName:     "settle_market",
+			wantArgs:     settleArgs(EnumValue{Variant: "Scalar", Fields: []any{bigInt("170141183460469231731687303715884105727")}}),
+			wantAccounts: map[string]solana.PublicKey{},
+		},
+		{
+			name:     "settle with struct variant",
+			data:     settle(borsh{}.u8(2).u16(6_000).u16(4_000)),
+			wantName: "settle_market",
+			wantArgs: settleArgs(EnumValue{Variant: "Split", Fields: map[string]any{
+				"yes_bps": uint16(6_000),
+				"no_bps":  uint16(4_000),
+			}}),
+			wantAccounts: map[string]solana.PublicKey{},
+		},
+		{
+			name:    "enum variant out of range",
+			data:    settle(borsh{}.u8(3)),
+			wantErr: ErrUnknownType,
+		},
+		{
+			name:    "truncated args",
+			data:    instructionData("place_bet").u8(0).u32(1),
+			wantErr: ErrUnexpectedEOF,
+		},
+		{
+			name:    "string longer than data",
+			data:    instructionData("initialize_market").u32(math.MaxUint32),
+			wantErr: ErrUnexpectedEOF,
+		},
+		{
+			name:    "unknown instruction",
+			data:    instructionData("close_market"),
+			wantErr: ErrUnknownDiscriminator,
+		},
+		{
+			name:    "shorter than discriminator",
+			data:    []byte{1, 2, 3},
+			wantErr: ErrUnknownDiscriminator,
+		},
+	}
+	for _, path := range []string{testIDLPath, testLegacyIDLPath} {
+		programIDL := loadTestIDL(t, path)
+		for _, tt := range tests {
+			t.Run(path+"/"+tt.name, func(t *testing.T) {
+				got, err := programIDL.DecodeInstruction(tt.data, tt.accounts)
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("got error %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if name := bin.ToSnakeForSighash(got.Name); name != tt.wantName {
+					t.Errorf("name = %s, want %s", name, tt.wantName)
+				}
+				assertDecoded(t, "args", snakeKeys(got.Args), tt.wantArgs)
+				if accounts := snakeKeys(got.Accounts); !maps.Equal(accounts, tt.wantAccounts) {
+					t.Errorf("accounts = %v, want %v", accounts, tt.wantAccounts)
+				}
+			})
+		}
+	}
+}
+
+// testMarketAccount mirrors the Market account of the test IDLs
+type testMarketAccount struct {
+	ID          string
+	Creator     solana.PublicKey
+	Resolver    solana.PublicKey
+	Title       string
+	OpenThrough int64
+	Resolution  uint8
+	YesAmount   uint64
+	NoAmount    uint64
+}
+
+func TestDecodeAccount(t *testing.T) {
+	market := accountData("Market").
+		str("m-1").pubkey(testCreator).pubkey(testResolver).str("Will it rain?").
+		i64(1_700_000_000).u8(3).u64(100).u64(0)
+	position := accountData("Position").
+		pubkey(testMarket).pubkey(testCreator).u8(0).u64(42).i64(1_700_000_001)
+	tests := []struct {
+		name       string
+		data       []byte
+		wantName   string
+		wantFields map[string]any
+		wantErr    error
+	}{
+		{
+			name:     "market",
+			data:     market,
+			wantName: "Market",
+			wantFields: map[string]any{
+				"id":           "m-1",
+				"creator":      testCreator,
+				"resolver":     testResolver,
+				"title":        "Will it rain?",
+				"open_through": int64(1_700_000_000),
+				"resolution":   EnumValue{Variant: "Tie"},
+				"yes_amount":   uint64(100),
+				"no_amount":    uint64(0),
+			},
+		},
+		{
+			name:     "position",
+			data:     position,
+			wantName: "Position",
+			wantFields: map[string]any{
+				"market":     testMarket,
+				"owner":      testCreator,
+				"side":       EnumValue{Variant: "Yes"},
+				"amount":     uint64(42),
+				"created_at": int64(1_700_000_001),
+			},
+		},
+		{
+			name:    "truncated",
+			data:    position[:len(position)-1],
+			wantErr: ErrUnexpectedEOF,
+		},
+		{
+			name:    "unknown account",
+			data:    accountData("Vault").u64(1),
+			wantErr: ErrUnknownDiscriminator,
+		},
+	}
+	for _, path := range []string{testIDLPath, testLegacyIDLPath} {
+		programIDL := loadTestIDL(t, path)
+		for _, tt := range tests {
+			t.Run(path+"/"+tt.name, func(t *testing.T) {
+				got, err := programIDL.DecodeAccount(tt.data)
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("got error %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got.Name != tt.wantName {
+					t.Errorf("name = %s, want %s", got.Name, tt.wantName)
+				}
+				assertDecoded(t, "fields", snakeKeys(got.Fields), tt.wantFields)
+			})
+		}
+		t.Run(path+"/into", func(t *testing.T) {
+			var got testMarketAccount
+			if err := programIDL.DecodeAccountInto("Market", market, &got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := testMarketAccount{
+				ID:          "m-1",
+				Creator:     testCreator,
+				Resolver:    testResolver,
+				Title:       "Will it rain?",
+				OpenThrough: 1_700_000_000,
+				Resolution:  3,
+				YesAmount:   100,
+			}
+			if got != want {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+			if err := programIDL.DecodeAccountInto("Market", position, &got); !errors.Is(err, ErrUnknownDiscriminator) {
+				t.Errorf("decode position as market: got error %v, want %v", err, ErrUnknownDiscriminator)
+			}
+			if err := programIDL.DecodeAccountInto("Vault", market, &got); !errors.Is(err, ErrUnknownType) {
+				t.Errorf("decode unknown account: got error %v, want %v", err, ErrUnknownType)
+			}
+		})
+	}
+}
+
+// assertDecoded compares decoded values by their printed form, which also
+// compares *big.Int values and public keys by value
+func assertDecoded(t *testing.T, what string, got, want map[string]any) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", what, got, want)
+		return
+	}
+	for key, value := range want {
+		if fmt.Sprintf("%T %v", got[key], got[key]) != fmt.Sprintf("%T %v", value, value) {
+			t.Errorf("%s[%s] = %T %v, want %T %v", what, key, got[key], got[key], value, value)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package idl
+
+import (
+	"errors"
+	"fmt"
+	bin "github.com/gagliardetto/binary"
+	"github.com/goccy/go-json"
+	"os"
+	"strings"
+)
+
+const discriminatorSize = 8
+
+var (
+	ErrUnknownDiscriminator = errors.New("unknown discriminator")
+	ErrUnknownType          = errors.New("unknown type")
+)
+
+type Discriminator [discriminatorSize]byte
+
+// IDL is an Anchor program interface description. Both the current format and the
+// legacy one without explicit discriminators are supported.
+type IDL struct {
+	Address      string        `json:"address"`
+	Metadata     Metadata      `json:"metadata"`
+	Instructions []Instruction `json:"instructions"`
+	Accounts     []AccountDef  `json:"accounts"`
+	Types        []TypeDef     `json:"types"`
+
+	types map[string]*TypeDef
+}
+
+type Metadata struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Spec    string `json:"spec"`
+}
+
+type Instruction struct {
+	Name          string            `json:"name"`
+	Discriminator Discriminator     `json:"discriminator"`
+	Accounts      []InstructionAcct `json:"accounts"`
+	Args          []Field           `json:"args"`
+}
+
+type InstructionAcct struct {
+	Name     string `json:"name"`
+	Writable bool   `json:"writable"`
+	Signer   bool   `json:"signer"`
+	Optional bool   `json:"optional"`
+	// Accounts is set for composite account groups
+	Accounts []InstructionAcct `json:"accounts"`
+}
+
+type AccountDef struct {
+	Name          string        `json:"name"`
+	Discriminator Discriminator `json:"discriminator"`
+	// Type is only set by the legacy format, otherwise the layout is in IDL.Types
+	Type *TypeDefTy `json:"type"`
+}
+
+type TypeDef struct {
+	Name string    `json:"name"`
+	Type TypeDefTy `json:"type"`
+}
+
+type TypeDefTy struct {
+	Kind     string    `json:"kind"`
+	Fields   []Field   `json:"fields"`
+	Variants []Variant `json:"variants"`
+	Alias    *Type     `json:"alias"`
+}
+
+type Variant struct {
+	Name   string  `json:"name"`
+	Fields []Field `json:"fields"`
+}
+
+// Field is a named field or, for tuple structs and variants, a bare type
+type Field struct {
+	Name string
+	Type Type
+}
+
+func (f *Field) UnmarshalJSON(data []byte) error {
+	var named struct {
+		Name string `json:"name"`
+		Type *Type  `json:"type"`
+	}
+	if err := json.Unmarshal(data, &named); err == nil && named.Type != nil {
+		f.Name, f.Type = named.Name, *named.Type
+		return nil
+	}
+	return json.Unmarshal(data, &f.Type)
+}
+
+// Type is a primitive name or one of the vec, option, array or defined compounds
+type Type struct {
+	Primitive string
+	Vec       *Type
+	Option    *Type
+	Array     *Type
+	ArrayLen  int
+	Defined   string
+}
+
+func (t *Type) UnmarshalJSON(data []byte) error {
+	var primitive string
+	if err := json.Unmarshal(data, &primitive); err == nil {
+		t.Primitive = primitive
+		return nil
+	}
+	var compound struct {
+		Vec     *Type             `json:"vec"`
+		Option  *Type             `json:"option"`
+		COption *Type             `json:"coption"`
+		Array   []json.RawMessage `json:"array"`
+		Defined json.RawMessage   `json:"defined"`
+	}
+	if err := json.Unmarshal(data, &compound); err != nil {
+		return fmt.Errorf("unmarshal type: %w", err)
+	}
+	switch {
+	case compound.Vec != nil:
+		t.Vec = compound.Vec
+	case compound.Option != nil:
+		t.Option = compound.Option
+	case compound.COption != nil:
+		t.Option = compound.COption
+	case len(compound.Array) == 2:
+		t.Array = new(Type)
+		if err := json.Unmarshal(compound.Array[0], t.Array); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(compound.Array[1], &t.ArrayLen); err != nil {
+			return fmt.Errorf("unmarshal array length: %w", err)
+		}
+	case compound.Defined != nil:
+		var name string
+		if err := json.Unmarshal(compound.Defined, &name); err == nil {
+			t.Defined = name
+			break
+		}
+		var defined struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(compound.Defined, &defined); err != nil {
+			return fmt.Errorf("unmarshal defined type: %w", err)
+		}
+		t.Defined = defined.Name
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownType, string(data))
+	}
+	return nil
+}
+
+func Load(path string) (*IDL, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read idl: %w", err)
+	}
+	return Parse(data)
+}
+
+func Parse(data []byte) (*IDL, error) {
+	var idl IDL
+	if err := json.Unmarshal(data, &idl); err != nil {
+		return nil, fmt.Errorf("unmarshal idl: %w", err)
+	}
+	idl.types = make(map[string]*TypeDef, len(idl.Types)+len(idl.Accounts))
+	for i := range idl.Types {
+		idl.types[idl.Types[i].Name] = &idl.Types[i]
+	}
+	// Legacy IDLs have no discriminators and keep account layouts inline
+	for i := range idl.Instructions {
+		ix := &idl.Instructions[i]
+		if ix.Discriminator == (Discriminator{}) {
+			ix.Discriminator = Discriminator(bin.SighashTypeID(bin.SIGHASH_GLOBAL_NAMESPACE, bin.ToSnakeForSighash(ix.Name)))
+		}
+		ix.Accounts = flattenAccounts(ix.Accounts)
+	}
+	for i := range idl.Accounts {
+		account := &idl.Accounts[i]
+		if account.Discriminator == (Discriminator{}) {
+			account.Discriminator = Discriminator(bin.SighashTypeID(bin.SIGHASH_ACCOUNT_NAMESPACE, account.Name))
+		}
+		if account.Type != nil {
+			if _, ok := idl.types[account.Name]; !ok {
+				idl.types[account.Name] = &TypeDef{Name: account.Name, Type: *account.Type}
+			}
+		}
+	}
+	return &idl, nil
+}
+
+func flattenAccounts(accounts []InstructionAcct) []InstructionAcct {
+	flat := make([]InstructionAcct, 0, len(accounts))
+	for _, account := range accounts {
+		if len(account.Accounts) > 0 {
+			flat = append(flat, flattenAccounts(account.Accounts)...)
+			continue
+		}
+		flat = append(flat, account)
+	}
+	return flat
+}
+
+// AccountDiscriminator returns the discriminator of the named account
+func (idl *IDL) AccountDiscriminator(name string) (Discriminator, bool) {
+	for _, account := range idl.Accounts {
+		if strings.EqualFold(account.Name, name) {
+			return account.Discriminator, true
+		}
+	}
+	return Discriminator{}, false
+}
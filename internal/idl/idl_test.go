@@ -0,0 +1,79 @@
+package idl
+
+import (
+	bin "github.com/gagliardetto/binary"
+	"slices"
+	"testing"
+)
+
+const (
+	testIDLPath       = "testdata/hit_my_bet.json"
+	testLegacyIDLPath = "testdata/hit_my_bet_legacy.json"
+)
+
+func loadTestIDL(t *testing.T, path string) *IDL {
+	t.Helper()
+	programIDL, err := Load(path)
+	if err != nil {
+		t.Fatalf("load %s: %v", path, err)
+	}
+	return programIDL
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		accounts map[string][]string
+	}{
+		{
+			name: "current format",
+			path: testIDLPath,
+			accounts: map[string][]string{
+				"initialize_market": {"market", "creator", "resolver", "system_program"},
+				"place_bet":         {"market", "position", "owner", "system_program"},
+				"settle_market":     {"market", "resolver"},
+			},
+		},
+		{
+			name: "legacy format",
+			path: testLegacyIDLPath,
+			accounts: map[string][]string{
+				"initializeMarket": {"market", "creator", "resolver", "systemProgram"},
+				"placeBet":         {"market", "position", "owner", "systemProgram"},
+				"settleMarket":     {"market", "resolver"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			programIDL := loadTestIDL(t, tt.path)
+			if len(programIDL.Instructions) != len(tt.accounts) {
+				t.Fatalf("got %d instructions, want %d", len(programIDL.Instructions), len(tt.accounts))
+			}
+			for _, ix := range programIDL.Instructions {
+				want := Discriminator(bin.SighashTypeID(bin.SIGHASH_GLOBAL_NAMESPACE, bin.ToSnakeForSighash(ix.Name)))
+				if ix.Discriminator != want {
+					t.Errorf("instruction %s: discriminator %v, want %v", ix.Name, ix.Discriminator, want)
+				}
+				var names []string
+				for _, account := range ix.Accounts {
+					names = append(names, account.Name)
+				}
+				if !slices.Equal(names, tt.accounts[ix.Name]) {
+					t.Errorf("instruction %s: accounts %v, want %v", ix.Name, names, tt.accounts[ix.Name])
+				}
+			}
+			for _, name := range []string{"Market", "Position"} {
+				discriminator, ok := programIDL.AccountDiscriminator(name)
+				want := Discriminator(bin.SighashTypeID(bin.SIGHASH_ACCOUNT_NAMESPACE, name))
+				if !ok || discriminator != want {
+					t.Errorf("account %s: discriminator %v, %t, want %v", name, discriminator, ok, want)
+				}
+			}
+			if _, ok := programIDL.AccountDiscriminator("Vault"); ok {
+				t.Error("unknown account has a discriminator")
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/arbitration"
+	"github.com/IndexStorm/hit-my-bet-back/internal/chain"
+	"github.com/IndexStorm/hit-my-bet-back/internal/fees"
+	"github.com/IndexStorm/hit-my-bet-back/internal/pricing"
+	"github.com/IndexStorm/hit-my-bet-back/internal/program"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/history"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/internal/settlement"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/nanoid"
+	"github.com/gagliardetto/solana-go"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+var ErrMarketNotIndexed = errors.New("position market is not indexed")
+
+type Account struct {
+	Pubkey solana.PublicKey
+	Data   []byte
+}
+
+// Applier decodes program accounts and writes them to the prediction repository
+// and records a price history tick whenever market pools change
+type Applier struct {
+	decoder        *program.Decoder
+	pricingModel   pricing.Model
+	predictionRepo prediction.Repository
+	historyRepo    history.Repository
+	settler        *settlement.Settler
+	court          *arbitration.Court
+	accountant     *fees.Accountant
+	logger         zerolog.Logger
+}
+
+func NewApplier(
+	decoder *program.Decoder,
+	pricingModel pricing.Model,
+	predictionRepo prediction.Repository,
+	historyRepo history.Repository,
+	settler *settlement.Settler,
+	court *arbitration.Court,
+	accountant *fees.Accountant,
+	logger zerolog.Logger,
+) *Applier {
+	return &Applier{
+		decoder:        decoder,
+		pricingModel:   pricingModel,
+		predictionRepo: predictionRepo,
+		historyRepo:    historyRepo,
+		settler:        settler,
+		court:          court,
+		accountant:     accountant,
+		logger:         logger,
+	}
+}
+
+// Consume applies account and backfill events from the chain listener until the channel is closed
+func (a *Applier) Consume(ctx context.Context, events <-chan chain.Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			switch event.Kind {
+			case chain.EventAccount:
+				account := Account{Pubkey: event.Pubkey, Data: event.Data}
+				if err := a.ApplyAccounts(ctx, []Account{account}); err != nil {
+					a.logger.Err(err).Stringer("account", event.Pubkey).Msg("indexer:apply event failed")
+				}
+			case chain.EventBackfill:
+				accounts := make([]Account, len(event.Accounts))
+				for i, state := range event.Accounts {
+					accounts[i] = Account{Pubkey: state.Pubkey, Data: state.Data}
+				}
+				if err := a.ApplyAccounts(ctx, accounts); err != nil {
+					a.logger.Err(err).Int("accounts", len(accounts)).Msg("indexer:apply backfill failed")
+				}
+			}
+		}
+	}
+}
+
+// ApplyAccounts stores decoded program accounts, markets first so that positions can reference them.
+// Resolutions are proposed once all positions of the market are stored
+// and settled right away when no dispute period applies.
+func (a *Applier) ApplyAccounts(ctx context.Context, accounts []Account) error {
+	var markets, positions []keyedAccount
+	for _, account := range accounts {
+		decoded, err := a.decoder.DecodeAccount(account.Data)
+		if errors.Is(err, program.ErrUnknownAccount) {
+			continue
+		} else if err != nil {
+			a.logger.Warn().Err(err).Stringer("account", account.Pubkey).Msg("indexer:skip undecodable account")
+			continue
+		}
+		switch decoded.(type) {
+		case *program.MarketAccount:
+			markets = append(markets, keyedAccount{pubkey: account.Pubkey, account: decoded})
+		case *program.PositionAccount:
+			positions = append(positions, keyedAccount{pubkey: account.Pubkey, account: decoded})
+		}
+	}
+	applied := markets[:0]
+	skipped := make(map[solana.PublicKey]struct{})
+	for _, market := range markets {
+		ok, err := a.applyMarket(ctx, market.pubkey, market.account.(*program.MarketAccount))
+		if err != nil {
+			return fmt.Errorf("apply market %s: %w", market.pubkey, err)
+		}
+		if ok {
+			applied = append(applied, market)
+		} else {
+			skipped[market.pubkey] = struct{}{}
+		}
+	}
+	for _, position := range positions {
+		if _, ok := skipped[position.account.(*program.PositionAccount).Market]; ok {
+			continue
+		}
+		if err := a.applyPosition(ctx, position.pubkey, position.account.(*program.PositionAccount)); err != nil {
+			return fmt.Errorf("apply position %s: %w", position.pubkey, err)
+		}
+	}
+	for _, market := range applied {
+		if market.account.(*program.MarketAccount).Resolution == program.ResolutionUnresolved {
+			continue
+		}
+		if err := a.resolve(ctx, market.pubkey); err != nil {
+			return fmt.Errorf("resolve market %s: %w", market.pubkey, err)
+		}
+	}
+	return nil
+}
+
+func (a *Applier) resolve(ctx context.Context, pubkey solana.PublicKey) error {
+	market, err := a.predictionRepo.GetMarketByPubkey(ctx, pubkey.String())
+	if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	// Resolvers report the outcome of categorical and scalar markets through the API
+	if !market.Binary() {
+		return nil
+	}
+	resolution, err := a.court.Propose(ctx, market)
+	if err != nil || !resolution.Final() {
+		return err
+	}
+	_, _, err = a.settler.Settle(ctx, market)
+	return err
+}
+
+type keyedAccount struct {
+	pubkey  solana.PublicKey
+	account any
+}
+
+// applyMarket stores the market account and reports whether it was applied
+func (a *Applier) applyMarket(ctx context.Context, pubkey solana.PublicKey, account *program.MarketAccount) (bool, error) {
+	id := account.ID
+	if id == "" {
+		id = pubkey.String()
+	}
+	err := a.predictionRepo.UpsertChainMarket(ctx, prediction.Market{
+		ID:             id,
+		ChainStatus:    prediction.MarketChainStatusConfirmed,
+		Title:          account.Title,
+		CreatorPubkey:  account.Creator.String(),
+		ResolverPubkey: account.Resolver.String(),
+		MarketPubkey:   zeronull.Text(pubkey.String()),
+		Resolution:     marketResolution(account.Resolution),
+		CreatedAt:      time.Now(),
+		OpenThrough:    time.Unix(account.OpenThrough, 0),
+		YesAmount:      int64(account.YesAmount),
+		NoAmount:       int64(account.NoAmount),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		a.logger.Warn().Stringer("account", pubkey).Str("market", id).Stringer("creator", account.Creator).
+			Msg("indexer:skip market account not matching the stored market")
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	market, err := a.predictionRepo.GetMarket(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("get market: %w", err)
+	}
+	// Outcome amounts of categorical markets are tracked from their positions
+	if market.Kind == prediction.MarketKindCategorical {
+		return true, nil
+	}
+	return true, a.recordTick(ctx, id, int64(account.YesAmount), int64(account.NoAmount))
+}
+
+func (a *Applier) applyPosition(ctx context.Context, pubkey solana.PublicKey, account *program.PositionAccount) error {
+	market, err := a.predictionRepo.GetMarketByPubkey(ctx, account.Market.String())
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrMarketNotIndexed
+	} else if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	side := prediction.PositionSideYes
+	if account.Side == program.SideNo {
+		side = prediction.PositionSideNo
+	}
+	position := prediction.Position{
+		ID:             nanoid.RandomID(),
+		MarketID:       market.ID,
+		PositionPubkey: pubkey.String(),
+		OwnerPubkey:    account.Owner.String(),
+		Side:           side,
+		Amount:         int64(account.Amount),
+		CreatedAt:      time.Unix(account.CreatedAt, 0),
+	}
+	// Positions of categorical markets carry the outcome id in the side byte
+	categorical := market.Kind == prediction.MarketKindCategorical
+	if categorical {
+		if int(account.Side) >= len(market.Outcomes) {
+			a.logger.Warn().Stringer("position", pubkey).Uint8("outcome", uint8(account.Side)).
+				Msg("indexer:skip position on unknown outcome")
+			return nil
+		}
+		position.Side = prediction.PositionSideYes
+		position.OutcomeID = pgtype.Int2{Int16: int16(account.Side), Valid: true}
+	}
+	if err = a.predictionRepo.UpsertPosition(ctx, position); err != nil {
+		return err
+	}
+	if err = a.accountant.AccruePosition(ctx, market, position); err != nil {
+		return fmt.Errorf("accrue fees: %w", err)
+	}
+	if categorical {
+		if err = a.predictionRepo.RefreshOutcomeAmounts(ctx, market.ID); err != nil {
+			return fmt.Errorf("refresh outcome amounts: %w", err)
+		}
+		return nil
+	}
+	return a.recordTick(ctx, market.ID, market.YesAmount, market.NoAmount)
+}
+
+func (a *Applier) recordTick(ctx context.Context, market string, yesAmount, noAmount int64) error {
+	probability, err := a.pricingModel.Price(pricing.Pool{Yes: float64(yesAmount), No: float64(noAmount)}, pricing.SideYes)
+	if err != nil {
+		return fmt.Errorf("price market: %w", err)
+	}
+	err = a.historyRepo.RecordTick(ctx, history.Tick{
+		MarketID:       market,
+		Time:           time.Now(),
+		YesProbability: probability,
+		YesAmount:      yesAmount,
+		NoAmount:       noAmount,
+	})
+	if err != nil {
+		return fmt.Errorf("record tick: %w", err)
+	}
+	return nil
+}
+
+func marketResolution(resolution program.Resolution) prediction.MarketResolution {
+	switch resolution {
+	case program.ResolutionYes:
+		return prediction.MarketResolutionYes
+	case program.ResolutionNo:
+		return prediction.MarketResolutionNo
+	case program.ResolutionTie:
+		return prediction.MarketResolutionTie
+	default:
+		return prediction.MarketResolutionUnresolved
+	}
+}
@@ -0,0 +1,225 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/checkpoint"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"slices"
+	"time"
+)
+
+const (
+	checkpointName     = "program"
+	signaturesPageSize = 1000
+	accountsBatchSize  = 100
+)
+
+type Config struct {
+	ProgramID    solana.PublicKey
+	PollInterval time.Duration
+	// MaxTxAttempts is how many times a transaction is applied before it is dead-lettered
+	MaxTxAttempts int32
+}
+
+// Indexer mirrors the accounts of our program into Postgres. It backfills every
+// program account once and then follows new program transactions from a checkpoint.
+// A transaction that keeps failing to apply is dead-lettered so the checkpoint moves on.
+type Indexer struct {
+	config         Config
+	client         *rpc.Client
+	logger         zerolog.Logger
+	applier        *Applier
+	checkpointRepo checkpoint.Repository
+}
+
+func New(
+	config Config,
+	client *rpc.Client,
+	logger zerolog.Logger,
+	applier *Applier,
+	checkpointRepo checkpoint.Repository,
+) *Indexer {
+	return &Indexer{
+		config:         config,
+		client:         client,
+		logger:         logger,
+		applier:        applier,
+		checkpointRepo: checkpointRepo,
+	}
+}
+
+func (i *Indexer) Run(ctx context.Context) error {
+	cp, err := i.checkpointRepo.GetCheckpoint(ctx, checkpointName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if cp, err = i.backfill(ctx); err != nil {
+			return fmt.Errorf("backfill: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("get checkpoint: %w", err)
+	}
+	i.logger.Info().Uint64("slot", cp.Slot).Str("signature", cp.Signature).Msg("indexer:following")
+	ticker := time.NewTicker(i.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if cp, err = i.follow(ctx, cp); err != nil {
+				i.logger.Err(err).Msg("indexer:follow failed")
+			}
+		}
+	}
+}
+
+func (i *Indexer) backfill(ctx context.Context) (checkpoint.Checkpoint, error) {
+	limit := 1
+	latest, err := i.client.GetSignaturesForAddressWithOpts(ctx, i.config.ProgramID, &rpc.GetSignaturesForAddressOpts{
+		Limit:      &limit,
+		Commitment: rpc.CommitmentFinalized,
+	})
+	if err != nil {
+		return checkpoint.Checkpoint{}, fmt.Errorf("get latest signature: %w", err)
+	}
+	accounts, err := i.client.GetProgramAccountsWithOpts(ctx, i.config.ProgramID, &rpc.GetProgramAccountsOpts{
+		Commitment: rpc.CommitmentFinalized,
+	})
+	if err != nil {
+		return checkpoint.Checkpoint{}, fmt.Errorf("get program accounts: %w", err)
+	}
+	if err = i.applier.ApplyAccounts(ctx, keyedAccounts(accounts)); err != nil {
+		return checkpoint.Checkpoint{}, err
+	}
+	cp := checkpoint.Checkpoint{Name: checkpointName, UpdatedAt: time.Now()}
+	if len(latest) > 0 {
+		cp.Slot = latest[0].Slot
+		cp.Signature = latest[0].Signature.String()
+	}
+	if err = i.checkpointRepo.SaveCheckpoint(ctx, cp); err != nil {
+		return checkpoint.Checkpoint{}, fmt.Errorf("save checkpoint: %w", err)
+	}
+	i.logger.Info().Int("accounts", len(accounts)).Uint64("slot", cp.Slot).Msg("indexer:backfilled")
+	return cp, nil
+}
+
+func (i *Indexer) follow(ctx context.Context, cp checkpoint.Checkpoint) (checkpoint.Checkpoint, error) {
+	signatures, err := i.signaturesSince(ctx, cp.Signature)
+	if err != nil {
+		return cp, fmt.Errorf("get signatures: %w", err)
+	}
+	for _, sig := range signatures {
+		if sig.Err == nil {
+			if err = i.applyTransaction(ctx, sig.Signature); err != nil {
+				failure, recordErr := i.checkpointRepo.RecordFailure(ctx, checkpoint.FailedTransaction{
+					Signature:    sig.Signature.String(),
+					Slot:         sig.Slot,
+					LastError:    err.Error(),
+					LastFailedAt: time.Now(),
+				}, i.config.MaxTxAttempts)
+				if recordErr != nil {
+					return cp, fmt.Errorf("record failed transaction %s: %w", sig.Signature, recordErr)
+				}
+				if !failure.DeadLettered() {
+					return cp, fmt.Errorf("apply transaction %s: %w", sig.Signature, err)
+				}
+				i.logger.Error().Err(err).
+					Stringer("signature", sig.Signature).
+					Int32("attempts", failure.Attempts).
+					Msg("indexer:transaction dead-lettered")
+			}
+		}
+		next := checkpoint.Checkpoint{
+			Name:      checkpointName,
+			Slot:      sig.Slot,
+			Signature: sig.Signature.String(),
+			UpdatedAt: time.Now(),
+		}
+		if err = i.checkpointRepo.SaveCheckpoint(ctx, next); err != nil {
+			return cp, fmt.Errorf("save checkpoint: %w", err)
+		}
+		cp = next
+	}
+	return cp, nil
+}
+
+// signaturesSince returns program signatures newer than the given one, oldest first
+func (i *Indexer) signaturesSince(ctx context.Context, since string) ([]*rpc.TransactionSignature, error) {
+	var until solana.Signature
+	if since != "" {
+		var err error
+		if until, err = solana.SignatureFromBase58(since); err != nil {
+			return nil, fmt.Errorf("parse checkpoint signature: %w", err)
+		}
+	}
+	limit := signaturesPageSize
+	var signatures []*rpc.TransactionSignature
+	var before solana.Signature
+	for {
+		page, err := i.client.GetSignaturesForAddressWithOpts(ctx, i.config.ProgramID, &rpc.GetSignaturesForAddressOpts{
+			Limit:      &limit,
+			Before:     before,
+			Until:      until,
+			Commitment: rpc.CommitmentFinalized,
+		})
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, page...)
+		if len(page) < limit {
+			break
+		}
+		before = page[len(page)-1].Signature
+	}
+	slices.Reverse(signatures)
+	return signatures, nil
+}
+
+func (i *Indexer) applyTransaction(ctx context.Context, signature solana.Signature) error {
+	var maxVersion uint64
+	result, err := i.client.GetTransaction(ctx, signature, &rpc.GetTransactionOpts{
+		Encoding:                       solana.EncodingBase64,
+		Commitment:                     rpc.CommitmentFinalized,
+		MaxSupportedTransactionVersion: &maxVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("get transaction: %w", err)
+	}
+	tx, err := result.Transaction.GetTransaction()
+	if err != nil {
+		return fmt.Errorf("decode transaction: %w", err)
+	}
+	keys := slices.Clone(tx.Message.AccountKeys)
+	if result.Meta != nil {
+		keys = append(keys, result.Meta.LoadedAddresses.Writable...)
+	}
+	var accounts []Account
+	for chunk := range slices.Chunk(keys, accountsBatchSize) {
+		res, err := i.client.GetMultipleAccountsWithOpts(ctx, chunk, &rpc.GetMultipleAccountsOpts{
+			Encoding:   solana.EncodingBase64,
+			Commitment: rpc.CommitmentFinalized,
+		})
+		if err != nil {
+			return fmt.Errorf("get accounts: %w", err)
+		}
+		for j, account := range res.Value {
+			if account == nil || !account.Owner.Equals(i.config.ProgramID) {
+				continue
+			}
+			accounts = append(accounts, Account{Pubkey: chunk[j], Data: account.Data.GetBinary()})
+		}
+	}
+	return i.applier.ApplyAccounts(ctx, accounts)
+}
+
+func keyedAccounts(result rpc.GetProgramAccountsResult) []Account {
+	accounts := make([]Account, len(result))
+	for i, account := range result {
+		accounts[i] = Account{Pubkey: account.Pubkey, Data: account.Account.Data.GetBinary()}
+	}
+	return accounts
+}
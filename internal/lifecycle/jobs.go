@@ -0,0 +1,111 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/config"
+	"github.com/IndexStorm/hit-my-bet-back/internal/notify"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/history"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/resolver"
+	"github.com/IndexStorm/hit-my-bet-back/internal/scheduler"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// Jobs move markets through their lifecycle: they close markets when trading ends,
+// remind resolvers of overdue resolutions and clean up stale data
+type Jobs struct {
+	config         config.Scheduler
+	predictionRepo prediction.Repository
+	resolverRepo   resolver.Repository
+	historyRepo    history.Repository
+	notifier       *notify.Notifier
+	logger         zerolog.Logger
+}
+
+func NewJobs(
+	config config.Scheduler,
+	predictionRepo prediction.Repository,
+	resolverRepo resolver.Repository,
+	historyRepo history.Repository,
+	notifier *notify.Notifier,
+	logger zerolog.Logger,
+) *Jobs {
+	return &Jobs{
+		config:         config,
+		predictionRepo: predictionRepo,
+		resolverRepo:   resolverRepo,
+		historyRepo:    historyRepo,
+		notifier:       notifier,
+		logger:         logger,
+	}
+}
+
+func (j *Jobs) Scheduled() []scheduler.Job {
+	return []scheduler.Job{
+		{Name: "close-markets", Interval: j.config.CloseInterval, Run: j.CloseMarkets},
+		{Name: "remind-resolvers", Interval: j.config.ReminderInterval, Run: j.RemindResolvers},
+		{Name: "prune-candles", Interval: j.config.MaintenanceInterval, Run: j.PruneCandles},
+		{Name: "expire-assignments", Interval: j.config.MaintenanceInterval, Run: j.ExpireAssignments},
+	}
+}
+
+func (j *Jobs) CloseMarkets(ctx context.Context) error {
+	closed, err := j.predictionRepo.CloseDueMarkets(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("close due markets: %w", err)
+	}
+	for _, market := range closed {
+		j.logger.Info().Str("market", market).Msg("lifecycle:market closed")
+	}
+	return nil
+}
+
+// RemindResolvers sends a reminder to the inbox of resolvers of overdue markets. A reminder
+// is only recorded together with its notification.
+func (j *Jobs) RemindResolvers(ctx context.Context) error {
+	now := time.Now()
+	var reminders []resolver.Reminder
+	err := j.resolverRepo.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		reminders, err = j.resolverRepo.RemindOverdue(ctx, now.Add(-j.config.ResolutionGrace), now.Add(-j.config.ReminderEvery), now)
+		if err != nil {
+			return fmt.Errorf("remind overdue: %w", err)
+		}
+		for _, reminder := range reminders {
+			if err = j.notifier.ResolutionOverdue(ctx, reminder); err != nil {
+				return fmt.Errorf("notify resolver of %s: %w", reminder.MarketID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, reminder := range reminders {
+		j.logger.Info().
+			Str("market", reminder.MarketID).
+			Str("resolver", reminder.ResolverPubkey).
+			Msg("lifecycle:resolver reminded")
+	}
+	return nil
+}
+
+func (j *Jobs) PruneCandles(ctx context.Context) error {
+	pruned, err := j.historyRepo.PruneCandles(ctx, history.IntervalMinute, time.Now().Add(-j.config.MinuteCandleRetention))
+	if err != nil {
+		return fmt.Errorf("prune candles: %w", err)
+	}
+	j.logger.Debug().Int64("candles", pruned).Msg("lifecycle:candles pruned")
+	return nil
+}
+
+func (j *Jobs) ExpireAssignments(ctx context.Context) error {
+	expired, err := j.resolverRepo.ExpirePending(ctx, time.Now().Add(-j.config.AssignmentTTL))
+	if err != nil {
+		return fmt.Errorf("expire pending assignments: %w", err)
+	}
+	j.logger.Debug().Int64("assignments", expired).Msg("lifecycle:assignments expired")
+	return nil
+}
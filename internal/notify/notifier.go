@@ -0,0 +1,172 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/comment"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/dispute"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/notification"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/resolver"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/nanoid"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"slices"
+	"time"
+)
+
+// Notifier turns domain events into notifications in the inboxes of the wallets they
+// concern. Call it within the transaction recording the event so both commit together,
+// events handled twice notify each wallet once.
+type Notifier struct {
+	notificationRepo notification.Repository
+	predictionRepo   prediction.Repository
+}
+
+func NewNotifier(notificationRepo notification.Repository, predictionRepo prediction.Repository) *Notifier {
+	return &Notifier{
+		notificationRepo: notificationRepo,
+		predictionRepo:   predictionRepo,
+	}
+}
+
+// MarketResolved tells the creator and the participants of the market about its
+// proposed resolution
+func (n *Notifier) MarketResolved(ctx context.Context, market prediction.Market, resolution dispute.Resolution) error {
+	recipients, err := n.participants(ctx, market, market.CreatorPubkey)
+	if err != nil {
+		return err
+	}
+	return n.notify(ctx, recipients, notification.KindMarketResolved, market.ID, market.ID, map[string]any{
+		"title":            market.Title,
+		"outcome":          resolution.ProposedOutcome,
+		"outcome_id":       resolution.ProposedOutcomeID,
+		"value":            resolution.ProposedValue,
+		"status":           resolution.Status,
+		"dispute_deadline": resolution.DisputeDeadline,
+	})
+}
+
+// PositionsSettled tells every wallet of the settlement what it can claim
+func (n *Notifier) PositionsSettled(ctx context.Context, market prediction.Market, payouts []prediction.Payout) error {
+	now := time.Now()
+	notifications := make([]notification.Notification, 0, len(payouts))
+	for _, payout := range payouts {
+		notifications = append(notifications, notification.Notification{
+			ID:              nanoid.RandomID(),
+			RecipientPubkey: payout.OwnerPubkey,
+			Kind:            notification.KindPositionSettled,
+			SubjectID:       market.ID,
+			MarketID:        zeronull.Text(market.ID),
+			Data: map[string]any{
+				"title":  market.Title,
+				"stake":  payout.Stake,
+				"payout": payout.Payout,
+			},
+			CreatedAt: now,
+		})
+	}
+	if err := n.notificationRepo.CreateNotifications(ctx, notifications); err != nil {
+		return fmt.Errorf("create notifications: %w", err)
+	}
+	return nil
+}
+
+// CommentReply tells the author of the parent comment about the reply
+func (n *Notifier) CommentReply(ctx context.Context, parent, reply comment.Comment) error {
+	if parent.AuthorPubkey == reply.AuthorPubkey {
+		return nil
+	}
+	return n.notify(ctx, []string{parent.AuthorPubkey}, notification.KindCommentReply, reply.ID, reply.MarketID, map[string]any{
+		"parent_id":     parent.ID,
+		"comment_id":    reply.ID,
+		"author_pubkey": reply.AuthorPubkey,
+	})
+}
+
+// DisputeOpened tells the resolver, the creator and the participants of the market that
+// its proposed resolution was challenged
+func (n *Notifier) DisputeOpened(ctx context.Context, challenge dispute.Challenge) error {
+	market, err := n.predictionRepo.GetMarket(ctx, challenge.MarketID)
+	if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	recipients, err := n.participants(ctx, market, market.CreatorPubkey, market.ResolverPubkey)
+	if err != nil {
+		return err
+	}
+	recipients = slices.DeleteFunc(recipients, func(pubkey string) bool {
+		return pubkey == challenge.ChallengerPubkey
+	})
+	return n.notify(ctx, recipients, notification.KindDisputeOpened, market.ID, market.ID, map[string]any{
+		"title":             market.Title,
+		"challenger_pubkey": challenge.ChallengerPubkey,
+		"outcome":           challenge.Outcome,
+		"outcome_id":        challenge.OutcomeID,
+		"value":             challenge.Value,
+	})
+}
+
+// ResolutionOverdue reminds the resolver of a closed market to propose its resolution,
+// every reminder is a separate notification
+func (n *Notifier) ResolutionOverdue(ctx context.Context, reminder resolver.Reminder) error {
+	market, err := n.predictionRepo.GetMarket(ctx, reminder.MarketID)
+	if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	subject := fmt.Sprintf("%s:%d", market.ID, reminder.RemindedAt.Unix())
+	return n.notify(ctx, []string{reminder.ResolverPubkey}, notification.KindResolutionOverdue, subject, market.ID, map[string]any{
+		"title":     market.Title,
+		"closed_at": market.ClosedAt,
+	})
+}
+
+// participants returns the wallets holding positions in the market and the extra
+// wallets, each once
+func (n *Notifier) participants(ctx context.Context, market prediction.Market, extra ...string) ([]string, error) {
+	positions, err := n.predictionRepo.GetMarketPositions(ctx, market.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get positions: %w", err)
+	}
+	seen := make(map[string]struct{}, len(positions)+len(extra))
+	recipients := make([]string, 0, len(positions)+len(extra))
+	add := func(pubkey string) {
+		if _, ok := seen[pubkey]; ok || pubkey == "" {
+			return
+		}
+		seen[pubkey] = struct{}{}
+		recipients = append(recipients, pubkey)
+	}
+	for _, pubkey := range extra {
+		add(pubkey)
+	}
+	for _, position := range positions {
+		add(position.OwnerPubkey)
+	}
+	return recipients, nil
+}
+
+func (n *Notifier) notify(
+	ctx context.Context,
+	recipients []string,
+	kind notification.Kind,
+	subject, market string,
+	data map[string]any,
+) error {
+	now := time.Now()
+	notifications := make([]notification.Notification, 0, len(recipients))
+	for _, recipient := range recipients {
+		notifications = append(notifications, notification.Notification{
+			ID:              nanoid.RandomID(),
+			RecipientPubkey: recipient,
+			Kind:            kind,
+			SubjectID:       subject,
+			MarketID:        zeronull.Text(market),
+			Data:            data,
+			CreatedAt:       now,
+		})
+	}
+	if err := n.notificationRepo.CreateNotifications(ctx, notifications); err != nil {
+		return fmt.Errorf("create notifications: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package oracle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/feed"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxHTTPResponseSize = 1 << 20
+
+var (
+	ErrPathNotFound = errors.New("json path not found")
+	ErrRedirect     = errors.New("oracle sources must not redirect")
+)
+
+// HTTPDoer is the part of the HTTP client the HTTP adapter needs
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// HTTPAdapter reads a number from a JSON document. Feed sources are limited to
+// allowlisted hosts so that market creators cannot make us call arbitrary URLs.
+type HTTPAdapter struct {
+	client       HTTPDoer
+	allowedHosts []string
+}
+
+// NewHTTPClient returns a client for the HTTP adapter that does not follow redirects,
+// an allowed host could otherwise send us to any URL
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return ErrRedirect
+		},
+	}
+}
+
+func NewHTTPAdapter(client HTTPDoer, allowedHosts []string) *HTTPAdapter {
+	return &HTTPAdapter{
+		client:       client,
+		allowedHosts: allowedHosts,
+	}
+}
+
+func (a *HTTPAdapter) Kind() feed.Kind {
+	return feed.KindHTTP
+}
+
+func (a *HTTPAdapter) Validate(_ context.Context, f feed.Feed) error {
+	u, err := url.Parse(f.Source)
+	if err != nil || u.Scheme != "https" {
+		return fmt.Errorf("%w: source must be an https url", ErrInvalidFeed)
+	}
+	if !slices.Contains(a.allowedHosts, u.Hostname()) {
+		return fmt.Errorf("%w: host %s is not allowed", ErrInvalidFeed, u.Hostname())
+	}
+	if f.Path == "" {
+		return fmt.Errorf("%w: path is required", ErrInvalidFeed)
+	}
+	return nil
+}
+
+func (a *HTTPAdapter) Observe(ctx context.Context, f feed.Feed) (Observation, error) {
+	if err := a.Validate(ctx, f); err != nil {
+		return Observation{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.Source, nil)
+	if err != nil {
+		return Observation{}, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return Observation{}, fmt.Errorf("get %s: %w", f.Source, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Observation{}, fmt.Errorf("get %s: status %d", f.Source, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseSize))
+	if err != nil {
+		return Observation{}, fmt.Errorf("read response: %w", err)
+	}
+	value, err := ExtractNumber(body, string(f.Path))
+	if err != nil {
+		return Observation{}, err
+	}
+	return Observation{Value: value, Time: time.Now()}, nil
+}
+
+// ExtractNumber reads the number at a dot separated path such as "data.prices.0.close"
+// from a JSON document, finite numeric strings are accepted as well
+func ExtractNumber(document []byte, path string) (float64, error) {
+	var value any
+	if err := json.Unmarshal(document, &value); err != nil {
+		return 0, fmt.Errorf("unmarshal document: %w", err)
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]any:
+			var ok bool
+			if value, ok = node[key]; !ok {
+				return 0, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return 0, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+			}
+			value = node[i]
+		default:
+			return 0, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+		}
+	}
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		// ParseFloat accepts NaN and infinities, which no condition can be evaluated against
+		number, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return 0, fmt.Errorf("value at %s is not a number", path)
+		}
+		return number, nil
+	default:
+		return 0, fmt.Errorf("value at %s is not a number", path)
+	}
+}
@@ -0,0 +1,116 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/feed"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractNumber(t *testing.T) {
+	document := []byte(`{"data":{"prices":[{"close":101.5},{"close":"99.25"},{"close":"NaN"},{"close":"-Inf"}],"label":"btc","flag":true}}`)
+	tests := []struct {
+		path    string
+		want    float64
+		wantErr error
+		// fails is set for values that are found but are not numbers
+		fails bool
+	}{
+		{path: "data.prices.0.close", want: 101.5},
+		{path: "data.prices.1.close", want: 99.25},
+		{path: "data.prices.2.close", fails: true},
+		{path: "data.prices.3.close", fails: true},
+		{path: "data.prices.4.close", wantErr: ErrPathNotFound},
+		{path: "data.prices.x.close", wantErr: ErrPathNotFound},
+		{path: "data.missing", wantErr: ErrPathNotFound},
+		{path: "data.label.value", wantErr: ErrPathNotFound},
+		{path: "data.label", fails: true},
+		{path: "data.flag", fails: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ExtractNumber(document, tt.path)
+			if tt.fails {
+				if err == nil {
+					t.Fatalf("ExtractNumber() = %v, want an error", got)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExtractNumber() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("ExtractNumber() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if _, err := ExtractNumber([]byte(`{`), "a"); err == nil {
+		t.Fatal("ExtractNumber() of malformed json succeeded")
+	}
+}
+
+// fakeHTTPDoer answers every request with the same response and records the requests
+type fakeHTTPDoer struct {
+	status   int
+	body     string
+	requests []*http.Request
+}
+
+func (f *fakeHTTPDoer) Do(req *http.Request) (*http.Response, error) {
+	f.requests = append(f.requests, req)
+	return &http.Response{StatusCode: f.status, Body: io.NopCloser(strings.NewReader(f.body))}, nil
+}
+
+func TestHTTPAdapterObserve(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		status    int
+		want      float64
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "allowed host", source: "https://api.example.com/price", status: http.StatusOK, want: 42.5, wantCalls: 1},
+		{name: "server error", source: "https://api.example.com/price", status: http.StatusBadGateway, wantErr: true, wantCalls: 1},
+		{name: "host not allowed", source: "https://evil.example.org/price", status: http.StatusOK, wantErr: true},
+		{name: "plain http", source: "http://api.example.com/price", status: http.StatusOK, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doer := &fakeHTTPDoer{status: tt.status, body: `{"price":{"last":42.5}}`}
+			adapter := NewHTTPAdapter(doer, []string{"api.example.com"})
+			observation, err := adapter.Observe(context.Background(), feed.Feed{Source: tt.source, Path: "price.last"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Observe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(doer.requests) != tt.wantCalls {
+				t.Fatalf("Observe() made %d requests, want %d", len(doer.requests), tt.wantCalls)
+			}
+			if !tt.wantErr && observation.Value != tt.want {
+				t.Fatalf("Observe() value = %v, want %v", observation.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPClientRedirect(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("redirect was followed")
+	}))
+	defer target.Close()
+	source := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer source.Close()
+
+	resp, err := NewHTTPClient(time.Second).Get(source.URL)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrRedirect) {
+		t.Fatalf("Get() error = %v, want %v", err, ErrRedirect)
+	}
+}
@@ -0,0 +1,87 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/feed"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"time"
+)
+
+var (
+	ErrUnsupportedKind = errors.New("oracle kind is not supported")
+	ErrInvalidFeed     = errors.New("oracle feed is not valid")
+	// ErrNotObservable means the source has no usable value yet, the feed is retried later
+	ErrNotObservable = errors.New("oracle value is not observable yet")
+)
+
+// Observation is a value read from an oracle source
+type Observation struct {
+	Value float64
+	Time  time.Time
+}
+
+// Adapter reads values of one feed kind
+type Adapter interface {
+	Kind() feed.Kind
+	// Validate checks the source and path of a feed before a market declares it,
+	// rejected feeds fail with ErrInvalidFeed
+	Validate(ctx context.Context, f feed.Feed) error
+	// Observe reads the value of the feed, observations published before the feed
+	// evaluation time are rejected with ErrNotObservable
+	Observe(ctx context.Context, f feed.Feed) (Observation, error)
+}
+
+// Registry dispatches feeds to the adapter of their kind
+type Registry struct {
+	adapters map[feed.Kind]Adapter
+}
+
+func NewRegistry(adapters ...Adapter) *Registry {
+	registry := &Registry{adapters: make(map[feed.Kind]Adapter, len(adapters))}
+	for _, adapter := range adapters {
+		registry.adapters[adapter.Kind()] = adapter
+	}
+	return registry
+}
+
+func (r *Registry) Validate(ctx context.Context, f feed.Feed) error {
+	adapter, ok := r.adapters[f.Kind]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedKind, f.Kind)
+	}
+	if !f.Operator.Valid() {
+		return fmt.Errorf("%w: operator %s", ErrInvalidFeed, f.Operator)
+	}
+	return adapter.Validate(ctx, f)
+}
+
+func (r *Registry) Observe(ctx context.Context, f feed.Feed) (Observation, error) {
+	adapter, ok := r.adapters[f.Kind]
+	if !ok {
+		return Observation{}, fmt.Errorf("%w: %s", ErrUnsupportedKind, f.Kind)
+	}
+	return adapter.Observe(ctx, f)
+}
+
+// Evaluate resolves YES when the observed value satisfies the feed condition and NO otherwise
+func Evaluate(operator feed.Operator, threshold, value float64) (prediction.MarketResolution, error) {
+	var holds bool
+	switch operator {
+	case feed.OperatorGT:
+		holds = value > threshold
+	case feed.OperatorGTE:
+		holds = value >= threshold
+	case feed.OperatorLT:
+		holds = value < threshold
+	case feed.OperatorLTE:
+		holds = value <= threshold
+	default:
+		return prediction.MarketResolutionUnresolved, fmt.Errorf("%w: operator %s", ErrInvalidFeed, operator)
+	}
+	if holds {
+		return prediction.MarketResolutionYes, nil
+	}
+	return prediction.MarketResolutionNo, nil
+}
@@ -0,0 +1,199 @@
+package oracle
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/feed"
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	pythMagic         = 0xa1b2c3d4
+	pythPriceAccount  = 3
+	pythStatusTrading = 1
+
+	// Offsets of the legacy push oracle price account
+	pythAccountTypeOffset = 8
+	pythExponentOffset    = 20
+	pythTimestampOffset   = 96
+	pythAggPriceOffset    = 208
+	pythAggConfOffset     = 216
+	pythAggStatusOffset   = 224
+	pythPriceAccountSize  = 240
+
+	// PriceUpdateV2 of the pull oracle, the verification level is a borsh enum
+	// and its Partial variant carries the number of signatures
+	pythPriceUpdateMinSize  = 8 + 32 + 1
+	pythVerificationPartial = 0
+	pythVerificationFull    = 1
+	pythFeedIDSize          = 32
+	pythPriceMessageSize    = pythFeedIDSize + 8 + 8 + 4 + 8
+)
+
+var (
+	ErrUnknownPythAccount = errors.New("account is not a pyth price account")
+	ErrUntrustedPythOwner = errors.New("price account is not owned by a pyth program")
+	ErrPythNotTrading     = errors.New("pyth price is not trading")
+	ErrPythNotVerified    = errors.New("pyth price update is not fully verified")
+
+	pythPriceUpdateDiscriminator = bin.SighashTypeID(bin.SIGHASH_ACCOUNT_NAMESPACE, "PriceUpdateV2")
+)
+
+// PythPrice is a price read from a Pyth account, the value is Price * 10^Exponent.
+// Verified is false for pull oracle updates posted with only part of the guardian signatures.
+type PythPrice struct {
+	Price       int64
+	Confidence  uint64
+	Exponent    int32
+	PublishTime time.Time
+	Trading     bool
+	Verified    bool
+}
+
+func (p PythPrice) Value() float64 {
+	return float64(p.Price) * math.Pow10(int(p.Exponent))
+}
+
+// ParsePythPrice decodes a legacy price account or a PriceUpdateV2 account of the pull oracle
+func ParsePythPrice(data []byte) (PythPrice, error) {
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data) == pythMagic {
+		return parsePythPriceAccount(data)
+	}
+	if len(data) >= pythPriceUpdateMinSize && bytes.Equal(data[:8], pythPriceUpdateDiscriminator[:]) {
+		return parsePythPriceUpdate(data)
+	}
+	return PythPrice{}, ErrUnknownPythAccount
+}
+
+func parsePythPriceAccount(data []byte) (PythPrice, error) {
+	if len(data) < pythPriceAccountSize || binary.LittleEndian.Uint32(data[pythAccountTypeOffset:]) != pythPriceAccount {
+		return PythPrice{}, ErrUnknownPythAccount
+	}
+	return PythPrice{
+		Price:       int64(binary.LittleEndian.Uint64(data[pythAggPriceOffset:])),
+		Confidence:  binary.LittleEndian.Uint64(data[pythAggConfOffset:]),
+		Exponent:    int32(binary.LittleEndian.Uint32(data[pythExponentOffset:])),
+		PublishTime: time.Unix(int64(binary.LittleEndian.Uint64(data[pythTimestampOffset:])), 0),
+		Trading:     binary.LittleEndian.Uint32(data[pythAggStatusOffset:]) == pythStatusTrading,
+		Verified:    true,
+	}, nil
+}
+
+func parsePythPriceUpdate(data []byte) (PythPrice, error) {
+	offset := 8 + 32
+	level := data[offset]
+	switch level {
+	case pythVerificationPartial:
+		// Partial verification is followed by the number of signatures
+		offset += 2
+	case pythVerificationFull:
+		offset++
+	default:
+		return PythPrice{}, ErrUnknownPythAccount
+	}
+	if len(data) < offset+pythPriceMessageSize {
+		return PythPrice{}, ErrUnknownPythAccount
+	}
+	message := data[offset+pythFeedIDSize:]
+	return PythPrice{
+		Price:       int64(binary.LittleEndian.Uint64(message)),
+		Confidence:  binary.LittleEndian.Uint64(message[8:]),
+		Exponent:    int32(binary.LittleEndian.Uint32(message[16:])),
+		PublishTime: time.Unix(int64(binary.LittleEndian.Uint64(message[20:])), 0),
+		// Posted updates carry no trading status
+		Trading:  true,
+		Verified: level == pythVerificationFull,
+	}, nil
+}
+
+// AccountReader is the part of the RPC client the Pyth adapter needs
+type AccountReader interface {
+	GetAccountInfo(ctx context.Context, account solana.PublicKey) (*rpc.GetAccountInfoResult, error)
+}
+
+// PythAdapter reads prices from Pyth accounts over RPC. Only accounts owned by one of
+// the trusted Pyth programs are read, anyone can create an account with the same layout.
+type PythAdapter struct {
+	reader     AccountReader
+	programIDs []solana.PublicKey
+}
+
+func NewPythAdapter(reader AccountReader, programIDs []solana.PublicKey) *PythAdapter {
+	return &PythAdapter{
+		reader:     reader,
+		programIDs: programIDs,
+	}
+}
+
+// ParseProgramIDs parses the configured Pyth program ids
+func ParseProgramIDs(ids []string) ([]solana.PublicKey, error) {
+	programIDs := make([]solana.PublicKey, 0, len(ids))
+	for _, id := range ids {
+		programID, err := solana.PublicKeyFromBase58(strings.TrimSpace(id))
+		if err != nil {
+			return nil, fmt.Errorf("parse pyth program id %s: %w", id, err)
+		}
+		programIDs = append(programIDs, programID)
+	}
+	return programIDs, nil
+}
+
+func (a *PythAdapter) Kind() feed.Kind {
+	return feed.KindPyth
+}
+
+// Validate checks that the source is a price account owned by a trusted Pyth program
+func (a *PythAdapter) Validate(ctx context.Context, f feed.Feed) error {
+	_, err := a.read(ctx, f)
+	if errors.Is(err, ErrUnknownPythAccount) || errors.Is(err, ErrUntrustedPythOwner) {
+		return fmt.Errorf("%w: %w", ErrInvalidFeed, err)
+	}
+	return err
+}
+
+func (a *PythAdapter) Observe(ctx context.Context, f feed.Feed) (Observation, error) {
+	price, err := a.read(ctx, f)
+	if err != nil {
+		return Observation{}, err
+	}
+	if !price.Trading {
+		return Observation{}, fmt.Errorf("%w: %w", ErrNotObservable, ErrPythNotTrading)
+	}
+	// A fully verified update may still be posted over a partially verified one
+	if !price.Verified {
+		return Observation{}, fmt.Errorf("%w: %w", ErrNotObservable, ErrPythNotVerified)
+	}
+	if price.PublishTime.Before(f.EvaluateAt) {
+		return Observation{}, ErrNotObservable
+	}
+	return Observation{Value: price.Value(), Time: price.PublishTime}, nil
+}
+
+func (a *PythAdapter) read(ctx context.Context, f feed.Feed) (PythPrice, error) {
+	account, err := solana.PublicKeyFromBase58(f.Source)
+	if err != nil {
+		return PythPrice{}, fmt.Errorf("%w: price account: %s", ErrInvalidFeed, err)
+	}
+	info, err := a.reader.GetAccountInfo(ctx, account)
+	if errors.Is(err, rpc.ErrNotFound) {
+		return PythPrice{}, ErrUnknownPythAccount
+	} else if err != nil {
+		return PythPrice{}, fmt.Errorf("get price account: %w", err)
+	}
+	if info == nil || info.Value == nil {
+		return PythPrice{}, ErrUnknownPythAccount
+	}
+	if !slices.ContainsFunc(a.programIDs, info.Value.Owner.Equals) {
+		return PythPrice{}, ErrUntrustedPythOwner
+	}
+	return ParsePythPrice(info.Value.Data.GetBinary())
+}
@@ -0,0 +1,214 @@
+package oracle
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/feed"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"math"
+	"testing"
+	"time"
+)
+
+var (
+	testPythProgram = solana.MustPublicKeyFromBase58("FsJ3A3u2vn5cTVofAjvy6y5kwABJAqYWpe4975bi2epH")
+	testPriceSource = solana.MustPublicKeyFromBase58("H6ARHf6YXhGYeQfUzQNGk6rDNnLBQKrenN712K4AQJEG")
+)
+
+func legacyPriceAccount(price int64, exponent int32, publishTime int64, status uint32) []byte {
+	data := make([]byte, pythPriceAccountSize)
+	binary.LittleEndian.PutUint32(data, pythMagic)
+	binary.LittleEndian.PutUint32(data[pythAccountTypeOffset:], pythPriceAccount)
+	binary.LittleEndian.PutUint32(data[pythExponentOffset:], uint32(exponent))
+	binary.LittleEndian.PutUint64(data[pythTimestampOffset:], uint64(publishTime))
+	binary.LittleEndian.PutUint64(data[pythAggPriceOffset:], uint64(price))
+	binary.LittleEndian.PutUint64(data[pythAggConfOffset:], 42)
+	binary.LittleEndian.PutUint32(data[pythAggStatusOffset:], status)
+	return data
+}
+
+func priceUpdateAccount(partial bool, price int64, exponent int32, publishTime int64) []byte {
+	data := append([]byte{}, pythPriceUpdateDiscriminator[:]...)
+	data = append(data, make([]byte, 32)...)
+	if partial {
+		data = append(data, 0, 5)
+	} else {
+		data = append(data, 1)
+	}
+	message := make([]byte, pythPriceMessageSize)
+	binary.LittleEndian.PutUint64(message[pythFeedIDSize:], uint64(price))
+	binary.LittleEndian.PutUint64(message[pythFeedIDSize+8:], 7)
+	binary.LittleEndian.PutUint32(message[pythFeedIDSize+16:], uint32(exponent))
+	binary.LittleEndian.PutUint64(message[pythFeedIDSize+20:], uint64(publishTime))
+	return append(data, message...)
+}
+
+func TestParsePythPrice(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    PythPrice
+		wantErr error
+	}{
+		{
+			name: "legacy trading",
+			data: legacyPriceAccount(6_512_345_000_000, -8, 1_700_000_000, pythStatusTrading),
+			want: PythPrice{Price: 6_512_345_000_000, Confidence: 42, Exponent: -8,
+				PublishTime: time.Unix(1_700_000_000, 0), Trading: true, Verified: true},
+		},
+		{
+			name: "legacy halted",
+			data: legacyPriceAccount(100, -2, 1_700_000_000, 2),
+			want: PythPrice{Price: 100, Confidence: 42, Exponent: -2, PublishTime: time.Unix(1_700_000_000, 0),
+				Verified: true},
+		},
+		{
+			name:    "legacy product account",
+			data:    func() []byte { d := legacyPriceAccount(1, 0, 0, 1); d[pythAccountTypeOffset] = 2; return d }(),
+			wantErr: ErrUnknownPythAccount,
+		},
+		{
+			name:    "legacy truncated",
+			data:    legacyPriceAccount(1, 0, 0, 1)[:pythAggStatusOffset],
+			wantErr: ErrUnknownPythAccount,
+		},
+		{
+			name: "price update full verification",
+			data: priceUpdateAccount(false, -1_250, -3, 1_700_000_100),
+			want: PythPrice{Price: -1_250, Confidence: 7, Exponent: -3,
+				PublishTime: time.Unix(1_700_000_100, 0), Trading: true, Verified: true},
+		},
+		{
+			name: "price update partial verification",
+			data: priceUpdateAccount(true, 99, 0, 1_700_000_200),
+			want: PythPrice{Price: 99, Confidence: 7, PublishTime: time.Unix(1_700_000_200, 0), Trading: true},
+		},
+		{
+			name:    "price update unknown verification",
+			data:    func() []byte { d := priceUpdateAccount(false, 1, 0, 0); d[8+32] = 2; return d }(),
+			wantErr: ErrUnknownPythAccount,
+		},
+		{
+			name:    "price update truncated",
+			data:    priceUpdateAccount(false, 1, 0, 0)[:60],
+			wantErr: ErrUnknownPythAccount,
+		},
+		{
+			name:    "unknown account",
+			data:    make([]byte, 300),
+			wantErr: ErrUnknownPythAccount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePythPrice(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParsePythPrice() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != tt.want {
+				t.Fatalf("ParsePythPrice() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPythPriceValue(t *testing.T) {
+	price := PythPrice{Price: 6_512_345_000_000, Exponent: -8}
+	if got := price.Value(); math.Abs(got-65_123.45) > 1e-9 {
+		t.Fatalf("Value() = %v, want 65123.45", got)
+	}
+}
+
+// fakeAccountReader serves accounts from memory
+type fakeAccountReader map[solana.PublicKey]*rpc.Account
+
+func (f fakeAccountReader) GetAccountInfo(_ context.Context, account solana.PublicKey) (*rpc.GetAccountInfoResult, error) {
+	value, ok := f[account]
+	if !ok {
+		return nil, rpc.ErrNotFound
+	}
+	return &rpc.GetAccountInfoResult{Value: value}, nil
+}
+
+func pythAccount(owner solana.PublicKey, data []byte) *rpc.Account {
+	return &rpc.Account{Owner: owner, Data: rpc.DataBytesOrJSONFromBytes(data)}
+}
+
+func TestPythAdapterOwner(t *testing.T) {
+	evaluateAt := time.Unix(1_700_000_000, 0)
+	data := legacyPriceAccount(100, 0, evaluateAt.Unix()+60, pythStatusTrading)
+	tests := []struct {
+		name        string
+		owner       solana.PublicKey
+		validateErr error
+		observeErr  error
+	}{
+		{name: "trusted owner", owner: testPythProgram},
+		{
+			name:        "spoofed account",
+			owner:       solana.SystemProgramID,
+			validateErr: ErrInvalidFeed,
+			observeErr:  ErrUntrustedPythOwner,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := NewPythAdapter(
+				fakeAccountReader{testPriceSource: pythAccount(tt.owner, data)},
+				[]solana.PublicKey{testPythProgram},
+			)
+			f := feed.Feed{Kind: feed.KindPyth, Source: testPriceSource.String(), EvaluateAt: evaluateAt}
+			if err := adapter.Validate(context.Background(), f); !errors.Is(err, tt.validateErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.validateErr)
+			}
+			observation, err := adapter.Observe(context.Background(), f)
+			if !errors.Is(err, tt.observeErr) {
+				t.Fatalf("Observe() error = %v, want %v", err, tt.observeErr)
+			}
+			if tt.observeErr == nil && observation.Value != 100 {
+				t.Fatalf("Observe() value = %v, want 100", observation.Value)
+			}
+		})
+	}
+}
+
+func TestPythAdapterObserve(t *testing.T) {
+	evaluateAt := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name    string
+		account *rpc.Account
+		wantErr error
+	}{
+		{
+			name:    "published before evaluation",
+			account: pythAccount(testPythProgram, legacyPriceAccount(1, 0, evaluateAt.Unix()-1, pythStatusTrading)),
+			wantErr: ErrNotObservable,
+		},
+		{
+			name:    "not trading",
+			account: pythAccount(testPythProgram, legacyPriceAccount(1, 0, evaluateAt.Unix()+1, 0)),
+			wantErr: ErrNotObservable,
+		},
+		{
+			name:    "partially verified",
+			account: pythAccount(testPythProgram, priceUpdateAccount(true, 1, 0, evaluateAt.Unix()+1)),
+			wantErr: ErrPythNotVerified,
+		},
+		{name: "missing account", wantErr: ErrUnknownPythAccount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := fakeAccountReader{}
+			if tt.account != nil {
+				reader[testPriceSource] = tt.account
+			}
+			adapter := NewPythAdapter(reader, []solana.PublicKey{testPythProgram})
+			_, err := adapter.Observe(context.Background(), feed.Feed{Source: testPriceSource.String(), EvaluateAt: evaluateAt})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Observe() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/arbitration"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/feed"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/internal/scheduler"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+const (
+	dueBatchSize = 100
+	// resolverPrefix marks resolutions proposed by an oracle instead of a wallet
+	resolverPrefix = "oracle:"
+)
+
+var ErrStaleObservation = errors.New("oracle value was not observed in time")
+
+type SchedulerConfig struct {
+	Interval    time.Duration
+	MaxAttempts int32
+	// MaxStaleness is how long after its evaluation time a feed may still be observed,
+	// later feeds fail so that the market is resolved by hand
+	MaxStaleness time.Duration
+}
+
+// Scheduler evaluates the feeds of closed markets and proposes their resolution,
+// which then goes through the regular dispute period
+type Scheduler struct {
+	config         SchedulerConfig
+	registry       *Registry
+	feedRepo       feed.Repository
+	predictionRepo prediction.Repository
+	court          *arbitration.Court
+	logger         zerolog.Logger
+}
+
+func NewScheduler(
+	config SchedulerConfig,
+	registry *Registry,
+	feedRepo feed.Repository,
+	predictionRepo prediction.Repository,
+	court *arbitration.Court,
+	logger zerolog.Logger,
+) *Scheduler {
+	return &Scheduler{
+		config:         config,
+		registry:       registry,
+		feedRepo:       feedRepo,
+		predictionRepo: predictionRepo,
+		court:          court,
+		logger:         logger,
+	}
+}
+
+func (s *Scheduler) Scheduled() []scheduler.Job {
+	return []scheduler.Job{
+		{Name: "evaluate-feeds", Interval: s.config.Interval, Run: s.EvaluateDue},
+	}
+}
+
+// EvaluateDue evaluates every feed due for evaluation, failures of one feed
+// are recorded on it and do not stop the others. Feeds that cannot be observed
+// within the staleness window fail right away.
+func (s *Scheduler) EvaluateDue(ctx context.Context) error {
+	now := time.Now()
+	feeds, err := s.feedRepo.ListDue(ctx, now, dueBatchSize)
+	if err != nil {
+		return fmt.Errorf("list due feeds: %w", err)
+	}
+	for _, f := range feeds {
+		err = s.evaluate(ctx, f)
+		if errors.Is(err, ErrNotObservable) {
+			if !now.After(f.EvaluateAt.Add(s.config.MaxStaleness)) {
+				continue
+			}
+			err = fmt.Errorf("%w: %w", ErrStaleObservation, err)
+		}
+		if err == nil {
+			continue
+		}
+		s.logger.Warn().Err(err).Str("market", f.MarketID).Msg("oracle:feed evaluation failed")
+		maxAttempts := s.config.MaxAttempts
+		if errors.Is(err, ErrStaleObservation) {
+			// Waiting longer cannot make the feed observable in time
+			maxAttempts = 1
+		}
+		if err = s.feedRepo.RecordFailure(ctx, f.MarketID, err.Error(), maxAttempts); err != nil {
+			return fmt.Errorf("record failure: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) evaluate(ctx context.Context, f feed.Feed) error {
+	observation, err := s.registry.Observe(ctx, f)
+	if err != nil {
+		return err
+	}
+	// A scheduler resuming long after the evaluation time would otherwise resolve
+	// the market on whatever the value is by then
+	if deadline := f.EvaluateAt.Add(s.config.MaxStaleness); observation.Time.After(deadline) {
+		return fmt.Errorf("%w: observed at %s, %s after the evaluation time",
+			ErrStaleObservation, observation.Time.UTC().Format(time.RFC3339), observation.Time.Sub(f.EvaluateAt))
+	}
+	outcome, err := Evaluate(f.Operator, f.Threshold, observation.Value)
+	if err != nil {
+		return err
+	}
+	market, err := s.predictionRepo.GetMarket(ctx, f.MarketID)
+	if err != nil {
+		return fmt.Errorf("get market: %w", err)
+	}
+	market.Resolution = outcome
+	market.ResolverPubkey = resolverPrefix + string(f.Kind)
+	err = s.feedRepo.RunInTx(ctx, func(ctx context.Context) error {
+		err := s.feedRepo.RecordObservation(ctx, f.MarketID, observation.Value, observation.Time, outcome)
+		if err != nil {
+			return fmt.Errorf("record observation: %w", err)
+		}
+		_, err = s.court.Propose(ctx, market)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.logger.Info().
+		Str("market", f.MarketID).
+		Float64("value", observation.Value).
+		Str("outcome", string(outcome)).
+		Msg("oracle:resolution proposed")
+	return nil
+}
@@ -0,0 +1,172 @@
+package oracle
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/internal/arbitration"
+	"github.com/IndexStorm/hit-my-bet-back/internal/notify"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/dispute"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/feed"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/notification"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/gagliardetto/solana-go"
+	"github.com/rs/zerolog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The fakes embed the repository interfaces so that only the methods the scheduler
+// reaches need an implementation, any other call panics
+
+type fakeFeedRepo struct {
+	feed.Repository
+	due          []feed.Feed
+	observations map[string]prediction.MarketResolution
+	failures     map[string]string
+	maxAttempts  map[string]int32
+}
+
+func (f *fakeFeedRepo) RunInTx(ctx context.Context, fn func(context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *fakeFeedRepo) ListDue(context.Context, time.Time, int) ([]feed.Feed, error) {
+	return f.due, nil
+}
+
+func (f *fakeFeedRepo) RecordObservation(
+	_ context.Context,
+	market string,
+	_ float64,
+	_ time.Time,
+	outcome prediction.MarketResolution,
+) error {
+	f.observations[market] = outcome
+	return nil
+}
+
+func (f *fakeFeedRepo) RecordFailure(_ context.Context, market string, reason string, maxAttempts int32) error {
+	f.failures[market] = reason
+	f.maxAttempts[market] = maxAttempts
+	return nil
+}
+
+type fakePredictionRepo struct {
+	prediction.Repository
+	markets map[string]prediction.Market
+}
+
+func (f *fakePredictionRepo) GetMarket(_ context.Context, id string) (prediction.Market, error) {
+	return f.markets[id], nil
+}
+
+func (f *fakePredictionRepo) GetMarketPositions(context.Context, string) ([]prediction.Position, error) {
+	return nil, nil
+}
+
+type fakeDisputeRepo struct {
+	dispute.Repository
+	proposals map[string]dispute.Resolution
+}
+
+func (f *fakeDisputeRepo) ProposeResolution(_ context.Context, resolution dispute.Resolution) (dispute.Resolution, error) {
+	f.proposals[resolution.MarketID] = resolution
+	return resolution, nil
+}
+
+type fakeNotificationRepo struct {
+	notification.Repository
+}
+
+func (f *fakeNotificationRepo) CreateNotifications(context.Context, []notification.Notification) error {
+	return nil
+}
+
+func TestSchedulerEvaluateDue(t *testing.T) {
+	// HTTP observations are made at the time of the call
+	evaluateAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	untrusted := solana.MustPublicKeyFromBase58("9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin")
+	unpublished := solana.MustPublicKeyFromBase58("So11111111111111111111111111111111111111112")
+	reader := fakeAccountReader{
+		// 120 at evaluation, the feed holding above 100 resolves YES
+		testPriceSource: pythAccount(testPythProgram, legacyPriceAccount(120, 0, evaluateAt.Unix()+5, pythStatusTrading)),
+		// no price published since the evaluation time yet
+		testPythProgram: pythAccount(testPythProgram, legacyPriceAccount(90, 0, evaluateAt.Unix()-5, pythStatusTrading)),
+		untrusted:       pythAccount(solana.SystemProgramID, legacyPriceAccount(1, 0, evaluateAt.Unix()+5, pythStatusTrading)),
+		// no price published since long before the evaluation time
+		unpublished: pythAccount(testPythProgram, legacyPriceAccount(90, 0, evaluateAt.Unix()-3*3600, pythStatusTrading)),
+	}
+	doer := &fakeHTTPDoer{status: 200, body: `{"value":7}`}
+	registry := NewRegistry(
+		NewPythAdapter(reader, []solana.PublicKey{testPythProgram}),
+		NewHTTPAdapter(doer, []string{"api.example.com"}),
+	)
+	feeds := &fakeFeedRepo{
+		due: []feed.Feed{
+			{MarketID: "yes", Kind: feed.KindPyth, Source: testPriceSource.String(),
+				Operator: feed.OperatorGT, Threshold: 100, EvaluateAt: evaluateAt},
+			{MarketID: "pending", Kind: feed.KindPyth, Source: testPythProgram.String(),
+				Operator: feed.OperatorGT, Threshold: 100, EvaluateAt: evaluateAt},
+			{MarketID: "spoofed", Kind: feed.KindPyth, Source: untrusted.String(),
+				Operator: feed.OperatorGT, Threshold: 100, EvaluateAt: evaluateAt},
+			{MarketID: "no", Kind: feed.KindHTTP, Source: "https://api.example.com/v", Path: "value",
+				Operator: feed.OperatorGTE, Threshold: 10, EvaluateAt: evaluateAt},
+			// the first price after the evaluation time came two hours late
+			{MarketID: "late", Kind: feed.KindPyth, Source: testPriceSource.String(),
+				Operator: feed.OperatorGT, Threshold: 100, EvaluateAt: evaluateAt.Add(-2 * time.Hour)},
+			{MarketID: "expired", Kind: feed.KindPyth, Source: unpublished.String(),
+				Operator: feed.OperatorGT, Threshold: 100, EvaluateAt: evaluateAt.Add(-2 * time.Hour)},
+		},
+		observations: map[string]prediction.MarketResolution{},
+		failures:     map[string]string{},
+		maxAttempts:  map[string]int32{},
+	}
+	markets := &fakePredictionRepo{markets: map[string]prediction.Market{}}
+	for _, f := range feeds.due {
+		markets.markets[f.MarketID] = prediction.Market{ID: f.MarketID, Kind: prediction.MarketKindBinary}
+	}
+	disputes := &fakeDisputeRepo{proposals: map[string]dispute.Resolution{}}
+	court := arbitration.NewCourt(disputes, markets, notify.NewNotifier(&fakeNotificationRepo{}, markets), time.Hour)
+	config := SchedulerConfig{MaxAttempts: 3, MaxStaleness: time.Hour}
+	scheduler := NewScheduler(config, registry, feeds, markets, court, zerolog.Nop())
+
+	if err := scheduler.EvaluateDue(context.Background()); err != nil {
+		t.Fatalf("EvaluateDue() error = %v", err)
+	}
+
+	wantObservations := map[string]prediction.MarketResolution{
+		"yes": prediction.MarketResolutionYes,
+		"no":  prediction.MarketResolutionNo,
+	}
+	if len(feeds.observations) != len(wantObservations) {
+		t.Fatalf("observations = %v, want %v", feeds.observations, wantObservations)
+	}
+	for market, outcome := range wantObservations {
+		if feeds.observations[market] != outcome {
+			t.Errorf("observation of %s = %s, want %s", market, feeds.observations[market], outcome)
+		}
+		proposal, ok := disputes.proposals[market]
+		if !ok || proposal.ProposedOutcome != outcome || proposal.Status != dispute.StatusProposed {
+			t.Errorf("proposal of %s = %+v, want a %s proposal", market, proposal, outcome)
+		}
+	}
+	if _, ok := feeds.failures["pending"]; ok {
+		t.Error("feed without a fresh price was recorded as failed, want it retried")
+	}
+	if reason := feeds.failures["spoofed"]; reason != ErrUntrustedPythOwner.Error() {
+		t.Errorf("failure of the feed reading an account of an untrusted owner = %q, want %q",
+			reason, ErrUntrustedPythOwner)
+	}
+	if _, ok := disputes.proposals["spoofed"]; ok {
+		t.Error("feed reading an account of an untrusted owner proposed a resolution")
+	}
+	for _, market := range []string{"late", "expired"} {
+		if !strings.HasPrefix(feeds.failures[market], ErrStaleObservation.Error()) || feeds.maxAttempts[market] != 1 {
+			t.Errorf("failure of %s = %q with %d attempts, want it failed for good as stale",
+				market, feeds.failures[market], feeds.maxAttempts[market])
+		}
+		if _, ok := disputes.proposals[market]; ok {
+			t.Errorf("stale feed %s proposed a resolution", market)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package portfolio
+
+import (
+	"github.com/IndexStorm/hit-my-bet-back/internal/pricing"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/profile"
+)
+
+type Outcome string
+
+const (
+	OutcomeWon      Outcome = "WON"
+	OutcomeLost     Outcome = "LOST"
+	OutcomeRefunded Outcome = "REFUNDED"
+	// OutcomePending is a resolved market that is not settled yet
+	OutcomePending Outcome = "PENDING"
+)
+
+// OpenPosition is marked to market by valuing its shares, bought at the price of the side
+// when the position was opened, at the current price of the side
+type OpenPosition struct {
+	MarketID       string                  `json:"market_id"`
+	MarketTitle    string                  `json:"market_title"`
+	PositionPubkey string                  `json:"position_pubkey"`
+	Side           prediction.PositionSide `json:"side"`
+	OutcomeID      *int16                  `json:"outcome_id,omitempty"`
+	Stake          int64                   `json:"stake"`
+	EntryPrice     float64                 `json:"entry_price"`
+	Shares         float64                 `json:"shares"`
+	Price          float64                 `json:"price"`
+	Value          float64                 `json:"value"`
+	UnrealizedPnL  float64                 `json:"unrealized_pnl"`
+}
+
+type ResolvedMarket struct {
+	MarketID    string                      `json:"market_id"`
+	MarketTitle string                      `json:"market_title"`
+	Resolution  prediction.MarketResolution `json:"resolution"`
+	Outcome     Outcome                     `json:"outcome"`
+	Stake       int64                       `json:"stake"`
+	Payout      int64                       `json:"payout"`
+	RealizedPnL int64                       `json:"realized_pnl"`
+}
+
+type Totals struct {
+	OpenStake     int64   `json:"open_stake"`
+	OpenValue     float64 `json:"open_value"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	RealizedPnL   int64   `json:"realized_pnl"`
+	Won           int     `json:"won"`
+	Lost          int     `json:"lost"`
+}
+
+type Portfolio struct {
+	Owner         string           `json:"owner"`
+	OpenPositions []OpenPosition   `json:"open_positions"`
+	Resolved      []ResolvedMarket `json:"resolved"`
+	Totals        Totals           `json:"totals"`
+	// Profile is set by the api when the owner has a profile
+	Profile *profile.Summary `json:"profile,omitempty"`
+}
+
+// Build computes the portfolio of a wallet from its positions and settlement payouts
+func Build(
+	owner string,
+	positions []prediction.OwnerPosition,
+	payouts []prediction.Payout,
+	model pricing.Model,
+) Portfolio {
+	portfolio := Portfolio{
+		Owner:         owner,
+		OpenPositions: make([]OpenPosition, 0),
+		Resolved:      make([]ResolvedMarket, 0),
+	}
+	payoutByMarket := make(map[string]prediction.Payout, len(payouts))
+	for _, payout := range payouts {
+		payoutByMarket[payout.MarketID] = payout
+	}
+	resolvedIndex := make(map[string]int)
+	for _, position := range positions {
+		if position.MarketResolution == prediction.MarketResolutionUnresolved {
+			open := openPosition(position, model)
+			portfolio.OpenPositions = append(portfolio.OpenPositions, open)
+			portfolio.Totals.OpenStake += open.Stake
+			portfolio.Totals.OpenValue += open.Value
+			portfolio.Totals.UnrealizedPnL += open.UnrealizedPnL
+			continue
+		}
+		if i, ok := resolvedIndex[position.MarketID]; ok {
+			portfolio.Resolved[i].Stake += position.Amount
+			continue
+		}
+		resolvedIndex[position.MarketID] = len(portfolio.Resolved)
+		portfolio.Resolved = append(portfolio.Resolved, ResolvedMarket{
+			MarketID:    position.MarketID,
+			MarketTitle: position.MarketTitle,
+			Resolution:  position.MarketResolution,
+			Outcome:     OutcomePending,
+			Stake:       position.Amount,
+		})
+	}
+	for i := range portfolio.Resolved {
+		resolved := &portfolio.Resolved[i]
+		payout, ok := payoutByMarket[resolved.MarketID]
+		if !ok {
+			continue
+		}
+		resolved.Stake = payout.Stake
+		resolved.Payout = payout.Payout
+		resolved.RealizedPnL = payout.Payout - payout.Stake
+		switch {
+		case resolved.Resolution == prediction.MarketResolutionTie:
+			resolved.Outcome = OutcomeRefunded
+		// Both sides of a scalar market are paid, the one that gained won
+		case resolved.Resolution == prediction.MarketResolutionValue && resolved.RealizedPnL < 0:
+			resolved.Outcome = OutcomeLost
+			portfolio.Totals.Lost++
+		case payout.Payout > 0:
+			resolved.Outcome = OutcomeWon
+			portfolio.Totals.Won++
+		default:
+			resolved.Outcome = OutcomeLost
+			portfolio.Totals.Lost++
+		}
+		portfolio.Totals.RealizedPnL += resolved.RealizedPnL
+	}
+	return portfolio
+}
+
+func openPosition(position prediction.OwnerPosition, model pricing.Model) OpenPosition {
+	side := pricing.SideYes
+	if position.Side == prediction.PositionSideNo {
+		side = pricing.SideNo
+	}
+	pool := pricing.Pool{Yes: float64(position.MarketYesAmount), No: float64(position.MarketNoAmount)}
+	price, err := model.Price(pool, side)
+	if err != nil {
+		price = 0
+	}
+	// Categorical markets record no price history, so their entry price is the current one
+	var outcomeID *int16
+	if position.MarketKind == prediction.MarketKindCategorical {
+		price = outcomePrice(position, model)
+		if position.OutcomeID.Valid {
+			outcomeID = &position.OutcomeID.Int16
+		}
+	}
+	entryPrice := price
+	if position.EntryYesProbability != 0 && outcomeID == nil {
+		entryPrice = float64(position.EntryYesProbability)
+		if side == pricing.SideNo {
+			entryPrice = 1 - entryPrice
+		}
+	}
+	open := OpenPosition{
+		MarketID:       position.MarketID,
+		MarketTitle:    position.MarketTitle,
+		PositionPubkey: position.PositionPubkey,
+		Side:           position.Side,
+		OutcomeID:      outcomeID,
+		Stake:          position.Amount,
+		EntryPrice:     entryPrice,
+		Price:          price,
+		Value:          float64(position.Amount),
+	}
+	if entryPrice > 0 {
+		open.Shares = float64(position.Amount) / entryPrice
+		open.Value = open.Shares * price
+	}
+	open.UnrealizedPnL = open.Value - float64(position.Amount)
+	return open
+}
+
+func outcomePrice(position prediction.OwnerPosition, model pricing.Model) float64 {
+	outcome := int(position.OutcomeID.Int16)
+	if !position.OutcomeID.Valid || outcome >= len(position.MarketOutcomeAmounts) {
+		return 0
+	}
+	pools := make([]float64, len(position.MarketOutcomeAmounts))
+	for i, amount := range position.MarketOutcomeAmounts {
+		pools[i] = float64(amount)
+	}
+	prices, err := model.Prices(pools)
+	if err != nil {
+		return 0
+	}
+	return prices[outcome]
+}
@@ -0,0 +1,41 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/pricing"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/cache"
+	"time"
+)
+
+const maxCachedPortfolios = 10_000
+
+// Service serves wallet portfolios from a short lived cache
+type Service struct {
+	predictionRepo prediction.Repository
+	pricingModel   pricing.Model
+	cache          *cache.TTL[string, Portfolio]
+}
+
+func NewService(predictionRepo prediction.Repository, pricingModel pricing.Model, ttl time.Duration) *Service {
+	return &Service{
+		predictionRepo: predictionRepo,
+		pricingModel:   pricingModel,
+		cache:          cache.NewTTL[string, Portfolio](ttl, maxCachedPortfolios),
+	}
+}
+
+func (s *Service) Get(ctx context.Context, owner string) (Portfolio, error) {
+	return s.cache.GetOrLoad(ctx, owner, func(ctx context.Context) (Portfolio, error) {
+		positions, err := s.predictionRepo.GetOwnerPositions(ctx, owner)
+		if err != nil {
+			return Portfolio{}, fmt.Errorf("get positions: %w", err)
+		}
+		payouts, err := s.predictionRepo.GetOwnerPayouts(ctx, owner)
+		if err != nil {
+			return Portfolio{}, fmt.Errorf("get payouts: %w", err)
+		}
+		return Build(owner, positions, payouts, s.pricingModel), nil
+	})
+}
@@ -0,0 +1,111 @@
+package pricing
+
+// CPMM is a constant product market maker over outcome shares. The share reserve of
+// a side mirrors the stake on the opposite side, so staking on a side makes it dearer.
+// A bet mints complete YES/NO sets for the amount, adds them to the reserves and
+// withdraws the bought side until the reserve product is back to its value before the bet.
+// Categorical markets keep the reserve of outcome i at G/pool_i with G chosen so the
+// reserves sum to the total stake, which reduces to the binary reserves for two outcomes.
+type CPMM struct{}
+
+func (CPMM) Name() string {
+	return ModelCPMM
+}
+
+// Price of a side is its share of the total stake
+func (CPMM) Price(pool Pool, side Side) (float64, error) {
+	own, other, err := orient(pool, side)
+	if err != nil {
+		return 0, err
+	}
+	if own+other <= 0 {
+		return 0.5, nil
+	}
+	return own / (own + other), nil
+}
+
+func (CPMM) Quote(pool Pool, side Side, amount float64) (Quote, error) {
+	own, other, err := orient(pool, side)
+	if err != nil {
+		return Quote{}, err
+	}
+	if amount <= 0 {
+		return Quote{}, ErrInvalidAmount
+	}
+	if own <= 0 || other <= 0 {
+		return Quote{}, ErrNoLiquidity
+	}
+	ownReserve, otherReserve := other, own
+	otherReserveAfter := otherReserve + amount
+	ownReserveAfter := ownReserve * otherReserve / otherReserveAfter
+	shares := ownReserve + amount - ownReserveAfter
+	before := otherReserve / (ownReserve + otherReserve)
+	after := otherReserveAfter / (ownReserveAfter + otherReserveAfter)
+	return newQuote(side, amount, shares, before, after), nil
+}
+
+// Prices of outcomes are their shares of the total stake
+func (CPMM) Prices(pools []float64) ([]float64, error) {
+	if len(pools) == 0 {
+		return nil, ErrNoLiquidity
+	}
+	var total float64
+	for _, pool := range pools {
+		total += pool
+	}
+	prices := make([]float64, len(pools))
+	for i, pool := range pools {
+		if total <= 0 {
+			prices[i] = 1 / float64(len(pools))
+		} else {
+			prices[i] = pool / total
+		}
+	}
+	return prices, nil
+}
+
+func (m CPMM) QuoteOutcome(pools []float64, outcome int, amount float64) (Quote, error) {
+	if err := checkOutcome(pools, outcome); err != nil {
+		return Quote{}, err
+	}
+	if amount <= 0 {
+		return Quote{}, ErrInvalidAmount
+	}
+	if len(pools) < 2 {
+		return Quote{}, ErrNoLiquidity
+	}
+	var total, inverse float64
+	for _, pool := range pools {
+		if pool <= 0 {
+			return Quote{}, ErrNoLiquidity
+		}
+		total += pool
+		inverse += 1 / pool
+	}
+	reserves := make([]float64, len(pools))
+	for i, pool := range pools {
+		reserves[i] = total / inverse / pool
+	}
+	// Every other reserve grows by the minted amount, the bought one shrinks so the
+	// product is unchanged
+	after := make([]float64, len(pools))
+	own := reserves[outcome]
+	for i, reserve := range reserves {
+		after[i] = reserve + amount
+		if i != outcome {
+			own *= reserve / after[i]
+		}
+	}
+	after[outcome] = own
+	shares := reserves[outcome] + amount - own
+	return newOutcomeQuote(outcome, amount, shares, reservePrice(reserves, outcome), reservePrice(after, outcome)), nil
+}
+
+// reservePrice is the price of the outcome implied by share reserves, inverse to its reserve
+func reservePrice(reserves []float64, outcome int) float64 {
+	var total float64
+	for _, reserve := range reserves {
+		total += reserves[outcome] / reserve
+	}
+	return 1 / total
+}
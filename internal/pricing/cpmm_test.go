@@ -0,0 +1,183 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+const priceTolerance = 1e-9
+
+var binaryPools = []Pool{
+	{Yes: 0, No: 0},
+	{Yes: 100, No: 0},
+	{Yes: 0, No: 100},
+	{Yes: 100, No: 100},
+	{Yes: 250, No: 750},
+	// Extreme pools come last, their prices are too close to 1 to move measurably
+	{Yes: 1e9, No: 1},
+}
+
+var categoricalPools = [][]float64{
+	{0, 0, 0},
+	{100, 0, 0},
+	{100, 200, 300},
+	{1, 1, 1, 1},
+	{5e8, 1, 250, 3},
+}
+
+// checkBinaryPrices asserts that the prices of both sides are probabilities summing to 1
+// and that the binary pool is priced the same as the pools [YES, NO]
+func checkBinaryPrices(t *testing.T, model Model, pool Pool) {
+	t.Helper()
+	yes, err := model.Price(pool, SideYes)
+	if err != nil {
+		t.Fatalf("Price(%+v, YES) error = %v", pool, err)
+	}
+	no, err := model.Price(pool, SideNo)
+	if err != nil {
+		t.Fatalf("Price(%+v, NO) error = %v", pool, err)
+	}
+	for _, price := range []float64{yes, no} {
+		if price < 0 || price > 1 {
+			t.Fatalf("Price(%+v) = %v, want within [0, 1]", pool, price)
+		}
+	}
+	if math.Abs(yes+no-1) > priceTolerance {
+		t.Fatalf("Price(%+v) YES %v + NO %v = %v, want 1", pool, yes, no, yes+no)
+	}
+	prices, err := model.Prices([]float64{pool.Yes, pool.No})
+	if err != nil {
+		t.Fatalf("Prices(%+v) error = %v", pool, err)
+	}
+	if math.Abs(prices[0]-yes) > priceTolerance || math.Abs(prices[1]-no) > priceTolerance {
+		t.Fatalf("Prices(%+v) = %v, want [%v %v]", pool, prices, yes, no)
+	}
+}
+
+// checkPrices asserts that outcome prices are probabilities summing to 1
+func checkPrices(t *testing.T, model Model, pools []float64) []float64 {
+	t.Helper()
+	prices, err := model.Prices(pools)
+	if err != nil {
+		t.Fatalf("Prices(%v) error = %v", pools, err)
+	}
+	if len(prices) != len(pools) {
+		t.Fatalf("Prices(%v) returned %d prices", pools, len(prices))
+	}
+	var sum float64
+	for _, price := range prices {
+		if price < 0 || price > 1 {
+			t.Fatalf("Prices(%v) = %v, want within [0, 1]", pools, prices)
+		}
+		sum += price
+	}
+	if math.Abs(sum-1) > priceTolerance {
+		t.Fatalf("Prices(%v) sum = %v, want 1", pools, sum)
+	}
+	return prices
+}
+
+// checkStakeRaisesPrice asserts that staking on each side and outcome raises its price,
+// both for the stake added to the pool and for the quoted bet
+func checkStakeRaisesPrice(t *testing.T, model Model, pools []float64, amount float64) {
+	t.Helper()
+	before := checkPrices(t, model, pools)
+	for outcome := range pools {
+		staked := append([]float64(nil), pools...)
+		staked[outcome] += amount
+		after := checkPrices(t, model, staked)
+		if after[outcome] <= before[outcome] {
+			t.Fatalf("Prices(%v)[%d] = %v after staking %v, want above %v",
+				pools, outcome, after[outcome], amount, before[outcome])
+		}
+		quote, err := model.QuoteOutcome(pools, outcome, amount)
+		if errors.Is(err, ErrNoLiquidity) {
+			continue
+		} else if err != nil {
+			t.Fatalf("QuoteOutcome(%v, %d) error = %v", pools, outcome, err)
+		}
+		if quote.Shares <= 0 || quote.PriceAfter <= quote.PriceBefore || quote.PriceAfter > 1 {
+			t.Fatalf("QuoteOutcome(%v, %d) = %+v, want positive shares and a rising price", pools, outcome, quote)
+		}
+	}
+}
+
+func TestCPMMPrices(t *testing.T) {
+	model := CPMM{}
+	for _, pool := range binaryPools {
+		checkBinaryPrices(t, model, pool)
+	}
+	for _, pools := range categoricalPools {
+		checkPrices(t, model, pools)
+	}
+	if _, err := model.Prices(nil); !errors.Is(err, ErrNoLiquidity) {
+		t.Fatalf("Prices(nil) error = %v, want %v", err, ErrNoLiquidity)
+	}
+	if _, err := model.Price(Pool{}, "MAYBE"); !errors.Is(err, ErrUnknownSide) {
+		t.Fatalf("Price() error = %v, want %v", err, ErrUnknownSide)
+	}
+}
+
+func TestCPMMStakeRaisesPrice(t *testing.T) {
+	model := CPMM{}
+	for _, pool := range binaryPools[:len(binaryPools)-1] {
+		for _, side := range []Side{SideYes, SideNo} {
+			before, _ := model.Price(pool, side)
+			staked := pool
+			if side == SideYes {
+				staked.Yes += 50
+			} else {
+				staked.No += 50
+			}
+			after, _ := model.Price(staked, side)
+			// A side holding the whole stake is already priced at 1
+			if after < before || after == before && before < 1 {
+				t.Fatalf("Price(%+v, %s) = %v after staking, want above %v", pool, side, after, before)
+			}
+			quote, err := model.Quote(pool, side, 50)
+			if errors.Is(err, ErrNoLiquidity) {
+				continue
+			} else if err != nil {
+				t.Fatalf("Quote(%+v, %s) error = %v", pool, side, err)
+			}
+			if quote.Shares <= 0 || quote.PriceAfter <= quote.PriceBefore || quote.PriceAfter > 1 {
+				t.Fatalf("Quote(%+v, %s) = %+v, want positive shares and a rising price", pool, side, quote)
+			}
+		}
+	}
+	for _, pools := range categoricalPools[2:] {
+		checkStakeRaisesPrice(t, model, pools, 50)
+	}
+}
+
+func TestCPMMQuote(t *testing.T) {
+	model := CPMM{}
+	quote, err := model.Quote(Pool{Yes: 100, No: 100}, SideYes, 100)
+	if err != nil {
+		t.Fatalf("Quote() error = %v", err)
+	}
+	// 100 minted sets grow the NO reserve to 200, so the YES reserve falls to 100*100/200 = 50
+	if math.Abs(quote.Shares-150) > priceTolerance {
+		t.Fatalf("Quote() shares = %v, want 150", quote.Shares)
+	}
+	if math.Abs(quote.PriceBefore-0.5) > priceTolerance || math.Abs(quote.PriceAfter-0.8) > priceTolerance {
+		t.Fatalf("Quote() prices = %v -> %v, want 0.5 -> 0.8", quote.PriceBefore, quote.PriceAfter)
+	}
+	if _, err = model.Quote(Pool{Yes: 100, No: 100}, SideYes, 0); !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("Quote() error = %v, want %v", err, ErrInvalidAmount)
+	}
+	if _, err = model.Quote(Pool{Yes: 100}, SideYes, 10); !errors.Is(err, ErrNoLiquidity) {
+		t.Fatalf("Quote() error = %v, want %v", err, ErrNoLiquidity)
+	}
+	binary, err := model.QuoteOutcome([]float64{100, 100}, 0, 100)
+	if err != nil {
+		t.Fatalf("QuoteOutcome() error = %v", err)
+	}
+	if math.Abs(binary.Shares-quote.Shares) > priceTolerance {
+		t.Fatalf("QuoteOutcome() shares = %v, want the binary %v", binary.Shares, quote.Shares)
+	}
+	if _, err = model.QuoteOutcome([]float64{100, 100}, 2, 10); !errors.Is(err, ErrUnknownOutcome) {
+		t.Fatalf("QuoteOutcome() error = %v, want %v", err, ErrUnknownOutcome)
+	}
+}
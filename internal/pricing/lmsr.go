@@ -0,0 +1,118 @@
+package pricing
+
+import "math"
+
+// LMSR is Hanson's logarithmic market scoring rule with the pool amounts taken as
+// outstanding shares of each side. Liquidity bounds the maker loss to b*ln(2).
+type LMSR struct {
+	Liquidity float64
+}
+
+func (LMSR) Name() string {
+	return ModelLMSR
+}
+
+func (m LMSR) Price(pool Pool, side Side) (float64, error) {
+	own, other, err := orient(pool, side)
+	if err != nil {
+		return 0, err
+	}
+	return m.price(own, other), nil
+}
+
+// price is exp(own/b) / (exp(own/b) + exp(other/b)), written as a logistic to avoid overflow
+func (m LMSR) price(own, other float64) float64 {
+	return 1 / (1 + math.Exp((other-own)/m.Liquidity))
+}
+
+// Quote solves C(own+shares, other) - C(own, other) = amount for the cost function
+// C = b*ln(exp(own/b) + exp(other/b)), which gives
+// shares = b*ln(exp(t) + exp(d)*(exp(t)-1)) with t = amount/b and d = (other-own)/b.
+func (m LMSR) Quote(pool Pool, side Side, amount float64) (Quote, error) {
+	own, other, err := orient(pool, side)
+	if err != nil {
+		return Quote{}, err
+	}
+	if amount <= 0 {
+		return Quote{}, ErrInvalidAmount
+	}
+	t := amount / m.Liquidity
+	d := (other - own) / m.Liquidity
+	shares := m.Liquidity * logAddExp(t, d+logExpm1(t))
+	before := m.price(own, other)
+	after := m.price(own+shares, other)
+	return newQuote(side, amount, shares, before, after), nil
+}
+
+// Prices are the softmax of pools/b
+func (m LMSR) Prices(pools []float64) ([]float64, error) {
+	if len(pools) == 0 {
+		return nil, ErrNoLiquidity
+	}
+	scaled := make([]float64, len(pools))
+	for i, pool := range pools {
+		scaled[i] = pool / m.Liquidity
+	}
+	norm := logSumExp(scaled)
+	prices := make([]float64, len(pools))
+	for i, value := range scaled {
+		prices[i] = math.Exp(value - norm)
+	}
+	return prices, nil
+}
+
+// QuoteOutcome solves the same cost equation as Quote over all outcomes, with
+// d = ln(sum of exp(pool/b) over the other outcomes) - own/b.
+func (m LMSR) QuoteOutcome(pools []float64, outcome int, amount float64) (Quote, error) {
+	if err := checkOutcome(pools, outcome); err != nil {
+		return Quote{}, err
+	}
+	if amount <= 0 {
+		return Quote{}, ErrInvalidAmount
+	}
+	if len(pools) < 2 {
+		return Quote{}, ErrNoLiquidity
+	}
+	others := make([]float64, 0, len(pools)-1)
+	for i, pool := range pools {
+		if i != outcome {
+			others = append(others, pool/m.Liquidity)
+		}
+	}
+	t := amount / m.Liquidity
+	d := logSumExp(others) - pools[outcome]/m.Liquidity
+	shares := m.Liquidity * logAddExp(t, d+logExpm1(t))
+	before, err := m.Prices(pools)
+	if err != nil {
+		return Quote{}, err
+	}
+	bought := append([]float64(nil), pools...)
+	bought[outcome] += shares
+	after, err := m.Prices(bought)
+	if err != nil {
+		return Quote{}, err
+	}
+	return newOutcomeQuote(outcome, amount, shares, before[outcome], after[outcome]), nil
+}
+
+func logSumExp(values []float64) float64 {
+	hi := math.Inf(-1)
+	for _, value := range values {
+		hi = math.Max(hi, value)
+	}
+	var sum float64
+	for _, value := range values {
+		sum += math.Exp(value - hi)
+	}
+	return hi + math.Log(sum)
+}
+
+func logAddExp(a, b float64) float64 {
+	hi, lo := math.Max(a, b), math.Min(a, b)
+	return hi + math.Log1p(math.Exp(lo-hi))
+}
+
+// logExpm1 returns ln(exp(x)-1) for x > 0 without overflowing exp(x) on large bets
+func logExpm1(x float64) float64 {
+	return x + math.Log(-math.Expm1(-x))
+}
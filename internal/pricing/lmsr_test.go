@@ -0,0 +1,96 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestLMSRPrices(t *testing.T) {
+	for _, liquidity := range []float64{1, 100, 1e6} {
+		model := LMSR{Liquidity: liquidity}
+		for _, pool := range binaryPools {
+			checkBinaryPrices(t, model, pool)
+		}
+		for _, pools := range categoricalPools {
+			checkPrices(t, model, pools)
+		}
+	}
+	model := LMSR{Liquidity: 100}
+	if price, _ := model.Price(Pool{Yes: 100, No: 100}, SideYes); math.Abs(price-0.5) > priceTolerance {
+		t.Fatalf("Price() of a balanced pool = %v, want 0.5", price)
+	}
+	if _, err := model.Prices(nil); !errors.Is(err, ErrNoLiquidity) {
+		t.Fatalf("Prices(nil) error = %v, want %v", err, ErrNoLiquidity)
+	}
+}
+
+func TestLMSRStakeRaisesPrice(t *testing.T) {
+	model := LMSR{Liquidity: 100}
+	for _, pool := range binaryPools[:len(binaryPools)-1] {
+		for _, side := range []Side{SideYes, SideNo} {
+			before, _ := model.Price(pool, side)
+			staked := pool
+			if side == SideYes {
+				staked.Yes += 50
+			} else {
+				staked.No += 50
+			}
+			if after, _ := model.Price(staked, side); after <= before {
+				t.Fatalf("Price(%+v, %s) = %v after staking, want above %v", pool, side, after, before)
+			}
+			quote, err := model.Quote(pool, side, 50)
+			if err != nil {
+				t.Fatalf("Quote(%+v, %s) error = %v", pool, side, err)
+			}
+			if quote.Shares <= 0 || quote.PriceAfter <= quote.PriceBefore || quote.PriceAfter > 1 {
+				t.Fatalf("Quote(%+v, %s) = %+v, want positive shares and a rising price", pool, side, quote)
+			}
+		}
+	}
+	for _, pools := range categoricalPools[:len(categoricalPools)-1] {
+		checkStakeRaisesPrice(t, model, pools, 50)
+	}
+}
+
+func TestLMSRQuote(t *testing.T) {
+	model := LMSR{Liquidity: 100}
+	cost := func(pools ...float64) float64 {
+		scaled := make([]float64, len(pools))
+		for i, pool := range pools {
+			scaled[i] = pool / model.Liquidity
+		}
+		return model.Liquidity * logSumExp(scaled)
+	}
+	for _, pool := range binaryPools[:len(binaryPools)-1] {
+		quote, err := model.Quote(pool, SideYes, 40)
+		if err != nil {
+			t.Fatalf("Quote(%+v) error = %v", pool, err)
+		}
+		// The shares bought must cost exactly the amount paid
+		if paid := cost(pool.Yes+quote.Shares, pool.No) - cost(pool.Yes, pool.No); math.Abs(paid-40) > 1e-6 {
+			t.Fatalf("Quote(%+v) shares %v cost %v, want 40", pool, quote.Shares, paid)
+		}
+		binary, err := model.QuoteOutcome([]float64{pool.Yes, pool.No}, 0, 40)
+		if err != nil {
+			t.Fatalf("QuoteOutcome(%+v) error = %v", pool, err)
+		}
+		if math.Abs(binary.Shares-quote.Shares) > 1e-6 {
+			t.Fatalf("QuoteOutcome(%+v) shares = %v, want the binary %v", pool, binary.Shares, quote.Shares)
+		}
+	}
+	pools := []float64{100, 200, 300}
+	quote, err := model.QuoteOutcome(pools, 1, 40)
+	if err != nil {
+		t.Fatalf("QuoteOutcome() error = %v", err)
+	}
+	if paid := cost(100, 200+quote.Shares, 300) - cost(pools...); math.Abs(paid-40) > 1e-6 {
+		t.Fatalf("QuoteOutcome() shares %v cost %v, want 40", quote.Shares, paid)
+	}
+	if _, err = model.Quote(Pool{}, SideYes, -1); !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("Quote() error = %v, want %v", err, ErrInvalidAmount)
+	}
+	if _, err = model.QuoteOutcome([]float64{100}, 0, 10); !errors.Is(err, ErrNoLiquidity) {
+		t.Fatalf("QuoteOutcome() error = %v, want %v", err, ErrNoLiquidity)
+	}
+}
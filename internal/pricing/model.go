@@ -0,0 +1,129 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+type Side string
+
+const (
+	SideYes Side = "YES"
+	SideNo  Side = "NO"
+)
+
+const (
+	ModelCPMM = "cpmm"
+	ModelLMSR = "lmsr"
+)
+
+var (
+	ErrUnknownModel   = errors.New("unknown pricing model")
+	ErrUnknownSide    = errors.New("unknown side")
+	ErrInvalidAmount  = errors.New("amount must be positive")
+	ErrNoLiquidity    = errors.New("market has no liquidity")
+	ErrUnknownOutcome = errors.New("unknown outcome")
+)
+
+// Pool holds the amounts staked on each side of a binary market
+type Pool struct {
+	Yes float64
+	No  float64
+}
+
+// Quote describes a hypothetical bet of Amount on Side, or on Outcome of a categorical
+// market. Shares pay out one unit each if the side wins, so AveragePrice is Amount / Shares.
+type Quote struct {
+	Side         Side    `json:"side,omitempty"`
+	Outcome      *int    `json:"outcome,omitempty"`
+	Amount       float64 `json:"amount"`
+	Shares       float64 `json:"shares"`
+	AveragePrice float64 `json:"average_price"`
+	PriceBefore  float64 `json:"price_before"`
+	PriceAfter   float64 `json:"price_after"`
+	Slippage     float64 `json:"slippage"`
+}
+
+// Model prices binary and categorical markets. Prices are implied probabilities: they
+// stay within [0, 1], prices of all outcomes sum to 1, and buying an outcome never
+// lowers its price. Categorical pools hold the amount staked on each outcome, a binary
+// market priced as the pools [YES, NO] gets the same prices as its Pool.
+type Model interface {
+	Name() string
+	Price(pool Pool, side Side) (float64, error)
+	Quote(pool Pool, side Side, amount float64) (Quote, error)
+	Prices(pools []float64) ([]float64, error)
+	QuoteOutcome(pools []float64, outcome int, amount float64) (Quote, error)
+}
+
+type Config struct {
+	Model string
+	// Liquidity is the LMSR b parameter, in the same units as pool amounts
+	Liquidity float64
+}
+
+func New(config Config) (Model, error) {
+	switch config.Model {
+	case ModelCPMM:
+		return CPMM{}, nil
+	case ModelLMSR:
+		if config.Liquidity <= 0 {
+			return nil, fmt.Errorf("lmsr liquidity must be positive, got %v", config.Liquidity)
+		}
+		return LMSR{Liquidity: config.Liquidity}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownModel, config.Model)
+	}
+}
+
+func ParseSide(value string) (Side, error) {
+	switch side := Side(value); side {
+	case SideYes, SideNo:
+		return side, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownSide, value)
+	}
+}
+
+// orient returns the pool as (bought side, opposite side)
+func orient(pool Pool, side Side) (float64, float64, error) {
+	switch side {
+	case SideYes:
+		return pool.Yes, pool.No, nil
+	case SideNo:
+		return pool.No, pool.Yes, nil
+	default:
+		return 0, 0, fmt.Errorf("%w: %s", ErrUnknownSide, side)
+	}
+}
+
+func checkOutcome(pools []float64, outcome int) error {
+	if outcome < 0 || outcome >= len(pools) {
+		return fmt.Errorf("%w: %d", ErrUnknownOutcome, outcome)
+	}
+	return nil
+}
+
+func newOutcomeQuote(outcome int, amount, shares, before, after float64) Quote {
+	quote := newQuote("", amount, shares, before, after)
+	quote.Outcome = &outcome
+	return quote
+}
+
+func newQuote(side Side, amount, shares, before, after float64) Quote {
+	quote := Quote{
+		Side:        side,
+		Amount:      amount,
+		Shares:      shares,
+		PriceBefore: before,
+		PriceAfter:  after,
+	}
+	if shares > 0 {
+		quote.AveragePrice = amount / shares
+	}
+	if before > 0 {
+		quote.Slippage = math.Max(0, quote.AveragePrice/before-1)
+	}
+	return quote
+}
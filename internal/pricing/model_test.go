@@ -0,0 +1,111 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// propertyRuns is the number of random pools every property is checked on
+const propertyRuns = 2_000
+
+var propertyModels = []Model{
+	CPMM{},
+	LMSR{Liquidity: 1},
+	LMSR{Liquidity: 100},
+	LMSR{Liquidity: 1e6},
+}
+
+// newPropertyRand is seeded so that a failing pool is reported the same on every run
+func newPropertyRand() *rand.Rand {
+	return rand.New(rand.NewPCG(7, 2_024))
+}
+
+// randomPools returns 2 to 8 pools, each either empty or log-uniform in [1, 1e6]
+func randomPools(r *rand.Rand) []float64 {
+	pools := make([]float64, 2+r.IntN(7))
+	for i := range pools {
+		if r.IntN(5) > 0 {
+			pools[i] = math.Pow(10, 6*r.Float64())
+		}
+	}
+	return pools
+}
+
+// randomAmount returns a bet between 1% and 100% of the pools, or up to 100 on empty pools
+func randomAmount(r *rand.Rand, pools []float64) float64 {
+	var total float64
+	for _, pool := range pools {
+		total += pool
+	}
+	return max(total, 100) * (0.01 + 0.99*r.Float64())
+}
+
+// saturated reports whether the price is too close to 0 or 1 to move measurably
+func saturated(price float64) bool {
+	return price < 1e-6 || price > 1-1e-6
+}
+
+func TestModelPriceProperties(t *testing.T) {
+	for _, model := range propertyModels {
+		r := newPropertyRand()
+		for range propertyRuns {
+			pools := randomPools(r)
+			checkBinaryPrices(t, model, Pool{Yes: pools[0], No: pools[1]})
+			before := checkPrices(t, model, pools)
+
+			outcome := r.IntN(len(pools))
+			amount := randomAmount(r, pools)
+			staked := append([]float64(nil), pools...)
+			staked[outcome] += amount
+			after := checkPrices(t, model, staked)
+			for i := range pools {
+				switch {
+				case i == outcome && after[i] < before[i]-priceTolerance:
+					t.Fatalf("%s: Prices(%v)[%d] fell from %v to %v after staking %v on it",
+						model.Name(), pools, i, before[i], after[i], amount)
+				case i == outcome && !saturated(before[i]) && after[i] <= before[i]:
+					t.Fatalf("%s: Prices(%v)[%d] = %v did not rise after staking %v on it",
+						model.Name(), pools, i, before[i], amount)
+				case i != outcome && after[i] > before[i]+priceTolerance:
+					t.Fatalf("%s: Prices(%v)[%d] rose from %v to %v after staking %v on outcome %d",
+						model.Name(), pools, i, before[i], after[i], amount, outcome)
+				}
+			}
+		}
+	}
+}
+
+func TestModelQuoteProperties(t *testing.T) {
+	for _, model := range propertyModels {
+		r := newPropertyRand()
+		for range propertyRuns {
+			pools := randomPools(r)
+			outcome := r.IntN(len(pools))
+			amount := randomAmount(r, pools)
+			quote, err := model.QuoteOutcome(pools, outcome, amount)
+			if errors.Is(err, ErrNoLiquidity) {
+				continue
+			} else if err != nil {
+				t.Fatalf("%s: QuoteOutcome(%v, %d, %v) error = %v", model.Name(), pools, outcome, amount, err)
+			}
+			// The average price is paid between the marginal prices before and after the bet
+			if !(quote.Shares > 0) || math.IsInf(quote.Shares, 0) ||
+				quote.PriceBefore < 0 || quote.PriceAfter > 1 || quote.PriceAfter < quote.PriceBefore ||
+				quote.AveragePrice < quote.PriceBefore*(1-priceTolerance) ||
+				quote.AveragePrice > quote.PriceAfter*(1+priceTolerance) {
+				t.Fatalf("%s: QuoteOutcome(%v, %d, %v) = %+v, want an average price between the prices before and after",
+					model.Name(), pools, outcome, amount, quote)
+			}
+			larger, err := model.QuoteOutcome(pools, outcome, 2*amount)
+			if err != nil {
+				t.Fatalf("%s: QuoteOutcome(%v, %d, %v) error = %v", model.Name(), pools, outcome, 2*amount, err)
+			}
+			if larger.Shares <= quote.Shares || larger.AveragePrice < quote.AveragePrice*(1-priceTolerance) {
+				t.Fatalf("%s: QuoteOutcome(%v, %d) = %+v for %v and %+v for twice as much, want more shares at a higher price",
+					model.Name(), pools, outcome, quote, amount, larger)
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package program
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/idl"
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+type Resolution uint8
+type Side uint8
+
+const (
+	ResolutionUnresolved Resolution = 0
+	ResolutionYes        Resolution = 1
+	ResolutionNo         Resolution = 2
+	ResolutionTie        Resolution = 3
+
+	SideYes Side = 0
+	SideNo  Side = 1
+)
+
+const (
+	discriminatorSize = 8
+
+	MarketAccountName   = "Market"
+	PositionAccountName = "Position"
+)
+
+var (
+	MarketDiscriminator   = bin.SighashTypeID(bin.SIGHASH_ACCOUNT_NAMESPACE, MarketAccountName)
+	PositionDiscriminator = bin.SighashTypeID(bin.SIGHASH_ACCOUNT_NAMESPACE, PositionAccountName)
+)
+
+var (
+	ErrUnknownAccount = errors.New("unknown account discriminator")
+	ErrNoIDL          = errors.New("program idl is not loaded")
+)
+
+// MarketAccount mirrors the Market account of the program
+type MarketAccount struct {
+	ID          string
+	Creator     solana.PublicKey
+	Resolver    solana.PublicKey
+	Title       string
+	OpenThrough int64
+	Resolution  Resolution
+	YesAmount   uint64
+	NoAmount    uint64
+}
+
+// PositionAccount mirrors the Position account of the program
+type PositionAccount struct {
+	Market    solana.PublicKey
+	Owner     solana.PublicKey
+	Side      Side
+	Amount    uint64
+	CreatedAt int64
+}
+
+// Decoder decodes accounts and instructions of our program. Discriminators are taken
+// from the program IDL when one is loaded, otherwise the Anchor defaults are assumed.
+type Decoder struct {
+	programID solana.PublicKey
+	idl       *idl.IDL
+}
+
+func NewDecoder(programID solana.PublicKey, programIDL *idl.IDL) *Decoder {
+	return &Decoder{programID: programID, idl: programIDL}
+}
+
+func (d *Decoder) IDL() *idl.IDL {
+	return d.idl
+}
+
+// DecodeAccount decodes account data into *MarketAccount or *PositionAccount
+func (d *Decoder) DecodeAccount(data []byte) (any, error) {
+	if d.idl == nil {
+		return DecodeAccount(data)
+	}
+	market := new(MarketAccount)
+	err := d.idl.DecodeAccountInto(MarketAccountName, data, market)
+	if err == nil {
+		return market, nil
+	} else if !errors.Is(err, idl.ErrUnknownDiscriminator) {
+		return nil, fmt.Errorf("decode market: %w", err)
+	}
+	position := new(PositionAccount)
+	err = d.idl.DecodeAccountInto(PositionAccountName, data, position)
+	if err == nil {
+		return position, nil
+	} else if !errors.Is(err, idl.ErrUnknownDiscriminator) {
+		return nil, fmt.Errorf("decode position: %w", err)
+	}
+	return nil, ErrUnknownAccount
+}
+
+// DecodeInstructions decodes every instruction of the transaction addressed to our program
+func (d *Decoder) DecodeInstructions(tx *solana.Transaction) ([]idl.DecodedInstruction, error) {
+	if d.idl == nil {
+		return nil, ErrNoIDL
+	}
+	var decoded []idl.DecodedInstruction
+	for _, ix := range tx.Message.Instructions {
+		programID, err := tx.Message.Program(ix.ProgramIDIndex)
+		if err != nil {
+			return nil, fmt.Errorf("resolve program: %w", err)
+		}
+		if !programID.Equals(d.programID) {
+			continue
+		}
+		accounts, err := ix.ResolveInstructionAccounts(&tx.Message)
+		if err != nil {
+			return nil, fmt.Errorf("resolve accounts: %w", err)
+		}
+		keys := make([]solana.PublicKey, len(accounts))
+		for i, account := range accounts {
+			keys[i] = account.PublicKey
+		}
+		instruction, err := d.idl.DecodeInstruction(ix.Data, keys)
+		if err != nil {
+			return nil, err
+		}
+		decoded = append(decoded, instruction)
+	}
+	return decoded, nil
+}
+
+// DecodeAccount decodes account data into *MarketAccount or *PositionAccount
+// depending on its default Anchor discriminator
+func DecodeAccount(data []byte) (any, error) {
+	if len(data) < discriminatorSize {
+		return nil, ErrUnknownAccount
+	}
+	var account any
+	switch discriminator := data[:discriminatorSize]; {
+	case bytes.Equal(discriminator, MarketDiscriminator[:]):
+		account = new(MarketAccount)
+	case bytes.Equal(discriminator, PositionDiscriminator[:]):
+		account = new(PositionAccount)
+	default:
+		return nil, ErrUnknownAccount
+	}
+	if err := bin.NewBorshDecoder(data[discriminatorSize:]).Decode(account); err != nil {
+		return nil, fmt.Errorf("decode account: %w", err)
+	}
+	return account, nil
+}
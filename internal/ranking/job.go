@@ -0,0 +1,55 @@
+package ranking
+
+import (
+	"context"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/leaderboard"
+	"github.com/IndexStorm/hit-my-bet-back/internal/scheduler"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// Job periodically materializes every leaderboard kind over every window
+type Job struct {
+	leaderboardRepo leaderboard.Repository
+	logger          zerolog.Logger
+	interval        time.Duration
+}
+
+func NewJob(leaderboardRepo leaderboard.Repository, logger zerolog.Logger, interval time.Duration) *Job {
+	return &Job{
+		leaderboardRepo: leaderboardRepo,
+		logger:          logger,
+		interval:        interval,
+	}
+}
+
+func (j *Job) Scheduled() []scheduler.Job {
+	return []scheduler.Job{
+		{Name: "materialize-leaderboards", Interval: j.interval, Run: j.Materialize},
+	}
+}
+
+// Materialize rebuilds every leaderboard, each one atomically so readers never see a partial ranking
+func (j *Job) Materialize(ctx context.Context) error {
+	now := time.Now()
+	for _, kind := range leaderboard.Kinds {
+		for _, window := range leaderboard.Windows {
+			var entries int64
+			err := j.leaderboardRepo.RunInTx(ctx, func(ctx context.Context) error {
+				var err error
+				entries, err = j.leaderboardRepo.Materialize(ctx, kind, window, window.Since(now), now)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("materialize %s/%s: %w", kind, window, err)
+			}
+			j.logger.Debug().
+				Str("kind", string(kind)).
+				Str("window", string(window)).
+				Int64("entries", entries).
+				Msg("leaderboard:materialized")
+		}
+	}
+	return nil
+}
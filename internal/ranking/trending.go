@@ -0,0 +1,63 @@
+package ranking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/config"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/trending"
+	"github.com/IndexStorm/hit-my-bet-back/internal/scheduler"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// Trending periodically recomputes the decayed popularity of open markets
+type Trending struct {
+	config       config.Trending
+	trendingRepo trending.Repository
+	logger       zerolog.Logger
+}
+
+func NewTrending(config config.Trending, trendingRepo trending.Repository, logger zerolog.Logger) *Trending {
+	return &Trending{
+		config:       config,
+		trendingRepo: trendingRepo,
+		logger:       logger,
+	}
+}
+
+func (t *Trending) Scheduled() []scheduler.Job {
+	return []scheduler.Job{
+		{Name: "refresh-trending", Interval: t.config.RefreshInterval, Run: t.Refresh},
+	}
+}
+
+// Refresh replaces every score atomically and drops view counts that left the window
+func (t *Trending) Refresh(ctx context.Context) error {
+	if t.config.HalfLife <= 0 {
+		return errors.New("trending half-life must be positive")
+	}
+	now := time.Now()
+	since := now.Add(-t.config.Window)
+	weights := trending.Weights{
+		Volume:   t.config.VolumeWeight,
+		Bettors:  t.config.BettorWeight,
+		Comments: t.config.CommentWeight,
+		Views:    t.config.ViewWeight,
+	}
+	var markets int64
+	err := t.trendingRepo.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		markets, err = t.trendingRepo.Refresh(ctx, weights, t.config.HalfLife, since, now)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("refresh trending: %w", err)
+	}
+	pruned, err := t.trendingRepo.PruneViews(ctx, since)
+	if err != nil {
+		return fmt.Errorf("prune views: %w", err)
+	}
+	t.logger.Debug().Int64("markets", markets).Int64("views", pruned).Msg("trending:refreshed")
+	return nil
+}
@@ -0,0 +1,29 @@
+package checkpoint
+
+import (
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+type Checkpoint struct {
+	Name      string    `db:"name" json:"name,omitempty"`
+	Slot      uint64    `db:"slot" json:"slot,omitempty"`
+	Signature string    `db:"signature" json:"signature,omitempty"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at,omitempty"`
+}
+
+// FailedTransaction is a program transaction the indexer failed to apply. It is retried
+// until it runs out of attempts, then dead-lettered and skipped by the checkpoint.
+type FailedTransaction struct {
+	Signature      string               `db:"signature" json:"signature"`
+	Slot           uint64               `db:"slot" json:"slot"`
+	Attempts       int32                `db:"attempts" json:"attempts"`
+	LastError      string               `db:"last_error" json:"last_error"`
+	FirstFailedAt  time.Time            `db:"first_failed_at" json:"first_failed_at"`
+	LastFailedAt   time.Time            `db:"last_failed_at" json:"last_failed_at"`
+	DeadLetteredAt zeronull.Timestamptz `db:"dead_lettered_at" json:"dead_lettered_at,omitempty"`
+}
+
+func (t FailedTransaction) DeadLettered() bool {
+	return !time.Time(t.DeadLetteredAt).IsZero()
+}
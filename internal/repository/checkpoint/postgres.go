@@ -0,0 +1,96 @@
+package checkpoint
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) GetCheckpoint(ctx context.Context, name string) (Checkpoint, error) {
+	const GetCheckpointQuery = `SELECT *
+FROM indexer.checkpoints
+WHERE
+  name = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetCheckpointQuery, name)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Checkpoint])
+}
+
+func (p *postgres) SaveCheckpoint(ctx context.Context, checkpoint Checkpoint) error {
+	const SaveCheckpointQuery = `INSERT INTO indexer.checkpoints
+(name,
+ slot,
+ signature,
+ updated_at)
+VALUES (@name,
+        @slot,
+        @signature,
+        @updated_at)
+ON CONFLICT (name) DO UPDATE
+  SET
+    slot       = excluded.slot,
+    signature  = excluded.signature,
+    updated_at = excluded.updated_at;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, SaveCheckpointQuery, pgx.NamedArgs{
+		"name":       checkpoint.Name,
+		"slot":       checkpoint.Slot,
+		"signature":  checkpoint.Signature,
+		"updated_at": checkpoint.UpdatedAt,
+	})
+	return err
+}
+
+func (p *postgres) RecordFailure(ctx context.Context, failure FailedTransaction, maxAttempts int32) (FailedTransaction, error) {
+	const RecordFailureQuery = `INSERT INTO indexer.failed_transactions AS f
+(signature,
+ slot,
+ attempts,
+ last_error,
+ first_failed_at,
+ last_failed_at,
+ dead_lettered_at)
+VALUES (@signature,
+        @slot,
+        1,
+        @last_error,
+        @failed_at,
+        @failed_at,
+        CASE WHEN @max_attempts::INTEGER <= 1 THEN @failed_at::TIMESTAMPTZ END)
+ON CONFLICT (signature) DO UPDATE
+  SET
+    attempts         = f.attempts + 1,
+    last_error       = excluded.last_error,
+    last_failed_at   = excluded.last_failed_at,
+    dead_lettered_at = CASE
+                         WHEN f.attempts + 1 >= @max_attempts::INTEGER THEN excluded.last_failed_at
+                         ELSE f.dead_lettered_at
+                       END
+RETURNING *;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, RecordFailureQuery, pgx.NamedArgs{
+		"signature":    failure.Signature,
+		"slot":         failure.Slot,
+		"last_error":   failure.LastError,
+		"failed_at":    failure.LastFailedAt,
+		"max_attempts": maxAttempts,
+	})
+	if err != nil {
+		return FailedTransaction{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[FailedTransaction])
+}
@@ -0,0 +1,16 @@
+package checkpoint
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	GetCheckpoint(ctx context.Context, name string) (Checkpoint, error)
+	SaveCheckpoint(ctx context.Context, checkpoint Checkpoint) error
+	// RecordFailure counts a failed attempt to apply the transaction and dead-letters it
+	// once maxAttempts is reached, returning the stored failure
+	RecordFailure(ctx context.Context, failure FailedTransaction, maxAttempts int32) (FailedTransaction, error)
+}
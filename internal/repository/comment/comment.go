@@ -0,0 +1,61 @@
+package comment
+
+import (
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusVisible Status = "VISIBLE"
+	// StatusHidden comments were hidden by moderation and are left out of threads
+	StatusHidden Status = "HIDDEN"
+	// StatusDeleted comments stay in threads without their body so replies keep their parent
+	StatusDeleted Status = "DELETED"
+)
+
+func (s Status) Valid() bool {
+	switch s {
+	case StatusVisible, StatusHidden, StatusDeleted:
+		return true
+	default:
+		return false
+	}
+}
+
+type Comment struct {
+	ID           string               `db:"id" json:"id"`
+	MarketID     string               `db:"market_id" json:"market_id"`
+	ParentID     zeronull.Text        `db:"parent_id" json:"parent_id,omitempty"`
+	AuthorPubkey string               `db:"author_pubkey" json:"author_pubkey"`
+	Body         string               `db:"body" json:"body"`
+	Status       Status               `db:"status" json:"status"`
+	CreatedAt    time.Time            `db:"created_at" json:"created_at"`
+	EditedAt     zeronull.Timestamptz `db:"edited_at" json:"edited_at,omitempty"`
+	DeletedAt    zeronull.Timestamptz `db:"deleted_at" json:"deleted_at,omitempty"`
+	// Replies counts the replies shown in the thread, Reactions the wallets per reaction
+	Replies   int64            `db:"replies" json:"replies"`
+	Reactions map[string]int64 `db:"reactions" json:"reactions"`
+}
+
+// Cursor points past the last comment of a page, comments are listed newest first
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+type Reaction struct {
+	CommentID     string    `db:"comment_id" json:"comment_id"`
+	ReactorPubkey string    `db:"reactor_pubkey" json:"reactor_pubkey"`
+	Reaction      string    `db:"reaction" json:"reaction"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+type Report struct {
+	ID             string    `db:"id" json:"id"`
+	CommentID      string    `db:"comment_id" json:"comment_id"`
+	ReporterPubkey string    `db:"reporter_pubkey" json:"reporter_pubkey"`
+	Reason         string    `db:"reason" json:"reason"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
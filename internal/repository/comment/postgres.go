@@ -0,0 +1,248 @@
+package comment
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+// commentColumns hides the body of deleted comments and counts visible replies and reactions
+const commentColumns = `c.id,
+       c.market_id,
+       c.parent_id,
+       c.author_pubkey,
+       CASE WHEN c.status = 'DELETED' THEN '' ELSE c.body END AS body,
+       c.status,
+       c.created_at,
+       c.edited_at,
+       c.deleted_at,
+       (SELECT count(*)
+        FROM prediction.comments r
+        WHERE r.parent_id = c.id AND r.status <> 'HIDDEN') AS replies,
+       coalesce((SELECT jsonb_object_agg(cr.reaction, cr.count)
+                 FROM (SELECT reaction, count(*) AS count
+                       FROM prediction.comment_reactions
+                       WHERE comment_id = c.id
+                       GROUP BY reaction) cr), '{}') AS reactions`
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) CreateComment(ctx context.Context, comment Comment) error {
+	const CreateCommentQuery = `INSERT INTO prediction.comments
+(id,
+ market_id,
+ parent_id,
+ author_pubkey,
+ body,
+ status,
+ created_at)
+VALUES (@id,
+        @market_id,
+        @parent_id,
+        @author_pubkey,
+        @body,
+        @status,
+        @created_at);`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, CreateCommentQuery, pgx.NamedArgs{
+		"id":            comment.ID,
+		"market_id":     comment.MarketID,
+		"parent_id":     comment.ParentID,
+		"author_pubkey": comment.AuthorPubkey,
+		"body":          comment.Body,
+		"status":        comment.Status,
+		"created_at":    comment.CreatedAt,
+	})
+	return err
+}
+
+func (p *postgres) GetComment(ctx context.Context, id string) (Comment, error) {
+	const GetCommentQuery = `SELECT ` + commentColumns + `
+FROM prediction.comments c
+WHERE
+  c.id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetCommentQuery, id)
+	if err != nil {
+		return Comment{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Comment])
+}
+
+func (p *postgres) ListComments(ctx context.Context, market, parent string, after *Cursor, limit int) ([]Comment, error) {
+	const ListCommentsQuery = `SELECT ` + commentColumns + `
+FROM prediction.comments c
+WHERE
+  c.market_id = @market_id
+  AND c.status <> 'HIDDEN'
+  AND ((@parent_id = '' AND c.parent_id IS NULL) OR c.parent_id = NULLIF(@parent_id, ''))
+  AND (NOT @paged OR (c.created_at, c.id) < (@after_created_at, @after_id))
+ORDER BY c.created_at DESC, c.id DESC
+LIMIT @limit;`
+	args := pgx.NamedArgs{
+		"market_id":        market,
+		"parent_id":        parent,
+		"paged":            after != nil,
+		"after_created_at": time.Time{},
+		"after_id":         "",
+		"limit":            limit,
+	}
+	if after != nil {
+		args["after_created_at"] = after.CreatedAt
+		args["after_id"] = after.ID
+	}
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListCommentsQuery, args)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Comment])
+}
+
+func (p *postgres) LockAuthor(ctx context.Context, author string) error {
+	const LockAuthorQuery = `SELECT pg_advisory_xact_lock(hashtextextended('comment:' || $1, 0));`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, LockAuthorQuery, author)
+	return err
+}
+
+func (p *postgres) CountRecent(ctx context.Context, author string, since time.Time) (int64, error) {
+	const CountRecentQuery = `SELECT count(*)
+FROM prediction.comments
+WHERE
+  author_pubkey = $1
+  AND created_at >= $2;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var count int64
+	err := conn.QueryRow(ctx, CountRecentQuery, author, since).Scan(&count)
+	return count, err
+}
+
+func (p *postgres) EditComment(ctx context.Context, id, body string, editedAt time.Time) error {
+	const EditCommentQuery = `UPDATE prediction.comments
+SET
+  body      = $2,
+  edited_at = $3
+WHERE
+  id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, EditCommentQuery, id, body, editedAt)
+	return err
+}
+
+func (p *postgres) DeleteComment(ctx context.Context, id string, deletedAt time.Time) error {
+	const DeleteCommentQuery = `UPDATE prediction.comments
+SET
+  status     = 'DELETED',
+  deleted_at = $2
+WHERE
+  id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, DeleteCommentQuery, id, deletedAt)
+	return err
+}
+
+func (p *postgres) SetStatus(ctx context.Context, id string, status Status) (Status, error) {
+	const SetStatusQuery = `UPDATE prediction.comments AS c
+SET
+  status = $2
+FROM (SELECT id, status
+      FROM prediction.comments
+      WHERE
+        id = $1
+        FOR UPDATE) AS previous
+WHERE
+  c.id = previous.id
+RETURNING previous.status;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var previous Status
+	err := conn.QueryRow(ctx, SetStatusQuery, id, status).Scan(&previous)
+	return previous, err
+}
+
+func (p *postgres) AddReaction(ctx context.Context, reaction Reaction) (bool, error) {
+	const AddReactionQuery = `INSERT INTO prediction.comment_reactions
+(comment_id,
+ reactor_pubkey,
+ reaction,
+ created_at)
+VALUES (@comment_id,
+        @reactor_pubkey,
+        @reaction,
+        @created_at)
+ON CONFLICT (comment_id, reactor_pubkey, reaction) DO NOTHING;`
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, AddReactionQuery, pgx.NamedArgs{
+		"comment_id":     reaction.CommentID,
+		"reactor_pubkey": reaction.ReactorPubkey,
+		"reaction":       reaction.Reaction,
+		"created_at":     reaction.CreatedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (p *postgres) RemoveReaction(ctx context.Context, reaction Reaction) (bool, error) {
+	const RemoveReactionQuery = `DELETE
+FROM prediction.comment_reactions
+WHERE
+  comment_id = $1
+  AND reactor_pubkey = $2
+  AND reaction = $3;`
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, RemoveReactionQuery, reaction.CommentID, reaction.ReactorPubkey, reaction.Reaction)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (p *postgres) CreateReport(ctx context.Context, report Report) (bool, error) {
+	const CreateReportQuery = `INSERT INTO prediction.comment_reports
+(id,
+ comment_id,
+ reporter_pubkey,
+ reason,
+ created_at)
+VALUES (@id,
+        @comment_id,
+        @reporter_pubkey,
+        @reason,
+        @created_at)
+ON CONFLICT (comment_id, reporter_pubkey) DO NOTHING;`
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, CreateReportQuery, pgx.NamedArgs{
+		"id":              report.ID,
+		"comment_id":      report.CommentID,
+		"reporter_pubkey": report.ReporterPubkey,
+		"reason":          report.Reason,
+		"created_at":      report.CreatedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (p *postgres) CountReports(ctx context.Context, comment string) (int64, error) {
+	const CountReportsQuery = `SELECT count(*)
+FROM prediction.comment_reports
+WHERE
+  comment_id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var count int64
+	err := conn.QueryRow(ctx, CountReportsQuery, comment).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,32 @@
+package comment
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"time"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	CreateComment(ctx context.Context, comment Comment) error
+	GetComment(ctx context.Context, id string) (Comment, error)
+	// ListComments returns a page of the thread under parent, top level comments of the
+	// market when parent is empty, starting after the cursor when it is set
+	ListComments(ctx context.Context, market, parent string, after *Cursor, limit int) ([]Comment, error)
+	// LockAuthor serializes the posting of comments by the wallet until the end of the
+	// transaction, run it in a transaction
+	LockAuthor(ctx context.Context, author string) error
+	// CountRecent counts comments the wallet posted since the given time
+	CountRecent(ctx context.Context, author string, since time.Time) (int64, error)
+	EditComment(ctx context.Context, id, body string, editedAt time.Time) error
+	DeleteComment(ctx context.Context, id string, deletedAt time.Time) error
+	// SetStatus changes the status of the comment and returns the previous one
+	SetStatus(ctx context.Context, id string, status Status) (Status, error)
+	// AddReaction returns false when the wallet already reacted the same way
+	AddReaction(ctx context.Context, reaction Reaction) (bool, error)
+	RemoveReaction(ctx context.Context, reaction Reaction) (bool, error)
+	// CreateReport stores the report and returns false when the wallet already reported the comment
+	CreateReport(ctx context.Context, report Report) (bool, error)
+	CountReports(ctx context.Context, comment string) (int64, error)
+}
@@ -0,0 +1,72 @@
+package dispute
+
+import (
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusProposed Status = "PROPOSED"
+	StatusDisputed Status = "DISPUTED"
+	StatusFinal    Status = "FINAL"
+
+	// FinalizerSystem finalizes proposals nobody challenged within the dispute period
+	FinalizerSystem = "system"
+)
+
+// Resolution is the off-chain lifecycle of a market outcome reported by its resolver.
+// The proposed outcome becomes final once the dispute period passes unchallenged,
+// a challenged one is final only after an arbiter rules on it.
+type Resolution struct {
+	MarketID        string                      `db:"market_id" json:"market_id"`
+	Status          Status                      `db:"status" json:"status"`
+	ProposedOutcome prediction.MarketResolution `db:"proposed_outcome" json:"proposed_outcome"`
+	FinalOutcome    prediction.MarketResolution `db:"final_outcome" json:"final_outcome"`
+	ProposedBy      string                      `db:"proposed_by" json:"proposed_by"`
+	ProposedAt      time.Time                   `db:"proposed_at" json:"proposed_at"`
+	DisputeDeadline time.Time                   `db:"dispute_deadline" json:"dispute_deadline"`
+	FinalizedBy     zeronull.Text               `db:"finalized_by" json:"finalized_by,omitempty"`
+	FinalizedAt     zeronull.Timestamptz        `db:"finalized_at" json:"finalized_at,omitempty"`
+	RulingNote      zeronull.Text               `db:"ruling_note" json:"ruling_note,omitempty"`
+	// Outcome ids name the outcome of OUTCOME resolutions of categorical markets
+	ProposedOutcomeID pgtype.Int2 `db:"proposed_outcome_id" json:"proposed_outcome_id"`
+	FinalOutcomeID    pgtype.Int2 `db:"final_outcome_id" json:"final_outcome_id"`
+	// Values are the answers of VALUE resolutions of scalar markets
+	ProposedValue pgtype.Float8 `db:"proposed_value" json:"proposed_value"`
+	FinalValue    pgtype.Float8 `db:"final_value" json:"final_value"`
+	// Settle failures of a final resolution, retried after the markets that settle cleanly
+	SettleAttempts int32                `db:"settle_attempts" json:"-"`
+	SettleError    zeronull.Text        `db:"settle_error" json:"-"`
+	SettleFailedAt zeronull.Timestamptz `db:"settle_failed_at" json:"-"`
+}
+
+func (r Resolution) Final() bool {
+	return r.Status == StatusFinal
+}
+
+type Challenge struct {
+	ID               string                      `db:"id" json:"id"`
+	MarketID         string                      `db:"market_id" json:"market_id"`
+	ChallengerPubkey string                      `db:"challenger_pubkey" json:"challenger_pubkey"`
+	Outcome          prediction.MarketResolution `db:"outcome" json:"outcome"`
+	Evidence         string                      `db:"evidence" json:"evidence"`
+	EvidenceURL      zeronull.Text               `db:"evidence_url" json:"evidence_url,omitempty"`
+	CreatedAt        time.Time                   `db:"created_at" json:"created_at"`
+	OutcomeID        pgtype.Int2                 `db:"outcome_id" json:"outcome_id"`
+	Value            pgtype.Float8               `db:"value" json:"value"`
+}
+
+// Ruling finalizes a resolution with the given outcome
+type Ruling struct {
+	MarketID    string
+	Outcome     prediction.MarketResolution
+	OutcomeID   pgtype.Int2
+	Value       pgtype.Float8
+	FinalizedBy string
+	FinalizedAt time.Time
+	Note        string
+}
@@ -0,0 +1,236 @@
+package dispute
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) ProposeResolution(ctx context.Context, resolution Resolution) (Resolution, error) {
+	const ProposeResolutionQuery = `INSERT INTO prediction.resolutions
+(market_id,
+ status,
+ proposed_outcome,
+ final_outcome,
+ proposed_by,
+ proposed_at,
+ dispute_deadline,
+ finalized_by,
+ finalized_at,
+ proposed_outcome_id,
+ final_outcome_id,
+ proposed_value,
+ final_value)
+VALUES (@market_id,
+        @status,
+        @proposed_outcome,
+        @final_outcome,
+        @proposed_by,
+        @proposed_at,
+        @dispute_deadline,
+        @finalized_by,
+        @finalized_at,
+        @proposed_outcome_id,
+        @final_outcome_id,
+        @proposed_value,
+        @final_value)
+ON CONFLICT (market_id) DO NOTHING;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, ProposeResolutionQuery, pgx.NamedArgs{
+		"market_id":        resolution.MarketID,
+		"status":           resolution.Status,
+		"proposed_outcome": resolution.ProposedOutcome,
+		"final_outcome":    resolution.FinalOutcome,
+		"proposed_by":      resolution.ProposedBy,
+		"proposed_at":      resolution.ProposedAt,
+		"dispute_deadline": resolution.DisputeDeadline,
+		"finalized_by":     resolution.FinalizedBy,
+		"finalized_at":     resolution.FinalizedAt,
+
+		"proposed_outcome_id": resolution.ProposedOutcomeID,
+		"final_outcome_id":    resolution.FinalOutcomeID,
+		"proposed_value":      resolution.ProposedValue,
+		"final_value":         resolution.FinalValue,
+	})
+	if err != nil {
+		return Resolution{}, err
+	}
+	return p.GetResolution(ctx, resolution.MarketID)
+}
+
+func (p *postgres) GetResolution(ctx context.Context, market string) (Resolution, error) {
+	const GetResolutionQuery = `SELECT *
+FROM prediction.resolutions
+WHERE
+  market_id = $1;`
+	return p.getResolution(ctx, GetResolutionQuery, market)
+}
+
+func (p *postgres) GetResolutionForUpdate(ctx context.Context, market string) (Resolution, error) {
+	const GetResolutionForUpdateQuery = `SELECT *
+FROM prediction.resolutions
+WHERE
+  market_id = $1
+  FOR UPDATE;`
+	return p.getResolution(ctx, GetResolutionForUpdateQuery, market)
+}
+
+func (p *postgres) getResolution(ctx context.Context, query, market string) (Resolution, error) {
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, query, market)
+	if err != nil {
+		return Resolution{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Resolution])
+}
+
+func (p *postgres) CreateChallenge(ctx context.Context, challenge Challenge) (bool, error) {
+	const CreateChallengeQuery = `INSERT INTO prediction.resolution_challenges
+(id,
+ market_id,
+ challenger_pubkey,
+ outcome,
+ evidence,
+ evidence_url,
+ created_at,
+ outcome_id,
+ value)
+VALUES (@id,
+        @market_id,
+        @challenger_pubkey,
+        @outcome,
+        @evidence,
+        @evidence_url,
+        @created_at,
+        @outcome_id,
+        @value)
+ON CONFLICT (market_id, challenger_pubkey) DO NOTHING;`
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, CreateChallengeQuery, pgx.NamedArgs{
+		"id":                challenge.ID,
+		"market_id":         challenge.MarketID,
+		"challenger_pubkey": challenge.ChallengerPubkey,
+		"outcome":           challenge.Outcome,
+		"evidence":          challenge.Evidence,
+		"evidence_url":      challenge.EvidenceURL,
+		"created_at":        challenge.CreatedAt,
+		"outcome_id":        challenge.OutcomeID,
+		"value":             challenge.Value,
+	})
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (p *postgres) ListChallenges(ctx context.Context, market string) ([]Challenge, error) {
+	const ListChallengesQuery = `SELECT *
+FROM prediction.resolution_challenges
+WHERE
+  market_id = $1
+ORDER BY created_at, id;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListChallengesQuery, market)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Challenge])
+}
+
+func (p *postgres) SetStatus(ctx context.Context, market string, status Status) error {
+	const SetStatusQuery = `UPDATE prediction.resolutions
+SET
+  status = $2
+WHERE
+  market_id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, SetStatusQuery, market, status)
+	return err
+}
+
+func (p *postgres) Finalize(ctx context.Context, ruling Ruling) error {
+	const FinalizeQuery = `UPDATE prediction.resolutions
+SET
+  status           = 'FINAL',
+  final_outcome    = @outcome,
+  final_outcome_id = @outcome_id,
+  final_value      = @value,
+  finalized_by     = @finalized_by,
+  finalized_at     = @finalized_at,
+  ruling_note      = NULLIF(@note, '')
+WHERE
+  market_id = @market_id;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, FinalizeQuery, pgx.NamedArgs{
+		"market_id":    ruling.MarketID,
+		"outcome":      ruling.Outcome,
+		"outcome_id":   ruling.OutcomeID,
+		"value":        ruling.Value,
+		"finalized_by": ruling.FinalizedBy,
+		"finalized_at": ruling.FinalizedAt,
+		"note":         ruling.Note,
+	})
+	return err
+}
+
+func (p *postgres) FinalizeExpired(ctx context.Context, now time.Time) ([]string, error) {
+	const FinalizeExpiredQuery = `UPDATE prediction.resolutions
+SET
+  status           = 'FINAL',
+  final_outcome    = proposed_outcome,
+  final_outcome_id = proposed_outcome_id,
+  final_value      = proposed_value,
+  finalized_by     = $2,
+  finalized_at     = $1
+WHERE
+  status = 'PROPOSED'
+  AND dispute_deadline <= $1
+RETURNING market_id;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, FinalizeExpiredQuery, now, FinalizerSystem)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (p *postgres) ListUnsettled(ctx context.Context, limit int) ([]string, error) {
+	const ListUnsettledQuery = `SELECT r.market_id
+FROM prediction.resolutions AS r
+WHERE
+  r.status = 'FINAL'
+  AND NOT EXISTS (SELECT 1 FROM prediction.settlements AS s WHERE s.market_id = r.market_id)
+ORDER BY r.settle_attempts, r.finalized_at
+LIMIT $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListUnsettledQuery, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (p *postgres) RecordSettleFailure(ctx context.Context, market string, reason string, now time.Time) error {
+	const RecordSettleFailureQuery = `UPDATE prediction.resolutions
+SET
+  settle_attempts  = settle_attempts + 1,
+  settle_error     = $2,
+  settle_failed_at = $3
+WHERE
+  market_id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, RecordSettleFailureQuery, market, reason, now)
+	return err
+}
@@ -0,0 +1,30 @@
+package dispute
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"time"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	// ProposeResolution stores the proposal unless the market already has one
+	// and returns the stored resolution
+	ProposeResolution(ctx context.Context, resolution Resolution) (Resolution, error)
+	GetResolution(ctx context.Context, market string) (Resolution, error)
+	// GetResolutionForUpdate locks the resolution until the end of the transaction
+	GetResolutionForUpdate(ctx context.Context, market string) (Resolution, error)
+	// CreateChallenge stores the challenge and returns false when the wallet already challenged the market
+	CreateChallenge(ctx context.Context, challenge Challenge) (bool, error)
+	ListChallenges(ctx context.Context, market string) ([]Challenge, error)
+	SetStatus(ctx context.Context, market string, status Status) error
+	Finalize(ctx context.Context, ruling Ruling) error
+	// FinalizeExpired finalizes unchallenged proposals whose dispute period ended
+	// with their proposed outcome and returns the finalized markets
+	FinalizeExpired(ctx context.Context, now time.Time) ([]string, error)
+	// ListUnsettled returns final resolutions that have no settlement yet, the ones that
+	// failed to settle the least often first
+	ListUnsettled(ctx context.Context, limit int) ([]string, error)
+	RecordSettleFailure(ctx context.Context, market string, reason string, now time.Time) error
+}
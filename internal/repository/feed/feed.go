@@ -0,0 +1,55 @@
+package feed
+
+import (
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+type Kind string
+type Operator string
+type Status string
+
+const (
+	// KindPyth reads a Pyth price account, Source is the account pubkey
+	KindPyth Kind = "PYTH"
+	// KindHTTP reads a number from a JSON document, Source is the URL and Path the field
+	KindHTTP Kind = "HTTP"
+
+	OperatorGT  Operator = "GT"
+	OperatorGTE Operator = "GTE"
+	OperatorLT  Operator = "LT"
+	OperatorLTE Operator = "LTE"
+
+	StatusPending  Status = "PENDING"
+	StatusResolved Status = "RESOLVED"
+	StatusFailed   Status = "FAILED"
+)
+
+func (o Operator) Valid() bool {
+	switch o {
+	case OperatorGT, OperatorGTE, OperatorLT, OperatorLTE:
+		return true
+	default:
+		return false
+	}
+}
+
+// Feed is the oracle a market declared at creation. Once the market closes the observed
+// value is compared with the threshold, the market resolves YES when the condition holds.
+type Feed struct {
+	MarketID      string                      `db:"market_id" json:"market_id"`
+	Kind          Kind                        `db:"kind" json:"kind"`
+	Source        string                      `db:"source" json:"source"`
+	Path          zeronull.Text               `db:"path" json:"path,omitempty"`
+	Operator      Operator                    `db:"operator" json:"operator"`
+	Threshold     float64                     `db:"threshold" json:"threshold"`
+	EvaluateAt    time.Time                   `db:"evaluate_at" json:"evaluate_at"`
+	Status        Status                      `db:"status" json:"status"`
+	Attempts      int32                       `db:"attempts" json:"attempts"`
+	LastError     zeronull.Text               `db:"last_error" json:"last_error,omitempty"`
+	ObservedValue zeronull.Float8             `db:"observed_value" json:"observed_value,omitempty"`
+	ObservedAt    zeronull.Timestamptz        `db:"observed_at" json:"observed_at,omitempty"`
+	Outcome       prediction.MarketResolution `db:"outcome" json:"outcome"`
+	ResolvedAt    zeronull.Timestamptz        `db:"resolved_at" json:"resolved_at,omitempty"`
+}
@@ -0,0 +1,121 @@
+package feed
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) CreateFeed(ctx context.Context, feed Feed) error {
+	const CreateFeedQuery = `INSERT INTO prediction.market_feeds
+(market_id,
+ kind,
+ source,
+ path,
+ operator,
+ threshold,
+ evaluate_at)
+VALUES (@market_id,
+        @kind,
+        @source,
+        @path,
+        @operator,
+        @threshold,
+        @evaluate_at);`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, CreateFeedQuery, pgx.NamedArgs{
+		"market_id":   feed.MarketID,
+		"kind":        feed.Kind,
+		"source":      feed.Source,
+		"path":        feed.Path,
+		"operator":    feed.Operator,
+		"threshold":   feed.Threshold,
+		"evaluate_at": feed.EvaluateAt,
+	})
+	return err
+}
+
+func (p *postgres) GetFeed(ctx context.Context, market string) (Feed, error) {
+	const GetFeedQuery = `SELECT *
+FROM prediction.market_feeds
+WHERE
+  market_id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetFeedQuery, market)
+	if err != nil {
+		return Feed{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Feed])
+}
+
+func (p *postgres) ListDue(ctx context.Context, now time.Time, limit int) ([]Feed, error) {
+	const ListDueQuery = `SELECT f.*
+FROM prediction.market_feeds f
+       JOIN prediction.markets m ON m.id = f.market_id
+WHERE
+  f.status = 'PENDING'
+  AND f.evaluate_at <= $1
+  AND m.resolution = 'UNRESOLVED'
+ORDER BY f.evaluate_at
+LIMIT $2;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListDueQuery, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Feed])
+}
+
+func (p *postgres) RecordObservation(
+	ctx context.Context,
+	market string,
+	value float64,
+	observedAt time.Time,
+	outcome prediction.MarketResolution,
+) error {
+	const RecordObservationQuery = `UPDATE prediction.market_feeds
+SET
+  status         = 'RESOLVED',
+  attempts       = attempts + 1,
+  last_error     = NULL,
+  observed_value = @value,
+  observed_at    = @observed_at,
+  outcome        = @outcome,
+  resolved_at    = now()
+WHERE
+  market_id = @market_id;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, RecordObservationQuery, pgx.NamedArgs{
+		"market_id":   market,
+		"value":       value,
+		"observed_at": observedAt,
+		"outcome":     outcome,
+	})
+	return err
+}
+
+func (p *postgres) RecordFailure(ctx context.Context, market string, reason string, maxAttempts int32) error {
+	const RecordFailureQuery = `UPDATE prediction.market_feeds
+SET
+  attempts   = attempts + 1,
+  last_error = $2,
+  status     = CASE WHEN attempts + 1 >= $3 THEN 'FAILED'::prediction.feed_status ELSE status END
+WHERE
+  market_id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, RecordFailureQuery, market, reason, maxAttempts)
+	return err
+}
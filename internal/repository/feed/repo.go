@@ -0,0 +1,26 @@
+package feed
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"time"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	CreateFeed(ctx context.Context, feed Feed) error
+	GetFeed(ctx context.Context, market string) (Feed, error)
+	// ListDue returns pending feeds of unresolved markets that are due for evaluation
+	ListDue(ctx context.Context, now time.Time, limit int) ([]Feed, error)
+	RecordObservation(
+		ctx context.Context,
+		market string,
+		value float64,
+		observedAt time.Time,
+		outcome prediction.MarketResolution,
+	) error
+	// RecordFailure counts a failed evaluation, the feed fails for good after maxAttempts
+	RecordFailure(ctx context.Context, market string, reason string, maxAttempts int32) error
+}
@@ -0,0 +1,68 @@
+package history
+
+import "time"
+
+type Interval string
+
+const (
+	IntervalMinute Interval = "1m"
+	IntervalHour   Interval = "1h"
+	IntervalDay    Interval = "1d"
+)
+
+var Intervals = []Interval{IntervalMinute, IntervalHour, IntervalDay}
+
+func (i Interval) Valid() bool {
+	switch i {
+	case IntervalMinute, IntervalHour, IntervalDay:
+		return true
+	default:
+		return false
+	}
+}
+
+func (i Interval) Duration() time.Duration {
+	switch i {
+	case IntervalHour:
+		return time.Hour
+	case IntervalDay:
+		return 24 * time.Hour
+	default:
+		return time.Minute
+	}
+}
+
+// truncUnit is the postgres date_trunc unit of the interval
+func (i Interval) truncUnit() string {
+	switch i {
+	case IntervalHour:
+		return "hour"
+	case IntervalDay:
+		return "day"
+	default:
+		return "minute"
+	}
+}
+
+// Tick is a snapshot of market pools. Volume is the amount staked since the previous tick.
+type Tick struct {
+	ID             int64     `db:"id" json:"-"`
+	MarketID       string    `db:"market_id" json:"market_id,omitempty"`
+	Time           time.Time `db:"time" json:"time"`
+	YesProbability float64   `db:"yes_probability" json:"yes_probability"`
+	YesAmount      int64     `db:"yes_amount" json:"yes_amount"`
+	NoAmount       int64     `db:"no_amount" json:"no_amount"`
+	Volume         int64     `db:"volume" json:"volume"`
+}
+
+// Candle aggregates the YES probability of a market over a bucket
+type Candle struct {
+	MarketID string    `db:"market_id" json:"-"`
+	Interval Interval  `db:"interval" json:"-"`
+	Bucket   time.Time `db:"bucket" json:"time"`
+	Open     float64   `db:"open" json:"open"`
+	High     float64   `db:"high" json:"high"`
+	Low      float64   `db:"low" json:"low"`
+	Close    float64   `db:"close" json:"close"`
+	Volume   int64     `db:"volume" json:"volume"`
+}
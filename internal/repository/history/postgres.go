@@ -0,0 +1,146 @@
+package history
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) RecordTick(ctx context.Context, tick Tick) error {
+	const RecordTickQuery = `INSERT INTO prediction.market_ticks
+(market_id,
+ time,
+ yes_probability,
+ yes_amount,
+ no_amount,
+ volume)
+SELECT @market_id::TEXT,
+       @time::TIMESTAMPTZ,
+       @yes_probability::DOUBLE PRECISION,
+       @yes_amount::BIGINT,
+       @no_amount::BIGINT,
+       GREATEST(@yes_amount::BIGINT + @no_amount::BIGINT - COALESCE(last.yes_amount + last.no_amount, 0), 0)
+FROM (SELECT 1) AS one
+  LEFT JOIN LATERAL (SELECT yes_amount, no_amount
+                     FROM prediction.market_ticks
+                     WHERE
+                       market_id = @market_id
+                     ORDER BY time DESC, id DESC
+                     LIMIT 1) AS last ON TRUE
+WHERE
+  last.yes_amount IS DISTINCT FROM @yes_amount::BIGINT
+  OR last.no_amount IS DISTINCT FROM @no_amount::BIGINT;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, RecordTickQuery, pgx.NamedArgs{
+		"market_id":       tick.MarketID,
+		"time":            tick.Time,
+		"yes_probability": tick.YesProbability,
+		"yes_amount":      tick.YesAmount,
+		"no_amount":       tick.NoAmount,
+	})
+	return err
+}
+
+func (p *postgres) RollupCandles(ctx context.Context, interval Interval, since time.Time) (int64, error) {
+	const RollupCandlesQuery = `INSERT INTO prediction.market_candles
+(market_id,
+ interval,
+ bucket,
+ open,
+ high,
+ low,
+ close,
+ volume)
+SELECT market_id,
+       @interval::TEXT,
+       date_trunc(@unit, time) AS bucket,
+       (array_agg(yes_probability ORDER BY time, id))[1],
+       max(yes_probability),
+       min(yes_probability),
+       (array_agg(yes_probability ORDER BY time DESC, id DESC))[1],
+       sum(volume)
+FROM prediction.market_ticks
+WHERE
+  time >= date_trunc(@unit, @since::TIMESTAMPTZ)
+GROUP BY market_id, bucket
+ON CONFLICT (market_id, interval, bucket) DO UPDATE
+  SET
+    open   = excluded.open,
+    high   = excluded.high,
+    low    = excluded.low,
+    close  = excluded.close,
+    volume = excluded.volume;`
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, RollupCandlesQuery, pgx.NamedArgs{
+		"interval": interval,
+		"unit":     interval.truncUnit(),
+		"since":    since,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (p *postgres) GetCandles(
+	ctx context.Context,
+	market string,
+	interval Interval,
+	stride time.Duration,
+	from, to time.Time,
+) ([]Candle, error) {
+	const GetCandlesQuery = `SELECT market_id,
+       interval,
+       date_bin(@stride_seconds * INTERVAL '1 second', bucket, TIMESTAMPTZ 'epoch') AS bucket,
+       (array_agg(open ORDER BY bucket))[1]        AS open,
+       max(high)                                   AS high,
+       min(low)                                    AS low,
+       (array_agg(close ORDER BY bucket DESC))[1]  AS close,
+       sum(volume)::BIGINT                         AS volume
+FROM prediction.market_candles
+WHERE
+  market_id = @market_id
+  AND interval = @interval
+  AND bucket >= @from
+  AND bucket < @to
+GROUP BY market_id, interval, 3
+ORDER BY 3;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetCandlesQuery, pgx.NamedArgs{
+		"market_id":      market,
+		"interval":       interval,
+		"stride_seconds": int64(stride.Seconds()),
+		"from":           from,
+		"to":             to,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Candle])
+}
+
+func (p *postgres) PruneCandles(ctx context.Context, interval Interval, before time.Time) (int64, error) {
+	const PruneCandlesQuery = `DELETE
+FROM prediction.market_candles
+WHERE
+  interval = $1
+  AND bucket < $2;`
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, PruneCandlesQuery, interval, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
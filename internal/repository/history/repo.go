@@ -0,0 +1,20 @@
+package history
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"time"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	// RecordTick stores the tick unless the market pools did not change since the last one
+	RecordTick(ctx context.Context, tick Tick) error
+	// RollupCandles rebuilds candles of the interval for every bucket starting at or after since
+	RollupCandles(ctx context.Context, interval Interval, since time.Time) (int64, error)
+	// GetCandles returns candles of the interval merged into buckets of stride
+	GetCandles(ctx context.Context, market string, interval Interval, stride time.Duration, from, to time.Time) ([]Candle, error)
+	// PruneCandles deletes candles of the interval whose bucket started before the given time
+	PruneCandles(ctx context.Context, interval Interval, before time.Time) (int64, error)
+}
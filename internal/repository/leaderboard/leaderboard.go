@@ -0,0 +1,63 @@
+package leaderboard
+
+import "time"
+
+type Kind string
+type Window string
+
+const (
+	KindPnL      Kind = "pnl"
+	KindVolume   Kind = "volume"
+	KindAccuracy Kind = "accuracy"
+
+	Window24h Window = "24h"
+	Window7d  Window = "7d"
+	WindowAll Window = "all"
+)
+
+var (
+	Kinds   = []Kind{KindPnL, KindVolume, KindAccuracy}
+	Windows = []Window{Window24h, Window7d, WindowAll}
+)
+
+func (k Kind) Valid() bool {
+	switch k {
+	case KindPnL, KindVolume, KindAccuracy:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w Window) Valid() bool {
+	switch w {
+	case Window24h, Window7d, WindowAll:
+		return true
+	default:
+		return false
+	}
+}
+
+// Since returns the start of the window, the zero time for all-time rankings
+func (w Window) Since(now time.Time) time.Time {
+	switch w {
+	case Window24h:
+		return now.Add(-24 * time.Hour)
+	case Window7d:
+		return now.AddDate(0, 0, -7)
+	default:
+		return time.Time{}
+	}
+}
+
+// Entry is the rank of a wallet. Value is realized PnL in lamports, staked volume in
+// lamports or the share of settled markets won, Markets the number of markets it covers.
+type Entry struct {
+	Kind        Kind      `db:"kind" json:"-"`
+	Window      Window    `db:"period" json:"-"`
+	OwnerPubkey string    `db:"owner_pubkey" json:"owner_pubkey"`
+	Value       float64   `db:"value" json:"value"`
+	Markets     int64     `db:"markets" json:"markets"`
+	Rank        int64     `db:"rank" json:"rank"`
+	ComputedAt  time.Time `db:"computed_at" json:"-"`
+}
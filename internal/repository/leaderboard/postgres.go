@@ -0,0 +1,141 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+// minAccuracyMarkets keeps wallets with a couple of lucky calls off the accuracy ranking
+const minAccuracyMarkets = 3
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) Materialize(ctx context.Context, kind Kind, window Window, since, now time.Time) (int64, error) {
+	const DeleteEntriesQuery = `DELETE
+FROM prediction.leaderboard_entries
+WHERE
+  kind = $1
+  AND period = $2;`
+	const PnLQuery = `SELECT sp.owner_pubkey,
+       sum(sp.payout - sp.stake)::DOUBLE PRECISION AS value,
+       count(*)                                    AS markets
+FROM prediction.settlement_payouts sp
+  JOIN prediction.settlements s ON s.market_id = sp.market_id
+WHERE
+  s.created_at >= @since
+GROUP BY sp.owner_pubkey`
+	const VolumeQuery = `SELECT owner_pubkey,
+       sum(amount)::DOUBLE PRECISION AS value,
+       count(DISTINCT market_id)     AS markets
+FROM prediction.positions
+WHERE
+  created_at >= @since
+GROUP BY owner_pubkey`
+	const AccuracyQuery = `SELECT sp.owner_pubkey,
+       avg((sp.payout > sp.stake)::INTEGER)::DOUBLE PRECISION AS value,
+       count(*)                                               AS markets
+FROM prediction.settlement_payouts sp
+  JOIN prediction.settlements s ON s.market_id = sp.market_id
+WHERE
+  s.created_at >= @since
+  AND s.resolution <> 'TIE'
+GROUP BY sp.owner_pubkey
+HAVING count(*) >= @min_markets`
+	const InsertEntriesQuery = `INSERT INTO prediction.leaderboard_entries
+(kind,
+ period,
+ owner_pubkey,
+ value,
+ markets,
+ rank,
+ computed_at)
+SELECT @kind::TEXT,
+       @window::TEXT,
+       owner_pubkey,
+       value,
+       markets,
+       rank() OVER (ORDER BY value DESC, markets DESC),
+       @computed_at::TIMESTAMPTZ
+FROM (%s) AS ranked;`
+	var source string
+	switch kind {
+	case KindPnL:
+		source = PnLQuery
+	case KindVolume:
+		source = VolumeQuery
+	case KindAccuracy:
+		source = AccuracyQuery
+	default:
+		return 0, fmt.Errorf("unknown leaderboard kind: %s", kind)
+	}
+	conn := p.GetConnectionFromCtx(ctx)
+	if _, err := conn.Exec(ctx, DeleteEntriesQuery, kind, window); err != nil {
+		return 0, err
+	}
+	tag, err := conn.Exec(ctx, fmt.Sprintf(InsertEntriesQuery, source), pgx.NamedArgs{
+		"kind":        kind,
+		"window":      window,
+		"since":       since,
+		"computed_at": now,
+		"min_markets": minAccuracyMarkets,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (p *postgres) GetEntries(ctx context.Context, kind Kind, window Window, limit, offset int) ([]Entry, error) {
+	const GetEntriesQuery = `SELECT *
+FROM prediction.leaderboard_entries
+WHERE
+  kind = $1
+  AND period = $2
+ORDER BY rank, owner_pubkey
+LIMIT $3 OFFSET $4;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetEntriesQuery, kind, window, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Entry])
+}
+
+func (p *postgres) CountEntries(ctx context.Context, kind Kind, window Window) (int64, error) {
+	const CountEntriesQuery = `SELECT count(*)
+FROM prediction.leaderboard_entries
+WHERE
+  kind = $1
+  AND period = $2;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var count int64
+	err := conn.QueryRow(ctx, CountEntriesQuery, kind, window).Scan(&count)
+	return count, err
+}
+
+func (p *postgres) GetEntry(ctx context.Context, kind Kind, window Window, owner string) (Entry, error) {
+	const GetEntryQuery = `SELECT *
+FROM prediction.leaderboard_entries
+WHERE
+  kind = $1
+  AND period = $2
+  AND owner_pubkey = $3;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetEntryQuery, kind, window, owner)
+	if err != nil {
+		return Entry{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Entry])
+}
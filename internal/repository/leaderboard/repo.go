@@ -0,0 +1,17 @@
+package leaderboard
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"time"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	// Materialize replaces the ranking of the kind and window with one computed from activity since the given time
+	Materialize(ctx context.Context, kind Kind, window Window, since, now time.Time) (int64, error)
+	GetEntries(ctx context.Context, kind Kind, window Window, limit, offset int) ([]Entry, error)
+	CountEntries(ctx context.Context, kind Kind, window Window) (int64, error)
+	GetEntry(ctx context.Context, kind Kind, window Window, owner string) (Entry, error)
+}
@@ -0,0 +1,77 @@
+package ledger
+
+import (
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+type Kind string
+type Source string
+type Status string
+type Period string
+
+const (
+	KindProtocol Kind = "PROTOCOL"
+	KindCreator  Kind = "CREATOR"
+
+	SourcePosition   Source = "POSITION"
+	SourceSettlement Source = "SETTLEMENT"
+
+	StatusAccrued Status = "ACCRUED"
+	StatusClaimed Status = "CLAIMED"
+
+	PeriodDay   Period = "day"
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+
+	DefaultCategory = "default"
+)
+
+func (p Period) Valid() bool {
+	switch p {
+	case PeriodDay, PeriodWeek, PeriodMonth:
+		return true
+	default:
+		return false
+	}
+}
+
+// Schedule holds the fees of a market category in basis points. Position fees are taken
+// from every stake, settlement fees from the losing pool of a resolved market.
+type Schedule struct {
+	Category              string    `db:"category" json:"category"`
+	PositionProtocolBps   int32     `db:"position_protocol_bps" json:"position_protocol_bps"`
+	PositionCreatorBps    int32     `db:"position_creator_bps" json:"position_creator_bps"`
+	SettlementProtocolBps int32     `db:"settlement_protocol_bps" json:"settlement_protocol_bps"`
+	SettlementCreatorBps  int32     `db:"settlement_creator_bps" json:"settlement_creator_bps"`
+	UpdatedAt             time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type Entry struct {
+	ID            int64                `db:"id" json:"id"`
+	MarketID      string               `db:"market_id" json:"market_id"`
+	CreatorPubkey string               `db:"creator_pubkey" json:"creator_pubkey"`
+	Kind          Kind                 `db:"kind" json:"kind"`
+	Source        Source               `db:"source" json:"source"`
+	SourceID      string               `db:"source_id" json:"source_id"`
+	Amount        int64                `db:"amount" json:"amount"`
+	Status        Status               `db:"status" json:"status"`
+	CreatedAt     time.Time            `db:"created_at" json:"created_at"`
+	ClaimedAt     zeronull.Timestamptz `db:"claimed_at" json:"claimed_at,omitempty"`
+}
+
+type ReportFilter struct {
+	// Creator limits the report to fees of markets created by the wallet when set
+	Creator string
+	Period  Period
+	From    time.Time
+	To      time.Time
+}
+
+// ReportRow sums the fees of a kind accrued over a period, Claimed is the part already claimed
+type ReportRow struct {
+	Period  time.Time `db:"period" json:"period"`
+	Kind    Kind      `db:"kind" json:"kind"`
+	Accrued int64     `db:"accrued" json:"accrued"`
+	Claimed int64     `db:"claimed" json:"claimed"`
+}
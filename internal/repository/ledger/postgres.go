@@ -0,0 +1,183 @@
+package ledger
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) GetSchedule(ctx context.Context, category string) (Schedule, error) {
+	const GetScheduleQuery = `SELECT *
+FROM fee.schedules
+WHERE
+  category IN ($1, $2)
+ORDER BY category = $1 DESC
+LIMIT 1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetScheduleQuery, category, DefaultCategory)
+	if err != nil {
+		return Schedule{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Schedule])
+}
+
+func (p *postgres) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	const ListSchedulesQuery = `SELECT *
+FROM fee.schedules
+ORDER BY category;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListSchedulesQuery)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Schedule])
+}
+
+func (p *postgres) SaveSchedule(ctx context.Context, schedule Schedule) error {
+	const SaveScheduleQuery = `INSERT INTO fee.schedules
+(category,
+ position_protocol_bps,
+ position_creator_bps,
+ settlement_protocol_bps,
+ settlement_creator_bps,
+ updated_at)
+VALUES (@category,
+        @position_protocol_bps,
+        @position_creator_bps,
+        @settlement_protocol_bps,
+        @settlement_creator_bps,
+        @updated_at)
+ON CONFLICT (category) DO UPDATE
+  SET
+    position_protocol_bps   = excluded.position_protocol_bps,
+    position_creator_bps    = excluded.position_creator_bps,
+    settlement_protocol_bps = excluded.settlement_protocol_bps,
+    settlement_creator_bps  = excluded.settlement_creator_bps,
+    updated_at              = excluded.updated_at;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, SaveScheduleQuery, pgx.NamedArgs{
+		"category":                schedule.Category,
+		"position_protocol_bps":   schedule.PositionProtocolBps,
+		"position_creator_bps":    schedule.PositionCreatorBps,
+		"settlement_protocol_bps": schedule.SettlementProtocolBps,
+		"settlement_creator_bps":  schedule.SettlementCreatorBps,
+		"updated_at":              schedule.UpdatedAt,
+	})
+	return err
+}
+
+func (p *postgres) UpsertEntry(ctx context.Context, entry Entry) error {
+	const UpsertEntryQuery = `INSERT INTO fee.ledger
+(market_id,
+ creator_pubkey,
+ kind,
+ source,
+ source_id,
+ amount,
+ status,
+ created_at)
+VALUES (@market_id,
+        @creator_pubkey,
+        @kind,
+        @source,
+        @source_id,
+        @amount,
+        @status,
+        @created_at)
+ON CONFLICT (source, source_id, kind) DO UPDATE
+  SET
+    amount = excluded.amount
+  WHERE
+    ledger.status = 'ACCRUED';`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, UpsertEntryQuery, pgx.NamedArgs{
+		"market_id":      entry.MarketID,
+		"creator_pubkey": entry.CreatorPubkey,
+		"kind":           entry.Kind,
+		"source":         entry.Source,
+		"source_id":      entry.SourceID,
+		"amount":         entry.Amount,
+		"status":         entry.Status,
+		"created_at":     entry.CreatedAt,
+	})
+	return err
+}
+
+func (p *postgres) GetPositionFees(ctx context.Context, market string) (map[string]int64, error) {
+	const GetPositionFeesQuery = `SELECT source_id,
+       sum(amount)::BIGINT
+FROM fee.ledger
+WHERE
+  market_id = $1
+  AND source = 'POSITION'
+GROUP BY source_id;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetPositionFeesQuery, market)
+	if err != nil {
+		return nil, err
+	}
+	fees := make(map[string]int64)
+	var position string
+	var amount int64
+	_, err = pgx.ForEachRow(rows, []any{&position, &amount}, func() error {
+		fees[position] = amount
+		return nil
+	})
+	return fees, err
+}
+
+func (p *postgres) ClaimCreatorFees(ctx context.Context, creator string, at time.Time) (int64, error) {
+	const ClaimCreatorFeesQuery = `WITH claimed AS (
+  UPDATE fee.ledger
+    SET
+      status = 'CLAIMED',
+      claimed_at = $2
+    WHERE
+      creator_pubkey = $1
+      AND kind = 'CREATOR'
+      AND status = 'ACCRUED'
+    RETURNING amount)
+SELECT coalesce(sum(amount), 0)::BIGINT
+FROM claimed;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var amount int64
+	err := conn.QueryRow(ctx, ClaimCreatorFeesQuery, creator, at).Scan(&amount)
+	return amount, err
+}
+
+func (p *postgres) GetReport(ctx context.Context, filter ReportFilter) ([]ReportRow, error) {
+	const GetReportQuery = `SELECT date_trunc(@period, created_at)                               AS period,
+       kind,
+       coalesce(sum(amount), 0)::BIGINT                                AS accrued,
+       coalesce(sum(amount) FILTER (WHERE status = 'CLAIMED'), 0)::BIGINT AS claimed
+FROM fee.ledger
+WHERE
+  (@creator = '' OR creator_pubkey = @creator)
+  AND created_at >= @from
+  AND created_at < @to
+GROUP BY 1, kind
+ORDER BY 1, kind;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetReportQuery, pgx.NamedArgs{
+		"period":  string(filter.Period),
+		"creator": filter.Creator,
+		"from":    filter.From,
+		"to":      filter.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[ReportRow])
+}
@@ -0,0 +1,23 @@
+package ledger
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"time"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	// GetSchedule returns the schedule of the category or the default one
+	GetSchedule(ctx context.Context, category string) (Schedule, error)
+	ListSchedules(ctx context.Context) ([]Schedule, error)
+	SaveSchedule(ctx context.Context, schedule Schedule) error
+	// UpsertEntry records a fee once per source and kind, updating its amount until claimed
+	UpsertEntry(ctx context.Context, entry Entry) error
+	// GetPositionFees returns the fees taken from each position of the market, keyed by position pubkey
+	GetPositionFees(ctx context.Context, market string) (map[string]int64, error)
+	// ClaimCreatorFees marks every accrued creator fee of the wallet claimed and returns their sum
+	ClaimCreatorFees(ctx context.Context, creator string, at time.Time) (int64, error)
+	GetReport(ctx context.Context, filter ReportFilter) ([]ReportRow, error)
+}
@@ -0,0 +1,46 @@
+package moderation
+
+import (
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+type Action string
+type TargetType string
+
+const (
+	ActionSetModeration  Action = "SET_MODERATION"
+	ActionAutoFlag       Action = "AUTO_FLAG"
+	ActionCreateCategory Action = "CREATE_CATEGORY"
+	ActionSetFeeSchedule Action = "SET_FEE_SCHEDULE"
+	ActionRuleResolution Action = "RULE_RESOLUTION"
+	ActionSetComment     Action = "SET_COMMENT"
+	ActionAutoHide       Action = "AUTO_HIDE"
+
+	TargetMarket      TargetType = "MARKET"
+	TargetCategory    TargetType = "CATEGORY"
+	TargetFeeSchedule TargetType = "FEE_SCHEDULE"
+	TargetComment     TargetType = "COMMENT"
+
+	// ActorSystem is the actor of actions taken automatically
+	ActorSystem = "system"
+)
+
+type Report struct {
+	ID             string        `db:"id" json:"id"`
+	MarketID       string        `db:"market_id" json:"market_id"`
+	ReporterPubkey string        `db:"reporter_pubkey" json:"reporter_pubkey"`
+	Reason         string        `db:"reason" json:"reason"`
+	Details        zeronull.Text `db:"details" json:"details,omitempty"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+}
+
+type AuditEntry struct {
+	ID         int64          `db:"id" json:"id"`
+	Actor      string         `db:"actor" json:"actor"`
+	Action     Action         `db:"action" json:"action"`
+	TargetType TargetType     `db:"target_type" json:"target_type"`
+	TargetID   string         `db:"target_id" json:"target_id"`
+	Details    map[string]any `db:"details" json:"details"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,139 @@
+package moderation
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) CreateReport(ctx context.Context, report Report) (bool, error) {
+	const CreateReportQuery = `INSERT INTO prediction.market_reports
+(id,
+ market_id,
+ reporter_pubkey,
+ reason,
+ details,
+ created_at)
+VALUES (@id,
+        @market_id,
+        @reporter_pubkey,
+        @reason,
+        @details,
+        @created_at)
+ON CONFLICT (market_id, reporter_pubkey) DO NOTHING;`
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, CreateReportQuery, pgx.NamedArgs{
+		"id":              report.ID,
+		"market_id":       report.MarketID,
+		"reporter_pubkey": report.ReporterPubkey,
+		"reason":          report.Reason,
+		"details":         report.Details,
+		"created_at":      report.CreatedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (p *postgres) CountReports(ctx context.Context, market string) (int64, error) {
+	const CountReportsQuery = `SELECT count(*)
+FROM prediction.market_reports
+WHERE
+  market_id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var count int64
+	err := conn.QueryRow(ctx, CountReportsQuery, market).Scan(&count)
+	return count, err
+}
+
+func (p *postgres) ListReports(ctx context.Context, market string) ([]Report, error) {
+	const ListReportsQuery = `SELECT *
+FROM prediction.market_reports
+WHERE
+  market_id = $1
+ORDER BY created_at DESC;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListReportsQuery, market)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Report])
+}
+
+func (p *postgres) SetModerationStatus(
+	ctx context.Context,
+	market string,
+	status prediction.ModerationStatus,
+) (prediction.ModerationStatus, error) {
+	const SetModerationStatusQuery = `UPDATE prediction.markets AS m
+SET
+  moderation_status = $2
+FROM (SELECT id, moderation_status
+      FROM prediction.markets
+      WHERE
+        id = $1
+        FOR UPDATE) AS previous
+WHERE
+  m.id = previous.id
+RETURNING previous.moderation_status;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var previous prediction.ModerationStatus
+	err := conn.QueryRow(ctx, SetModerationStatusQuery, market, status).Scan(&previous)
+	return previous, err
+}
+
+func (p *postgres) AddAuditEntry(ctx context.Context, entry AuditEntry) error {
+	const AddAuditEntryQuery = `INSERT INTO admin.audit_log
+(actor,
+ action,
+ target_type,
+ target_id,
+ details,
+ created_at)
+VALUES (@actor,
+        @action,
+        @target_type,
+        @target_id,
+        @details,
+        @created_at);`
+	details := entry.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, AddAuditEntryQuery, pgx.NamedArgs{
+		"actor":       entry.Actor,
+		"action":      entry.Action,
+		"target_type": entry.TargetType,
+		"target_id":   entry.TargetID,
+		"details":     details,
+		"created_at":  entry.CreatedAt,
+	})
+	return err
+}
+
+func (p *postgres) ListAuditEntries(ctx context.Context, limit, offset int) ([]AuditEntry, error) {
+	const ListAuditEntriesQuery = `SELECT *
+FROM admin.audit_log
+ORDER BY created_at DESC, id DESC
+LIMIT $1 OFFSET $2;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListAuditEntriesQuery, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[AuditEntry])
+}
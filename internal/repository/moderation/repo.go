@@ -0,0 +1,20 @@
+package moderation
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	// CreateReport stores the report and returns false when the wallet already reported the market
+	CreateReport(ctx context.Context, report Report) (bool, error)
+	CountReports(ctx context.Context, market string) (int64, error)
+	ListReports(ctx context.Context, market string) ([]Report, error)
+	// SetModerationStatus changes the status of the market and returns the previous one
+	SetModerationStatus(ctx context.Context, market string, status prediction.ModerationStatus) (prediction.ModerationStatus, error)
+	AddAuditEntry(ctx context.Context, entry AuditEntry) error
+	ListAuditEntries(ctx context.Context, limit, offset int) ([]AuditEntry, error)
+}
@@ -0,0 +1,54 @@
+package notification
+
+import (
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindMarketResolved  Kind = "MARKET_RESOLVED"
+	KindPositionSettled Kind = "POSITION_SETTLED"
+	KindCommentReply    Kind = "COMMENT_REPLY"
+	KindDisputeOpened   Kind = "DISPUTE_OPENED"
+	// KindResolutionOverdue reminds a resolver to propose the resolution of a closed market
+	KindResolutionOverdue Kind = "RESOLUTION_OVERDUE"
+)
+
+// Kinds lists every kind a wallet may opt out of
+var Kinds = []Kind{
+	KindMarketResolved,
+	KindPositionSettled,
+	KindCommentReply,
+	KindDisputeOpened,
+	KindResolutionOverdue,
+}
+
+func (k Kind) Valid() bool {
+	switch k {
+	case KindMarketResolved, KindPositionSettled, KindCommentReply, KindDisputeOpened, KindResolutionOverdue:
+		return true
+	default:
+		return false
+	}
+}
+
+// Notification tells a wallet about an event, SubjectID identifies the event so it
+// notifies each recipient once
+type Notification struct {
+	ID              string               `db:"id" json:"id"`
+	RecipientPubkey string               `db:"recipient_pubkey" json:"recipient_pubkey"`
+	Kind            Kind                 `db:"kind" json:"kind"`
+	SubjectID       string               `db:"subject_id" json:"subject_id"`
+	MarketID        zeronull.Text        `db:"market_id" json:"market_id,omitempty"`
+	Data            map[string]any       `db:"data" json:"data"`
+	CreatedAt       time.Time            `db:"created_at" json:"created_at"`
+	ReadAt          zeronull.Timestamptz `db:"read_at" json:"read_at,omitempty"`
+}
+
+// Cursor points past the last notification of a page, notifications are listed newest first
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
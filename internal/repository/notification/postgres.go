@@ -0,0 +1,181 @@
+package notification
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) CreateNotifications(ctx context.Context, notifications []Notification) error {
+	const CreateNotificationQuery = `INSERT INTO prediction.notifications
+(id,
+ recipient_pubkey,
+ kind,
+ subject_id,
+ market_id,
+ data,
+ created_at)
+SELECT @id::TEXT,
+       @recipient_pubkey::TEXT,
+       @kind::prediction.notification_kind,
+       @subject_id::TEXT,
+       @market_id::TEXT,
+       @data::JSONB,
+       @created_at::TIMESTAMPTZ
+WHERE
+  NOT EXISTS (SELECT 1
+              FROM prediction.notification_preferences np
+              WHERE
+                np.pubkey = @recipient_pubkey
+                AND np.kind = @kind::prediction.notification_kind
+                AND NOT np.enabled)
+ON CONFLICT DO NOTHING;`
+	if len(notifications) == 0 {
+		return nil
+	}
+	conn := p.GetConnectionFromCtx(ctx)
+	batch := &pgx.Batch{}
+	for _, notification := range notifications {
+		batch.Queue(CreateNotificationQuery, pgx.NamedArgs{
+			"id":               notification.ID,
+			"recipient_pubkey": notification.RecipientPubkey,
+			"kind":             notification.Kind,
+			"subject_id":       notification.SubjectID,
+			"market_id":        notification.MarketID,
+			"data":             notification.Data,
+			"created_at":       notification.CreatedAt,
+		})
+	}
+	return conn.SendBatch(ctx, batch).Close()
+}
+
+func (p *postgres) ListNotifications(
+	ctx context.Context,
+	recipient string,
+	unread bool,
+	after *Cursor,
+	limit int,
+) ([]Notification, error) {
+	const ListNotificationsQuery = `SELECT *
+FROM prediction.notifications
+WHERE
+  recipient_pubkey = @recipient
+  AND (NOT @unread OR read_at IS NULL)
+  AND (NOT @paged OR (created_at, id) < (@after_created_at, @after_id))
+ORDER BY created_at DESC, id DESC
+LIMIT @limit;`
+	args := pgx.NamedArgs{
+		"recipient":        recipient,
+		"unread":           unread,
+		"paged":            after != nil,
+		"after_created_at": time.Time{},
+		"after_id":         "",
+		"limit":            limit,
+	}
+	if after != nil {
+		args["after_created_at"] = after.CreatedAt
+		args["after_id"] = after.ID
+	}
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListNotificationsQuery, args)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Notification])
+}
+
+func (p *postgres) CountUnread(ctx context.Context, recipient string) (int64, error) {
+	const CountUnreadQuery = `SELECT count(*)
+FROM prediction.notifications
+WHERE
+  recipient_pubkey = $1
+  AND read_at IS NULL;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var count int64
+	err := conn.QueryRow(ctx, CountUnreadQuery, recipient).Scan(&count)
+	return count, err
+}
+
+func (p *postgres) MarkRead(ctx context.Context, recipient string, ids []string, readAt time.Time) (int64, error) {
+	const MarkReadQuery = `UPDATE prediction.notifications
+SET
+  read_at = $3
+WHERE
+  recipient_pubkey = $1
+  AND read_at IS NULL
+  AND (cardinality($2::TEXT[]) = 0 OR id = ANY ($2));`
+	if ids == nil {
+		ids = []string{}
+	}
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, MarkReadQuery, recipient, ids, readAt)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (p *postgres) GetPreferences(ctx context.Context, pubkey string) (map[Kind]bool, error) {
+	const GetPreferencesQuery = `SELECT kind, enabled
+FROM prediction.notification_preferences
+WHERE
+  pubkey = $1;`
+	preferences := make(map[Kind]bool, len(Kinds))
+	for _, kind := range Kinds {
+		preferences[kind] = true
+	}
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetPreferencesQuery, pubkey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			kind    Kind
+			enabled bool
+		)
+		if err = rows.Scan(&kind, &enabled); err != nil {
+			return nil, err
+		}
+		preferences[kind] = enabled
+	}
+	return preferences, rows.Err()
+}
+
+func (p *postgres) SetPreferences(ctx context.Context, pubkey string, preferences map[Kind]bool, updatedAt time.Time) error {
+	const SetPreferenceQuery = `INSERT INTO prediction.notification_preferences
+(pubkey,
+ kind,
+ enabled,
+ updated_at)
+VALUES ($1,
+        $2,
+        $3,
+        $4)
+ON CONFLICT (pubkey, kind) DO UPDATE
+  SET
+    enabled    = excluded.enabled,
+    updated_at = excluded.updated_at;`
+	if len(preferences) == 0 {
+		return nil
+	}
+	conn := p.GetConnectionFromCtx(ctx)
+	batch := &pgx.Batch{}
+	for kind, enabled := range preferences {
+		batch.Queue(SetPreferenceQuery, pubkey, kind, enabled, updatedAt)
+	}
+	return conn.SendBatch(ctx, batch).Close()
+}
@@ -0,0 +1,25 @@
+package notification
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"time"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	// CreateNotifications stores the notifications, skipping the ones whose recipient
+	// disabled their kind or already got them
+	CreateNotifications(ctx context.Context, notifications []Notification) error
+	// ListNotifications returns a page of the inbox of the wallet, starting after the
+	// cursor when it is set
+	ListNotifications(ctx context.Context, recipient string, unread bool, after *Cursor, limit int) ([]Notification, error)
+	CountUnread(ctx context.Context, recipient string) (int64, error)
+	// MarkRead marks the given notifications of the wallet as read, every unread one
+	// when ids is empty, and returns how many were marked
+	MarkRead(ctx context.Context, recipient string, ids []string, readAt time.Time) (int64, error)
+	// GetPreferences returns whether the wallet receives each kind, kinds are enabled by default
+	GetPreferences(ctx context.Context, pubkey string) (map[Kind]bool, error)
+	SetPreferences(ctx context.Context, pubkey string, preferences map[Kind]bool, updatedAt time.Time) error
+}
@@ -0,0 +1,18 @@
+package prediction
+
+import (
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+type Category struct {
+	Slug        string        `db:"slug" json:"slug"`
+	Name        string        `db:"name" json:"name"`
+	Description zeronull.Text `db:"description" json:"description,omitempty"`
+	CreatedAt   time.Time     `db:"created_at" json:"created_at"`
+}
+
+type TagCount struct {
+	Tag   string `db:"tag" json:"tag"`
+	Count int64  `db:"count" json:"count"`
+}
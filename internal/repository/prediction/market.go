@@ -1,12 +1,20 @@
 package prediction
 
 import (
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/profile"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"math"
 	"time"
 )
 
 type MarketChainStatus string
 type MarketResolution string
+type MarketStatus string
+type ModerationStatus string
+type ResolverStatus string
+type MarketKind string
 
 const (
 	MarketChainStatusPending   MarketChainStatus = "PENDING"
@@ -17,8 +25,38 @@ const (
 	MarketResolutionTie        MarketResolution = "TIE"
 	MarketResolutionYes        MarketResolution = "YES"
 	MarketResolutionNo         MarketResolution = "NO"
+	// MarketResolutionOutcome resolves a categorical market to Market.ResolvedOutcome
+	MarketResolutionOutcome MarketResolution = "OUTCOME"
+	// MarketResolutionValue resolves a scalar market to Market.ResolvedValue
+	MarketResolutionValue MarketResolution = "VALUE"
+
+	MarketStatusOpen     MarketStatus = "OPEN"
+	MarketStatusClosed   MarketStatus = "CLOSED"
+	MarketStatusResolved MarketStatus = "RESOLVED"
+
+	ModerationStatusVisible ModerationStatus = "VISIBLE"
+	ModerationStatusHidden  ModerationStatus = "HIDDEN"
+	ModerationStatusFlagged ModerationStatus = "FLAGGED"
+	ModerationStatusRemoved ModerationStatus = "REMOVED"
+
+	ResolverStatusPending  ResolverStatus = "PENDING"
+	ResolverStatusAccepted ResolverStatus = "ACCEPTED"
+
+	MarketKindBinary      MarketKind = "BINARY"
+	MarketKindCategorical MarketKind = "CATEGORICAL"
+	// MarketKindScalar markets trade LONG as YES and SHORT as NO on a numeric range
+	MarketKindScalar MarketKind = "SCALAR"
 )
 
+func (s ModerationStatus) Valid() bool {
+	switch s {
+	case ModerationStatusVisible, ModerationStatusHidden, ModerationStatusFlagged, ModerationStatusRemoved:
+		return true
+	default:
+		return false
+	}
+}
+
 type Market struct {
 	ID             string            `db:"id" json:"id,omitempty"`
 	ChainStatus    MarketChainStatus `db:"chain_status" json:"chain_status,omitempty"`
@@ -26,8 +64,116 @@ type Market struct {
 	Description    zeronull.Text     `db:"description" json:"description,omitempty"`
 	CreatorPubkey  string            `db:"creator_pubkey" json:"creator_pubkey,omitempty"`
 	ResolverPubkey string            `db:"resolver_pubkey" json:"resolver_pubkey,omitempty"`
-	MarketPubkey   string            `db:"market_pubkey" json:"market_pubkey,omitempty"`
+	MarketPubkey   zeronull.Text     `db:"market_pubkey" json:"market_pubkey,omitempty"`
 	Resolution     MarketResolution  `db:"resolution" json:"resolution,omitempty"`
 	CreatedAt      time.Time         `db:"created_at" json:"created_at,omitempty"`
 	OpenThrough    time.Time         `db:"open_through" json:"open_through,omitempty"`
+	YesAmount      int64             `db:"yes_amount" json:"yes_amount"`
+	NoAmount       int64             `db:"no_amount" json:"no_amount"`
+	Category       zeronull.Text     `db:"category" json:"category,omitempty"`
+	Tags           []string          `db:"tags" json:"tags"`
+	// ModerationStatus is independent of the chain, hidden and removed markets are left out of listings
+	ModerationStatus ModerationStatus `db:"moderation_status" json:"moderation_status,omitempty"`
+	// ResolverStatus is pending until a nominated third-party resolver accepts the market
+	ResolverStatus ResolverStatus `db:"resolver_status" json:"resolver_status,omitempty"`
+	// ClosedAt is set by the lifecycle scheduler once trading stops at OpenThrough
+	ClosedAt zeronull.Timestamptz `db:"closed_at" json:"closed_at,omitempty"`
+	Kind     MarketKind           `db:"kind" json:"kind,omitempty"`
+	// Outcomes of categorical markets, indexed by their id
+	Outcomes        []MarketOutcome `db:"outcomes" json:"outcomes,omitempty"`
+	ResolvedOutcome pgtype.Int2     `db:"resolved_outcome" json:"resolved_outcome"`
+	// Bounds of the range of scalar markets
+	ScalarLower   pgtype.Float8 `db:"scalar_lower" json:"scalar_lower"`
+	ScalarUpper   pgtype.Float8 `db:"scalar_upper" json:"scalar_upper"`
+	ResolvedValue pgtype.Float8 `db:"resolved_value" json:"resolved_value"`
+	// CreatorProfile is set when the creator has a profile
+	CreatorProfile *profile.Summary `db:"creator_profile" json:"creator_profile,omitempty"`
+}
+
+// MarketOutcome is a named outcome of a categorical market with the amount staked on it
+type MarketOutcome struct {
+	ID     int16  `json:"id"`
+	Name   string `json:"name"`
+	Amount int64  `json:"amount"`
+}
+
+// ValidResolution reports whether the market can resolve to the resolution, outcome is
+// the winning outcome of OUTCOME resolutions and value the answer of VALUE resolutions.
+// Any market may resolve to TIE.
+func (m Market) ValidResolution(resolution MarketResolution, outcome pgtype.Int2, value pgtype.Float8) bool {
+	switch resolution {
+	case MarketResolutionTie:
+		return !outcome.Valid && !value.Valid
+	case MarketResolutionYes, MarketResolutionNo:
+		return m.Binary() && !outcome.Valid && !value.Valid
+	case MarketResolutionOutcome:
+		return m.Kind == MarketKindCategorical && outcome.Valid && !value.Valid &&
+			outcome.Int16 >= 0 && int(outcome.Int16) < len(m.Outcomes)
+	case MarketResolutionValue:
+		return m.Kind == MarketKindScalar && value.Valid && !outcome.Valid &&
+			!math.IsNaN(value.Float64) && !math.IsInf(value.Float64, 0)
+	default:
+		return false
+	}
+}
+
+// Binary markets are resolved on chain, other kinds by their resolver through the API
+func (m Market) Binary() bool {
+	return m.Kind == MarketKindBinary || m.Kind == ""
+}
+
+// OutcomeAmounts returns the amounts staked on each outcome of a categorical market
+func (m Market) OutcomeAmounts() []float64 {
+	amounts := make([]float64, len(m.Outcomes))
+	for i, outcome := range m.Outcomes {
+		amounts[i] = float64(outcome.Amount)
+	}
+	return amounts
+}
+
+// MarketFilter narrows market listings, zero fields match every market except
+// that hidden and removed markets are only listed when asked for explicitly
+type MarketFilter struct {
+	Status        MarketStatus
+	Creator       string
+	Category      string
+	Tag           string
+	Moderation    ModerationStatus
+	IncludeHidden bool
+}
+
+func (f MarketFilter) Valid() bool {
+	switch f.Status {
+	case "", MarketStatusOpen, MarketStatusClosed, MarketStatusResolved:
+	default:
+		return false
+	}
+	return f.Moderation == "" || f.Moderation.Valid()
+}
+
+func (f MarketFilter) namedArgs() pgx.NamedArgs {
+	return pgx.NamedArgs{
+		"status":   string(f.Status),
+		"creator":  f.Creator,
+		"category": f.Category,
+		"tag":      f.Tag,
+
+		"moderation":     string(f.Moderation),
+		"include_hidden": f.IncludeHidden,
+	}
+}
+
+// TrendingMarket is a market with its popularity as of the last trending refresh
+type TrendingMarket struct {
+	Market
+	TrendingScore float64   `db:"trending_score" json:"trending_score"`
+	ScoredAt      time.Time `db:"scored_at" json:"scored_at"`
+}
+
+// MarketSearchResult highlights are HTML escaped with matches wrapped in <mark> tags
+type MarketSearchResult struct {
+	Market
+	Rank                 float64 `db:"rank" json:"rank"`
+	TitleHighlight       string  `db:"title_highlight" json:"title_highlight"`
+	DescriptionHighlight string  `db:"description_highlight" json:"description_highlight,omitempty"`
 }
@@ -0,0 +1,40 @@
+package prediction
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+type PositionSide string
+
+const (
+	PositionSideYes PositionSide = "YES"
+	PositionSideNo  PositionSide = "NO"
+)
+
+type Position struct {
+	ID             string       `db:"id" json:"id,omitempty"`
+	MarketID       string       `db:"market_id" json:"market_id,omitempty"`
+	PositionPubkey string       `db:"position_pubkey" json:"position_pubkey,omitempty"`
+	OwnerPubkey    string       `db:"owner_pubkey" json:"owner_pubkey,omitempty"`
+	Side           PositionSide `db:"side" json:"side,omitempty"`
+	Amount         int64        `db:"amount" json:"amount"`
+	CreatedAt      time.Time    `db:"created_at" json:"created_at,omitempty"`
+	// OutcomeID is the outcome backed by a position in a categorical market, Side is YES
+	OutcomeID pgtype.Int2 `db:"outcome_id" json:"outcome_id"`
+}
+
+// OwnerPosition is a position of a wallet together with the state of its market and the
+// YES probability of the market when the position was opened
+type OwnerPosition struct {
+	Position
+	MarketTitle         string           `db:"market_title"`
+	MarketResolution    MarketResolution `db:"market_resolution"`
+	MarketYesAmount     int64            `db:"market_yes_amount"`
+	MarketNoAmount      int64            `db:"market_no_amount"`
+	EntryYesProbability zeronull.Float8  `db:"entry_yes_probability"`
+	MarketKind          MarketKind       `db:"market_kind"`
+	// MarketOutcomeAmounts are the amounts staked on each outcome of a categorical market
+	MarketOutcomeAmounts []int64 `db:"market_outcome_amounts"`
+}
@@ -5,8 +5,59 @@ import (
 	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
 )
 
+// marketColumns lists the columns of Market, markets also carry columns that are
+// only used for querying such as the search vector
+const marketColumns = `id,
+       chain_status,
+       title,
+       description,
+       creator_pubkey,
+       resolver_pubkey,
+       market_pubkey,
+       resolution,
+       created_at,
+       open_through,
+       yes_amount,
+       no_amount,
+       category,
+       coalesce((SELECT array_agg(mt.tag ORDER BY mt.tag)
+                 FROM prediction.market_tags mt
+                 WHERE mt.market_id = markets.id), '{}') AS tags,
+       moderation_status,
+       resolver_status,
+       closed_at,
+       kind,
+       coalesce((SELECT jsonb_agg(jsonb_build_object('id', mo.outcome_id, 'name', mo.name, 'amount', mo.amount)
+                                 ORDER BY mo.outcome_id)
+                 FROM prediction.market_outcomes mo
+                 WHERE mo.market_id = markets.id), '[]') AS outcomes,
+       resolved_outcome,
+       scalar_lower,
+       scalar_upper,
+       resolved_value,
+       (SELECT jsonb_build_object('pubkey', pr.pubkey,
+                                  'display_name', pr.display_name,
+                                  'avatar_url', pr.avatar_url,
+                                  'sns_name', pr.sns_name)
+        FROM prediction.profiles pr
+        WHERE pr.pubkey = markets.creator_pubkey) AS creator_profile`
+
+// marketFilterCondition applies MarketFilter
+const marketFilterCondition = `(@status = ''
+    OR (@status = 'OPEN' AND resolution = 'UNRESOLVED' AND open_through > now())
+    OR (@status = 'CLOSED' AND resolution = 'UNRESOLVED' AND open_through <= now())
+    OR (@status = 'RESOLVED' AND resolution <> 'UNRESOLVED'))
+  AND (@creator = '' OR creator_pubkey = @creator)
+  AND (@category = '' OR category = @category)
+  AND (@tag = '' OR EXISTS (SELECT 1
+                            FROM prediction.market_tags mt
+                            WHERE mt.market_id = markets.id AND mt.tag = @tag))
+  AND (moderation_status::TEXT = @moderation
+    OR (@moderation = '' AND (@include_hidden::BOOLEAN OR moderation_status IN ('VISIBLE', 'FLAGGED'))))`
+
 type postgres struct {
 	db.BaseRepository
 }
@@ -24,19 +75,29 @@ func (p *postgres) CreateMarket(ctx context.Context, market Market) error {
  title,
  creator_pubkey,
  resolver_pubkey,
+ resolver_status,
+ kind,
+ scalar_lower,
+ scalar_upper,
  resolution,
  description,
  created_at,
- open_through)
+ open_through,
+ category)
 VALUES (@id,
         @chain_status,
         @title,
         @creator_pubkey,
         @resolver_pubkey,
+        @resolver_status,
+        @kind,
+        @scalar_lower,
+        @scalar_upper,
         @resolution,
         @description,
         @created_at,
-        @open_through);`
+        @open_through,
+        @category);`
 	conn := p.GetConnectionFromCtx(ctx)
 	_, err := conn.Exec(ctx, CreateMarketQuery, pgx.NamedArgs{
 		"id":              market.ID,
@@ -45,10 +106,54 @@ VALUES (@id,
 		"description":     market.Description,
 		"creator_pubkey":  market.CreatorPubkey,
 		"resolver_pubkey": market.ResolverPubkey,
+		"resolver_status": market.ResolverStatus,
+		"kind":            market.Kind,
+		"scalar_lower":    market.ScalarLower,
+		"scalar_upper":    market.ScalarUpper,
 		"resolution":      market.Resolution,
 		"created_at":      market.CreatedAt,
 		"open_through":    market.OpenThrough,
+		"category":        market.Category,
 	})
+	if err != nil {
+		return err
+	}
+	if err = p.addMarketOutcomes(ctx, market.ID, market.Outcomes); err != nil {
+		return err
+	}
+	return p.addMarketTags(ctx, market.ID, market.Tags)
+}
+
+func (p *postgres) addMarketOutcomes(ctx context.Context, market string, outcomes []MarketOutcome) error {
+	const AddMarketOutcomeQuery = `INSERT INTO prediction.market_outcomes
+(market_id,
+ outcome_id,
+ name)
+VALUES ($1,
+        $2,
+        $3);`
+	if len(outcomes) == 0 {
+		return nil
+	}
+	conn := p.GetConnectionFromCtx(ctx)
+	batch := &pgx.Batch{}
+	for _, outcome := range outcomes {
+		batch.Queue(AddMarketOutcomeQuery, market, outcome.ID, outcome.Name)
+	}
+	return conn.SendBatch(ctx, batch).Close()
+}
+
+func (p *postgres) addMarketTags(ctx context.Context, market string, tags []string) error {
+	const AddMarketTagsQuery = `INSERT INTO prediction.market_tags
+(market_id,
+ tag)
+SELECT $1, unnest($2::TEXT[])
+ON CONFLICT DO NOTHING;`
+	if len(tags) == 0 {
+		return nil
+	}
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, AddMarketTagsQuery, market, tags)
 	return err
 }
 
@@ -62,3 +167,494 @@ WHERE
 	_, err := conn.Exec(ctx, SetMarketInitializedQuery, MarketChainStatusConfirmed, market)
 	return err
 }
+
+func (p *postgres) SetMarketChainStatus(ctx context.Context, market string, status MarketChainStatus) error {
+	const SetMarketChainStatusQuery = `UPDATE prediction.markets
+SET
+  chain_status = $1
+WHERE
+  id = $2;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, SetMarketChainStatusQuery, status, market)
+	return err
+}
+
+func (p *postgres) GetMarket(ctx context.Context, id string) (Market, error) {
+	const GetMarketQuery = `SELECT ` + marketColumns + `
+FROM prediction.markets
+WHERE
+  id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetMarketQuery, id)
+	if err != nil {
+		return Market{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Market])
+}
+
+func (p *postgres) GetMarketByPubkey(ctx context.Context, pubkey string) (Market, error) {
+	const GetMarketByPubkeyQuery = `SELECT ` + marketColumns + `
+FROM prediction.markets
+WHERE
+  market_pubkey = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetMarketByPubkeyQuery, pubkey)
+	if err != nil {
+		return Market{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Market])
+}
+
+func (p *postgres) UpsertChainMarket(ctx context.Context, market Market) error {
+	const UpsertChainMarketQuery = `INSERT INTO prediction.markets
+(id,
+ chain_status,
+ title,
+ creator_pubkey,
+ resolver_pubkey,
+ market_pubkey,
+ resolution,
+ created_at,
+ open_through,
+ yes_amount,
+ no_amount)
+VALUES (@id,
+        @chain_status,
+        @title,
+        @creator_pubkey,
+        @resolver_pubkey,
+        @market_pubkey,
+        @resolution,
+        @created_at,
+        @open_through,
+        @yes_amount,
+        @no_amount)
+ON CONFLICT (id) DO UPDATE
+  SET
+    chain_status    = excluded.chain_status,
+    market_pubkey   = excluded.market_pubkey,
+    -- Binary markets are resolved on chain by the resolver stored in their account
+    resolver_pubkey = CASE WHEN markets.kind = 'BINARY' THEN excluded.resolver_pubkey ELSE markets.resolver_pubkey END,
+    -- Categorical and scalar markets are resolved by their resolver through the API
+    resolution      = CASE WHEN markets.kind = 'BINARY' THEN excluded.resolution ELSE markets.resolution END,
+    yes_amount      = excluded.yes_amount,
+    no_amount       = excluded.no_amount
+  -- Accounts carrying the id of a market owned by another creator or account are not applied
+  WHERE
+    markets.creator_pubkey = excluded.creator_pubkey
+    AND (markets.market_pubkey IS NULL OR markets.market_pubkey = excluded.market_pubkey)
+RETURNING id;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var id string
+	return conn.QueryRow(ctx, UpsertChainMarketQuery, pgx.NamedArgs{
+		"id":              market.ID,
+		"chain_status":    market.ChainStatus,
+		"title":           market.Title,
+		"creator_pubkey":  market.CreatorPubkey,
+		"resolver_pubkey": market.ResolverPubkey,
+		"market_pubkey":   market.MarketPubkey,
+		"resolution":      market.Resolution,
+		"created_at":      market.CreatedAt,
+		"open_through":    market.OpenThrough,
+		"yes_amount":      market.YesAmount,
+		"no_amount":       market.NoAmount,
+	}).Scan(&id)
+}
+
+func (p *postgres) UpsertPosition(ctx context.Context, position Position) error {
+	const UpsertPositionQuery = `INSERT INTO prediction.positions
+(id,
+ market_id,
+ position_pubkey,
+ owner_pubkey,
+ side,
+ amount,
+ created_at,
+ outcome_id)
+VALUES (@id,
+        @market_id,
+        @position_pubkey,
+        @owner_pubkey,
+        @side,
+        @amount,
+        @created_at,
+        @outcome_id)
+ON CONFLICT (position_pubkey) DO UPDATE
+  SET
+    side       = excluded.side,
+    amount     = excluded.amount,
+    outcome_id = excluded.outcome_id;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, UpsertPositionQuery, pgx.NamedArgs{
+		"id":              position.ID,
+		"market_id":       position.MarketID,
+		"position_pubkey": position.PositionPubkey,
+		"owner_pubkey":    position.OwnerPubkey,
+		"side":            position.Side,
+		"amount":          position.Amount,
+		"created_at":      position.CreatedAt,
+		"outcome_id":      position.OutcomeID,
+	})
+	return err
+}
+
+func (p *postgres) GetMarketPositions(ctx context.Context, market string) ([]Position, error) {
+	const GetMarketPositionsQuery = `SELECT *
+FROM prediction.positions
+WHERE
+  market_id = $1
+ORDER BY created_at, id;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetMarketPositionsQuery, market)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Position])
+}
+
+func (p *postgres) GetSettlement(ctx context.Context, market string) (Settlement, error) {
+	const GetSettlementQuery = `SELECT *
+FROM prediction.settlements
+WHERE
+  market_id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetSettlementQuery, market)
+	if err != nil {
+		return Settlement{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Settlement])
+}
+
+func (p *postgres) GetSettlementPayouts(ctx context.Context, market string) ([]Payout, error) {
+	const GetSettlementPayoutsQuery = `SELECT *
+FROM prediction.settlement_payouts
+WHERE
+  market_id = $1
+ORDER BY payout DESC, owner_pubkey;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetSettlementPayoutsQuery, market)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Payout])
+}
+
+func (p *postgres) SaveSettlement(ctx context.Context, settlement Settlement, payouts []Payout) error {
+	const SaveSettlementQuery = `INSERT INTO prediction.settlements
+(market_id,
+ resolution,
+ total_pool,
+ winning_pool,
+ fee_bps,
+ fee_amount,
+ created_at,
+ creator_fee_bps,
+ creator_fee_amount,
+ winning_outcome_id,
+ resolved_value)
+VALUES (@market_id,
+        @resolution,
+        @total_pool,
+        @winning_pool,
+        @fee_bps,
+        @fee_amount,
+        @created_at,
+        @creator_fee_bps,
+        @creator_fee_amount,
+        @winning_outcome_id,
+        @resolved_value)
+ON CONFLICT (market_id) DO NOTHING;`
+	const SavePayoutQuery = `INSERT INTO prediction.settlement_payouts
+(market_id,
+ owner_pubkey,
+ stake,
+ payout)
+VALUES (@market_id,
+        @owner_pubkey,
+        @stake,
+        @payout);`
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, SaveSettlementQuery, pgx.NamedArgs{
+		"market_id":    settlement.MarketID,
+		"resolution":   settlement.Resolution,
+		"total_pool":   settlement.TotalPool,
+		"winning_pool": settlement.WinningPool,
+		"fee_bps":      settlement.FeeBps,
+		"fee_amount":   settlement.FeeAmount,
+		"created_at":   settlement.CreatedAt,
+
+		"creator_fee_bps":    settlement.CreatorFeeBps,
+		"creator_fee_amount": settlement.CreatorFeeAmount,
+		"winning_outcome_id": settlement.WinningOutcomeID,
+		"resolved_value":     settlement.ResolvedValue,
+	})
+	if err != nil || tag.RowsAffected() == 0 {
+		return err
+	}
+	batch := &pgx.Batch{}
+	for _, payout := range payouts {
+		batch.Queue(SavePayoutQuery, pgx.NamedArgs{
+			"market_id":    payout.MarketID,
+			"owner_pubkey": payout.OwnerPubkey,
+			"stake":        payout.Stake,
+			"payout":       payout.Payout,
+		})
+	}
+	return conn.SendBatch(ctx, batch).Close()
+}
+
+func (p *postgres) GetClaims(ctx context.Context, owner string) ([]Claim, error) {
+	const GetClaimsQuery = `SELECT sp.market_id,
+       m.market_pubkey,
+       s.resolution,
+       sp.owner_pubkey,
+       sp.stake,
+       sp.payout,
+       coalesce(array_agg(p.position_pubkey ORDER BY p.created_at) FILTER (WHERE p.id IS NOT NULL),
+                '{}') AS position_pubkeys
+FROM prediction.settlement_payouts sp
+  JOIN prediction.settlements s ON s.market_id = sp.market_id
+  JOIN prediction.markets m ON m.id = sp.market_id
+  LEFT JOIN prediction.positions p ON p.market_id = sp.market_id AND p.owner_pubkey = sp.owner_pubkey
+WHERE
+  sp.owner_pubkey = $1
+  AND sp.payout > 0
+GROUP BY sp.market_id, m.market_pubkey, s.resolution, sp.owner_pubkey, sp.stake, sp.payout, s.created_at
+ORDER BY s.created_at DESC;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetClaimsQuery, owner)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Claim])
+}
+
+func (p *postgres) GetOwnerPositions(ctx context.Context, owner string) ([]OwnerPosition, error) {
+	const GetOwnerPositionsQuery = `SELECT p.*,
+       m.title                AS market_title,
+       m.resolution           AS market_resolution,
+       m.yes_amount           AS market_yes_amount,
+       m.no_amount            AS market_no_amount,
+       entry.yes_probability  AS entry_yes_probability,
+       m.kind                 AS market_kind,
+       coalesce((SELECT array_agg(mo.amount ORDER BY mo.outcome_id)
+                 FROM prediction.market_outcomes mo
+                 WHERE mo.market_id = m.id), '{}') AS market_outcome_amounts
+FROM prediction.positions p
+  JOIN prediction.markets m ON m.id = p.market_id
+  LEFT JOIN LATERAL (SELECT yes_probability
+                     FROM prediction.market_ticks t
+                     WHERE
+                       t.market_id = p.market_id
+                       AND t.time <= p.created_at
+                     ORDER BY t.time DESC, t.id DESC
+                     LIMIT 1) AS entry ON TRUE
+WHERE
+  p.owner_pubkey = $1
+ORDER BY p.created_at DESC;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetOwnerPositionsQuery, owner)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[OwnerPosition])
+}
+
+func (p *postgres) GetOwnerPayouts(ctx context.Context, owner string) ([]Payout, error) {
+	const GetOwnerPayoutsQuery = `SELECT *
+FROM prediction.settlement_payouts
+WHERE
+  owner_pubkey = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetOwnerPayoutsQuery, owner)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Payout])
+}
+
+func (p *postgres) ListMarkets(ctx context.Context, filter MarketFilter, limit, offset int) ([]Market, error) {
+	const ListMarketsQuery = `SELECT ` + marketColumns + `
+FROM prediction.markets
+WHERE
+  ` + marketFilterCondition + `
+ORDER BY created_at DESC, id
+LIMIT @limit OFFSET @offset;`
+	conn := p.GetConnectionFromCtx(ctx)
+	args := filter.namedArgs()
+	args["limit"] = limit
+	args["offset"] = offset
+	rows, err := conn.Query(ctx, ListMarketsQuery, args)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Market])
+}
+
+func (p *postgres) SearchMarkets(ctx context.Context, query string, filter MarketFilter, limit, offset int) ([]MarketSearchResult, error) {
+	const SearchMarketsQuery = `WITH search AS (SELECT websearch_to_tsquery('english', @query) AS tsquery)
+SELECT ` + marketColumns + `,
+       ts_rank_cd(search_vector, search.tsquery) + word_similarity(@query, title) AS rank,
+       ts_headline('english', prediction.escape_html(title), search.tsquery,
+                   'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS title_highlight,
+       ts_headline('english', prediction.escape_html(coalesce(description, '')), search.tsquery,
+                   'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS description_highlight
+FROM prediction.markets,
+     search
+WHERE
+  -- A typo in the query still matches a word of a longer title
+  (search_vector @@ search.tsquery OR @query <% title)
+  AND ` + marketFilterCondition + `
+ORDER BY rank DESC, created_at DESC, id
+LIMIT @limit OFFSET @offset;`
+	conn := p.GetConnectionFromCtx(ctx)
+	args := filter.namedArgs()
+	args["query"] = query
+	args["limit"] = limit
+	args["offset"] = offset
+	rows, err := conn.Query(ctx, SearchMarketsQuery, args)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[MarketSearchResult])
+}
+
+func (p *postgres) ListTrendingMarkets(ctx context.Context, category string, limit, offset int) ([]TrendingMarket, error) {
+	const ListTrendingMarketsQuery = `SELECT ` + marketColumns + `,
+       mt.score       AS trending_score,
+       mt.computed_at AS scored_at
+FROM prediction.markets
+       JOIN prediction.market_trending mt ON mt.market_id = markets.id
+WHERE
+  resolution = 'UNRESOLVED'
+  AND open_through > now()
+  AND moderation_status IN ('VISIBLE', 'FLAGGED')
+  AND (@category = '' OR category = @category)
+ORDER BY mt.score DESC, markets.created_at DESC, markets.id
+LIMIT @limit OFFSET @offset;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListTrendingMarketsQuery, pgx.NamedArgs{
+		"category": category,
+		"limit":    limit,
+		"offset":   offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[TrendingMarket])
+}
+
+func (p *postgres) GetCategory(ctx context.Context, slug string) (Category, error) {
+	const GetCategoryQuery = `SELECT *
+FROM prediction.categories
+WHERE
+  slug = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetCategoryQuery, slug)
+	if err != nil {
+		return Category{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Category])
+}
+
+func (p *postgres) ListCategories(ctx context.Context) ([]Category, error) {
+	const ListCategoriesQuery = `SELECT *
+FROM prediction.categories
+ORDER BY name;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListCategoriesQuery)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Category])
+}
+
+func (p *postgres) GetTagCounts(ctx context.Context, filter MarketFilter, limit int) ([]TagCount, error) {
+	const GetTagCountsQuery = `SELECT tags.tag,
+       count(*) AS count
+FROM prediction.market_tags tags
+  JOIN prediction.markets ON markets.id = tags.market_id
+WHERE
+  ` + marketFilterCondition + `
+GROUP BY tags.tag
+ORDER BY count DESC, tags.tag
+LIMIT @limit;`
+	conn := p.GetConnectionFromCtx(ctx)
+	args := filter.namedArgs()
+	args["limit"] = limit
+	rows, err := conn.Query(ctx, GetTagCountsQuery, args)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[TagCount])
+}
+
+func (p *postgres) CreateCategory(ctx context.Context, category Category) error {
+	const CreateCategoryQuery = `INSERT INTO prediction.categories
+(slug,
+ name,
+ description,
+ created_at)
+VALUES (@slug,
+        @name,
+        @description,
+        @created_at);`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, CreateCategoryQuery, pgx.NamedArgs{
+		"slug":        category.Slug,
+		"name":        category.Name,
+		"description": category.Description,
+		"created_at":  category.CreatedAt,
+	})
+	return err
+}
+
+func (p *postgres) CloseDueMarkets(ctx context.Context, now time.Time) ([]string, error) {
+	const CloseDueMarketsQuery = `UPDATE prediction.markets
+SET
+  closed_at = open_through
+WHERE
+  closed_at IS NULL
+  AND open_through <= $1
+RETURNING id;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, CloseDueMarketsQuery, now)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (p *postgres) RefreshOutcomeAmounts(ctx context.Context, market string) error {
+	const RefreshOutcomeAmountsQuery = `UPDATE prediction.market_outcomes mo
+SET
+  amount = coalesce((SELECT sum(p.amount)
+                     FROM prediction.positions p
+                     WHERE p.market_id = mo.market_id AND p.outcome_id = mo.outcome_id), 0)
+WHERE
+  mo.market_id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, RefreshOutcomeAmountsQuery, market)
+	return err
+}
+
+func (p *postgres) ResolveMarket(ctx context.Context, market Market) error {
+	const ResolveMarketQuery = `UPDATE prediction.markets
+SET
+  resolution       = @resolution,
+  resolved_outcome = @resolved_outcome,
+  resolved_value   = @resolved_value
+WHERE
+  id = @id
+  AND kind <> 'BINARY'
+  AND resolution = 'UNRESOLVED'
+RETURNING id;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var id string
+	return conn.QueryRow(ctx, ResolveMarketQuery, pgx.NamedArgs{
+		"id":               market.ID,
+		"resolution":       market.Resolution,
+		"resolved_outcome": market.ResolvedOutcome,
+		"resolved_value":   market.ResolvedValue,
+	}).Scan(&id)
+}
@@ -3,11 +3,46 @@ package prediction
 import (
 	"context"
 	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"time"
 )
 
 type Repository interface {
 	db.BaseRepository
 
+	// CreateMarket stores the market together with its tags, run it in a transaction
 	CreateMarket(ctx context.Context, market Market) error
 	SetMarketInitialized(ctx context.Context, market string) error
+	SetMarketChainStatus(ctx context.Context, market string, status MarketChainStatus) error
+	GetMarket(ctx context.Context, id string) (Market, error)
+	GetMarketByPubkey(ctx context.Context, pubkey string) (Market, error)
+	ListMarkets(ctx context.Context, filter MarketFilter, limit, offset int) ([]Market, error)
+	// SearchMarkets ranks markets by full-text match over title and description,
+	// falling back to trigram similarity of the title for misspelled queries
+	SearchMarkets(ctx context.Context, query string, filter MarketFilter, limit, offset int) ([]MarketSearchResult, error)
+	// ListTrendingMarkets returns open listed markets by their last trending score
+	ListTrendingMarkets(ctx context.Context, category string, limit, offset int) ([]TrendingMarket, error)
+	// UpsertChainMarket stores the market decoded from its program account, it returns
+	// pgx.ErrNoRows when the stored market of that id has another creator or account
+	UpsertChainMarket(ctx context.Context, market Market) error
+	// CloseDueMarkets marks markets whose trading period ended as closed and returns them
+	CloseDueMarkets(ctx context.Context, now time.Time) ([]string, error)
+	UpsertPosition(ctx context.Context, position Position) error
+	// RefreshOutcomeAmounts recomputes the amounts staked on each outcome of a categorical market
+	RefreshOutcomeAmounts(ctx context.Context, market string) error
+	// ResolveMarket stores the resolution reported for an unresolved categorical or scalar
+	// market, it returns pgx.ErrNoRows when there is no such market
+	ResolveMarket(ctx context.Context, market Market) error
+	GetMarketPositions(ctx context.Context, market string) ([]Position, error)
+	GetSettlement(ctx context.Context, market string) (Settlement, error)
+	GetSettlementPayouts(ctx context.Context, market string) ([]Payout, error)
+	// SaveSettlement stores the settlement snapshot unless the market is already settled
+	SaveSettlement(ctx context.Context, settlement Settlement, payouts []Payout) error
+	GetClaims(ctx context.Context, owner string) ([]Claim, error)
+	GetOwnerPositions(ctx context.Context, owner string) ([]OwnerPosition, error)
+	GetOwnerPayouts(ctx context.Context, owner string) ([]Payout, error)
+	GetCategory(ctx context.Context, slug string) (Category, error)
+	ListCategories(ctx context.Context) ([]Category, error)
+	CreateCategory(ctx context.Context, category Category) error
+	// GetTagCounts counts markets per tag among markets matching the filter
+	GetTagCounts(ctx context.Context, filter MarketFilter, limit int) ([]TagCount, error)
 }
@@ -0,0 +1,46 @@
+package prediction
+
+import (
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/profile"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+type Settlement struct {
+	MarketID    string           `db:"market_id" json:"market_id,omitempty"`
+	Resolution  MarketResolution `db:"resolution" json:"resolution,omitempty"`
+	TotalPool   int64            `db:"total_pool" json:"total_pool"`
+	WinningPool int64            `db:"winning_pool" json:"winning_pool"`
+	FeeBps      int32            `db:"fee_bps" json:"fee_bps"`
+	FeeAmount   int64            `db:"fee_amount" json:"fee_amount"`
+	CreatedAt   time.Time        `db:"created_at" json:"created_at,omitempty"`
+	// Creator fees are taken from the losing pool on top of the protocol fee
+	CreatorFeeBps    int32 `db:"creator_fee_bps" json:"creator_fee_bps"`
+	CreatorFeeAmount int64 `db:"creator_fee_amount" json:"creator_fee_amount"`
+	// WinningOutcomeID is the winning outcome of a categorical market
+	WinningOutcomeID pgtype.Int2 `db:"winning_outcome_id" json:"winning_outcome_id"`
+	// ResolvedValue is the answer of a scalar market, WinningPool is then the pool of the
+	// side that gained from it
+	ResolvedValue pgtype.Float8 `db:"resolved_value" json:"resolved_value"`
+}
+
+type Payout struct {
+	MarketID    string `db:"market_id" json:"market_id,omitempty"`
+	OwnerPubkey string `db:"owner_pubkey" json:"owner_pubkey,omitempty"`
+	Stake       int64  `db:"stake" json:"stake"`
+	Payout      int64  `db:"payout" json:"payout"`
+	// OwnerProfile is filled by the api when the owner has a profile
+	OwnerProfile *profile.Summary `db:"-" json:"owner_profile,omitempty"`
+}
+
+// Claim is a payout owed to a wallet together with what is needed to build the claim transaction
+type Claim struct {
+	MarketID        string           `db:"market_id" json:"market_id,omitempty"`
+	MarketPubkey    zeronull.Text    `db:"market_pubkey" json:"market_pubkey,omitempty"`
+	Resolution      MarketResolution `db:"resolution" json:"resolution,omitempty"`
+	OwnerPubkey     string           `db:"owner_pubkey" json:"owner_pubkey,omitempty"`
+	Stake           int64            `db:"stake" json:"stake"`
+	Payout          int64            `db:"payout" json:"payout"`
+	PositionPubkeys []string         `db:"position_pubkeys" json:"position_pubkeys"`
+}
@@ -0,0 +1,133 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const uniqueViolation = "23505"
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) GetProfile(ctx context.Context, pubkey string) (Profile, error) {
+	const GetProfileQuery = `SELECT *
+FROM prediction.profiles
+WHERE
+  pubkey = $1;`
+	return p.getProfile(ctx, GetProfileQuery, pubkey)
+}
+
+func (p *postgres) GetProfileForUpdate(ctx context.Context, pubkey string) (Profile, error) {
+	const GetProfileForUpdateQuery = `SELECT *
+FROM prediction.profiles
+WHERE
+  pubkey = $1
+  FOR UPDATE;`
+	return p.getProfile(ctx, GetProfileForUpdateQuery, pubkey)
+}
+
+func (p *postgres) getProfile(ctx context.Context, query, pubkey string) (Profile, error) {
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, query, pubkey)
+	if err != nil {
+		return Profile{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Profile])
+}
+
+func (p *postgres) SaveProfile(ctx context.Context, profile Profile) error {
+	const SaveProfileQuery = `INSERT INTO prediction.profiles
+(pubkey,
+ display_name,
+ avatar_url,
+ bio,
+ socials,
+ sns_name,
+ created_at,
+ updated_at)
+VALUES (@pubkey,
+        @display_name,
+        @avatar_url,
+        @bio,
+        @socials,
+        @sns_name,
+        @created_at,
+        @updated_at)
+ON CONFLICT (pubkey) DO UPDATE
+  SET
+    display_name = excluded.display_name,
+    avatar_url   = excluded.avatar_url,
+    bio          = excluded.bio,
+    socials      = excluded.socials,
+    sns_name     = excluded.sns_name,
+    updated_at   = excluded.updated_at;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, SaveProfileQuery, pgx.NamedArgs{
+		"pubkey":       profile.Pubkey,
+		"display_name": profile.DisplayName,
+		"avatar_url":   profile.AvatarURL,
+		"bio":          profile.Bio,
+		"socials":      profile.Socials,
+		"sns_name":     profile.SnsName,
+		"created_at":   profile.CreatedAt,
+		"updated_at":   profile.UpdatedAt,
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		switch pgErr.ConstraintName {
+		case "profiles_display_name_idx":
+			return ErrDisplayNameTaken
+		case "profiles_sns_name_idx":
+			return ErrSnsNameTaken
+		}
+	}
+	return err
+}
+
+func (p *postgres) UnlinkSnsName(ctx context.Context, name, owner string) error {
+	const UnlinkSnsNameQuery = `UPDATE prediction.profiles
+SET
+  sns_name = NULL
+WHERE
+  sns_name = $1
+  AND pubkey <> $2;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, UnlinkSnsNameQuery, name, owner)
+	return err
+}
+
+func (p *postgres) GetSummaries(ctx context.Context, pubkeys []string) (map[string]Summary, error) {
+	const GetSummariesQuery = `SELECT *
+FROM prediction.profiles
+WHERE
+  pubkey = ANY ($1);`
+	summaries := make(map[string]Summary)
+	if len(pubkeys) == 0 {
+		return summaries, nil
+	}
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetSummariesQuery, pubkeys)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := pgx.CollectRows(rows, pgx.RowToStructByName[Profile])
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		summaries[profile.Pubkey] = profile.Summary()
+	}
+	return summaries, nil
+}
@@ -0,0 +1,44 @@
+package profile
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+var (
+	ErrDisplayNameTaken = errors.New("display name is taken")
+	ErrSnsNameTaken     = errors.New("sns name is linked to another profile")
+)
+
+type Profile struct {
+	Pubkey      string        `db:"pubkey" json:"pubkey"`
+	DisplayName zeronull.Text `db:"display_name" json:"display_name,omitempty"`
+	AvatarURL   zeronull.Text `db:"avatar_url" json:"avatar_url,omitempty"`
+	Bio         zeronull.Text `db:"bio" json:"bio,omitempty"`
+	// Socials maps a network such as "x" or "telegram" to the handle on it
+	Socials map[string]string `db:"socials" json:"socials"`
+	// SnsName is a .sol domain owned by the wallet when it was linked, it is cleared
+	// once the new owner of the domain links it
+	SnsName   zeronull.Text `db:"sns_name" json:"sns_name,omitempty"`
+	CreatedAt time.Time     `db:"created_at" json:"created_at"`
+	// UpdatedAt is the timestamp of the last signed update, older updates are rejected
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Summary is the part of a profile embedded next to wallet pubkeys in other responses
+type Summary struct {
+	Pubkey      string `json:"pubkey"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	SnsName     string `json:"sns_name,omitempty"`
+}
+
+func (p Profile) Summary() Summary {
+	return Summary{
+		Pubkey:      p.Pubkey,
+		DisplayName: string(p.DisplayName),
+		AvatarURL:   string(p.AvatarURL),
+		SnsName:     string(p.SnsName),
+	}
+}
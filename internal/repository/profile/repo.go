@@ -0,0 +1,21 @@
+package profile
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	GetProfile(ctx context.Context, pubkey string) (Profile, error)
+	// GetProfileForUpdate locks the profile of the wallet, run it in a transaction
+	GetProfileForUpdate(ctx context.Context, pubkey string) (Profile, error)
+	// SaveProfile creates or replaces the profile, it returns ErrDisplayNameTaken or
+	// ErrSnsNameTaken when another wallet holds the name
+	SaveProfile(ctx context.Context, profile Profile) error
+	// UnlinkSnsName clears the .sol name from the profile of any wallet other than owner
+	UnlinkSnsName(ctx context.Context, name, owner string) error
+	// GetSummaries returns summaries of the wallets that have a profile
+	GetSummaries(ctx context.Context, pubkeys []string) (map[string]Summary, error)
+}
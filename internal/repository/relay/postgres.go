@@ -0,0 +1,97 @@
+package relay
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) SaveTransaction(ctx context.Context, tx Transaction) error {
+	const SaveTransactionQuery = `INSERT INTO relay.transactions
+(signature,
+ raw,
+ last_valid_block_height,
+ status,
+ attempts,
+ slot,
+ err,
+ kind,
+ subject,
+ created_at,
+ updated_at)
+VALUES (@signature,
+        @raw,
+        @last_valid_block_height,
+        @status,
+        @attempts,
+        @slot,
+        @err,
+        @kind,
+        @subject,
+        @created_at,
+        @updated_at)
+ON CONFLICT (signature) DO NOTHING;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, SaveTransactionQuery, pgx.NamedArgs{
+		"signature":               tx.Signature,
+		"raw":                     tx.Raw,
+		"last_valid_block_height": tx.LastValidBlockHeight,
+		"status":                  tx.Status,
+		"attempts":                tx.Attempts,
+		"slot":                    tx.Slot,
+		"err":                     tx.Err,
+		"kind":                    tx.Kind,
+		"subject":                 tx.Subject,
+		"created_at":              tx.CreatedAt,
+		"updated_at":              tx.UpdatedAt,
+	})
+	return err
+}
+
+func (p *postgres) UpdateTransaction(ctx context.Context, tx Transaction) error {
+	const UpdateTransactionQuery = `UPDATE relay.transactions
+SET
+  status     = @status,
+  attempts   = @attempts,
+  slot       = @slot,
+  err        = @err,
+  updated_at = @updated_at
+WHERE
+  signature = @signature
+  AND status = 'PENDING';`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, UpdateTransactionQuery, pgx.NamedArgs{
+		"signature":  tx.Signature,
+		"status":     tx.Status,
+		"attempts":   tx.Attempts,
+		"slot":       tx.Slot,
+		"err":        tx.Err,
+		"updated_at": tx.UpdatedAt,
+	})
+	return err
+}
+
+func (p *postgres) ListPending(ctx context.Context) ([]Transaction, error) {
+	const ListPendingQuery = `SELECT *
+FROM relay.transactions
+WHERE
+  status = 'PENDING'
+ORDER BY created_at;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListPendingQuery)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Transaction])
+}
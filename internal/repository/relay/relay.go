@@ -0,0 +1,22 @@
+package relay
+
+import (
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+// Transaction is a signed transaction relayed to the cluster and rebroadcast until it
+// reaches a final status. Kind names the handler of its outcome, Subject what it acts on.
+type Transaction struct {
+	Signature            string        `db:"signature" json:"signature"`
+	Raw                  []byte        `db:"raw" json:"-"`
+	LastValidBlockHeight uint64        `db:"last_valid_block_height" json:"last_valid_block_height"`
+	Status               string        `db:"status" json:"status"`
+	Attempts             int32         `db:"attempts" json:"attempts"`
+	Slot                 uint64        `db:"slot" json:"slot"`
+	Err                  zeronull.Text `db:"err" json:"err,omitempty"`
+	Kind                 zeronull.Text `db:"kind" json:"kind,omitempty"`
+	Subject              zeronull.Text `db:"subject" json:"subject,omitempty"`
+	CreatedAt            time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt            time.Time     `db:"updated_at" json:"updated_at"`
+}
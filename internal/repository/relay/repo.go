@@ -0,0 +1,18 @@
+package relay
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	// SaveTransaction stores a newly relayed transaction unless it is already tracked
+	SaveTransaction(ctx context.Context, tx Transaction) error
+	// UpdateTransaction stores the status, attempts, slot and error of the transaction
+	// while it is pending, transactions that reached a final status are not changed
+	UpdateTransaction(ctx context.Context, tx Transaction) error
+	// ListPending returns the transactions that did not reach a final status yet
+	ListPending(ctx context.Context) ([]Transaction, error)
+}
@@ -0,0 +1,213 @@
+package resolver
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) CreateAssignment(ctx context.Context, assignment Assignment) error {
+	const CreateAssignmentQuery = `INSERT INTO prediction.resolver_assignments
+(id,
+ market_id,
+ resolver_pubkey,
+ nominated_by,
+ status,
+ created_at,
+ responded_at)
+VALUES (@id,
+        @market_id,
+        @resolver_pubkey,
+        @nominated_by,
+        @status,
+        @created_at,
+        @responded_at);`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, CreateAssignmentQuery, pgx.NamedArgs{
+		"id":              assignment.ID,
+		"market_id":       assignment.MarketID,
+		"resolver_pubkey": assignment.ResolverPubkey,
+		"nominated_by":    assignment.NominatedBy,
+		"status":          assignment.Status,
+		"created_at":      assignment.CreatedAt,
+		"responded_at":    assignment.RespondedAt,
+	})
+	return err
+}
+
+func (p *postgres) RevokePending(ctx context.Context, market string, at time.Time) error {
+	const RevokePendingQuery = `UPDATE prediction.resolver_assignments
+SET
+  status       = 'REVOKED',
+  responded_at = $2
+WHERE
+  market_id = $1
+  AND status = 'PENDING';`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, RevokePendingQuery, market, at)
+	return err
+}
+
+func (p *postgres) GetPendingAssignment(ctx context.Context, market string) (Assignment, error) {
+	const GetPendingAssignmentQuery = `SELECT *
+FROM prediction.resolver_assignments
+WHERE
+  market_id = $1
+  AND status = 'PENDING'
+  FOR UPDATE;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetPendingAssignmentQuery, market)
+	if err != nil {
+		return Assignment{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Assignment])
+}
+
+func (p *postgres) RespondAssignment(ctx context.Context, id string, status AssignmentStatus, at time.Time) error {
+	const RespondAssignmentQuery = `UPDATE prediction.resolver_assignments
+SET
+  status       = $2,
+  responded_at = $3
+WHERE
+  id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, RespondAssignmentQuery, id, status, at)
+	return err
+}
+
+func (p *postgres) ReplaceAccepted(ctx context.Context, market string) error {
+	const ReplaceAcceptedQuery = `UPDATE prediction.resolver_assignments
+SET
+  status = 'REPLACED'
+WHERE
+  market_id = $1
+  AND status = 'ACCEPTED';`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, ReplaceAcceptedQuery, market)
+	return err
+}
+
+func (p *postgres) ListAssignments(ctx context.Context, market string) ([]Assignment, error) {
+	const ListAssignmentsQuery = `SELECT *
+FROM prediction.resolver_assignments
+WHERE
+  market_id = $1
+ORDER BY created_at, id;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListAssignmentsQuery, market)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Assignment])
+}
+
+func (p *postgres) SetMarketResolver(
+	ctx context.Context,
+	market, resolver string,
+	status prediction.ResolverStatus,
+) error {
+	const SetMarketResolverQuery = `UPDATE prediction.markets
+SET
+  resolver_pubkey = $2,
+  resolver_status = $3
+WHERE
+  id = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	_, err := conn.Exec(ctx, SetMarketResolverQuery, market, resolver, status)
+	return err
+}
+
+func (p *postgres) GetReputation(ctx context.Context, resolver string) (Reputation, error) {
+	const GetReputationQuery = `WITH resolutions AS (SELECT r.*,
+                            EXISTS (SELECT 1
+                                    FROM prediction.resolution_challenges c
+                                    WHERE c.market_id = r.market_id) AS disputed,
+                            (r.final_outcome = r.proposed_outcome
+                              AND r.final_outcome_id IS NOT DISTINCT FROM r.proposed_outcome_id
+                              AND r.final_value IS NOT DISTINCT FROM r.proposed_value) AS upheld
+                     FROM prediction.resolutions r
+                     WHERE
+                       r.proposed_by = $1)
+SELECT $1::TEXT                                                                       AS resolver_pubkey,
+       (SELECT count(*)
+        FROM prediction.markets
+        WHERE
+          resolver_pubkey = $1
+          AND resolver_status = 'ACCEPTED'
+          AND resolution = 'UNRESOLVED')                                              AS assigned,
+       count(*)                                                                       AS resolved,
+       count(*) FILTER (WHERE status = 'FINAL')                                       AS final,
+       count(*) FILTER (WHERE disputed)                                               AS disputed,
+       count(*) FILTER (WHERE status = 'FINAL' AND NOT upheld)                        AS overturned,
+       coalesce(count(*) FILTER (WHERE status = 'FINAL' AND upheld)::DOUBLE PRECISION
+                  / nullif(count(*) FILTER (WHERE status = 'FINAL'), 0), 0)           AS accuracy,
+       max(proposed_at)                                                               AS last_resolved_at
+FROM resolutions;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, GetReputationQuery, resolver)
+	if err != nil {
+		return Reputation{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Reputation])
+}
+
+func (p *postgres) ExpirePending(ctx context.Context, before time.Time) (int64, error) {
+	const ExpirePendingQuery = `UPDATE prediction.resolver_assignments
+SET
+  status       = 'REVOKED',
+  responded_at = now()
+WHERE
+  status = 'PENDING'
+  AND created_at < $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, ExpirePendingQuery, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (p *postgres) RemindOverdue(ctx context.Context, overdueSince, remindedSince, now time.Time) ([]Reminder, error) {
+	const RemindOverdueQuery = `INSERT INTO prediction.resolver_reminders
+(market_id,
+ resolver_pubkey,
+ reminded_at)
+SELECT m.id,
+       m.resolver_pubkey,
+       @now::TIMESTAMPTZ
+FROM prediction.markets m
+WHERE
+  m.closed_at IS NOT NULL
+  AND m.closed_at < @overdue_since
+  AND m.resolution = 'UNRESOLVED'
+  AND m.resolver_status = 'ACCEPTED'
+  AND NOT EXISTS (SELECT 1 FROM prediction.resolutions r WHERE r.market_id = m.id)
+  AND NOT EXISTS (SELECT 1 FROM prediction.market_feeds f WHERE f.market_id = m.id AND f.status <> 'FAILED')
+  AND NOT EXISTS (SELECT 1
+                  FROM prediction.resolver_reminders rr
+                  WHERE rr.market_id = m.id AND rr.reminded_at > @reminded_since)
+RETURNING market_id, resolver_pubkey, reminded_at;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, RemindOverdueQuery, pgx.NamedArgs{
+		"now":            now,
+		"overdue_since":  overdueSince,
+		"reminded_since": remindedSince,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Reminder])
+}
@@ -0,0 +1,30 @@
+package resolver
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"time"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	// CreateAssignment stores the assignment, revoke the pending one of the market first
+	CreateAssignment(ctx context.Context, assignment Assignment) error
+	// RevokePending revokes the pending assignment of the market if any
+	RevokePending(ctx context.Context, market string, at time.Time) error
+	// GetPendingAssignment locks the pending assignment of the market until the end of the transaction
+	GetPendingAssignment(ctx context.Context, market string) (Assignment, error)
+	RespondAssignment(ctx context.Context, id string, status AssignmentStatus, at time.Time) error
+	// ReplaceAccepted marks the accepted assignment of the market as replaced
+	ReplaceAccepted(ctx context.Context, market string) error
+	ListAssignments(ctx context.Context, market string) ([]Assignment, error)
+	SetMarketResolver(ctx context.Context, market, resolver string, status prediction.ResolverStatus) error
+	GetReputation(ctx context.Context, resolver string) (Reputation, error)
+	// ExpirePending revokes pending assignments created before the given time
+	ExpirePending(ctx context.Context, before time.Time) (int64, error)
+	// RemindOverdue records a reminder for every market that closed before overdueSince without
+	// a proposed resolution or an oracle, unless its resolver was reminded after remindedSince
+	RemindOverdue(ctx context.Context, overdueSince, remindedSince, now time.Time) ([]Reminder, error)
+}
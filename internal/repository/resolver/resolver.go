@@ -0,0 +1,49 @@
+package resolver
+
+import (
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+type AssignmentStatus string
+
+const (
+	AssignmentStatusPending  AssignmentStatus = "PENDING"
+	AssignmentStatusAccepted AssignmentStatus = "ACCEPTED"
+	AssignmentStatusDeclined AssignmentStatus = "DECLINED"
+	AssignmentStatusRevoked  AssignmentStatus = "REVOKED"
+	AssignmentStatusReplaced AssignmentStatus = "REPLACED"
+)
+
+// Assignment nominates a wallet to resolve a market. A market has at most one pending
+// assignment and the accepted one is the resolver of record until it is replaced.
+type Assignment struct {
+	ID             string               `db:"id" json:"id"`
+	MarketID       string               `db:"market_id" json:"market_id"`
+	ResolverPubkey string               `db:"resolver_pubkey" json:"resolver_pubkey"`
+	NominatedBy    string               `db:"nominated_by" json:"nominated_by"`
+	Status         AssignmentStatus     `db:"status" json:"status"`
+	CreatedAt      time.Time            `db:"created_at" json:"created_at"`
+	RespondedAt    zeronull.Timestamptz `db:"responded_at" json:"responded_at,omitempty"`
+}
+
+// Reputation summarizes the track record of a resolver over its proposed resolutions
+type Reputation struct {
+	ResolverPubkey string `db:"resolver_pubkey" json:"resolver_pubkey"`
+	// Assigned counts unresolved markets currently assigned to the resolver
+	Assigned   int64 `db:"assigned" json:"assigned"`
+	Resolved   int64 `db:"resolved" json:"resolved"`
+	Final      int64 `db:"final" json:"final"`
+	Disputed   int64 `db:"disputed" json:"disputed"`
+	Overturned int64 `db:"overturned" json:"overturned"`
+	// Accuracy is the share of final resolutions that were not overturned
+	Accuracy       float64              `db:"accuracy" json:"accuracy"`
+	LastResolvedAt zeronull.Timestamptz `db:"last_resolved_at" json:"last_resolved_at,omitempty"`
+}
+
+// Reminder nudges the resolver of a closed market that has no proposed resolution yet
+type Reminder struct {
+	MarketID       string    `db:"market_id" json:"market_id"`
+	ResolverPubkey string    `db:"resolver_pubkey" json:"resolver_pubkey"`
+	RemindedAt     time.Time `db:"reminded_at" json:"reminded_at"`
+}
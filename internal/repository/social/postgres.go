@@ -0,0 +1,187 @@
+package social
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) Follow(ctx context.Context, follow Follow) (bool, error) {
+	const FollowQuery = `INSERT INTO prediction.follows
+(follower_pubkey,
+ followee_pubkey,
+ created_at)
+VALUES (@follower_pubkey,
+        @followee_pubkey,
+        @created_at)
+ON CONFLICT DO NOTHING;`
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, FollowQuery, pgx.NamedArgs{
+		"follower_pubkey": follow.FollowerPubkey,
+		"followee_pubkey": follow.FolloweePubkey,
+		"created_at":      follow.CreatedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (p *postgres) Unfollow(ctx context.Context, follower, followee string) (bool, error) {
+	const UnfollowQuery = `DELETE
+FROM prediction.follows
+WHERE
+  follower_pubkey = $1
+  AND followee_pubkey = $2;`
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, UnfollowQuery, follower, followee)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (p *postgres) CountFollowing(ctx context.Context, follower string) (int64, error) {
+	const CountFollowingQuery = `SELECT count(*)
+FROM prediction.follows
+WHERE
+  follower_pubkey = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var count int64
+	err := conn.QueryRow(ctx, CountFollowingQuery, follower).Scan(&count)
+	return count, err
+}
+
+func (p *postgres) CountFollowers(ctx context.Context, followee string) (int64, error) {
+	const CountFollowersQuery = `SELECT count(*)
+FROM prediction.follows
+WHERE
+  followee_pubkey = $1;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var count int64
+	err := conn.QueryRow(ctx, CountFollowersQuery, followee).Scan(&count)
+	return count, err
+}
+
+func (p *postgres) ListFollowing(ctx context.Context, follower string, after *Cursor, limit int) ([]Follow, error) {
+	const ListFollowingQuery = `SELECT *
+FROM prediction.follows
+WHERE
+  follower_pubkey = @pubkey
+  AND (NOT @paged OR (created_at, followee_pubkey) < (@after_created_at, @after_id))
+ORDER BY created_at DESC, followee_pubkey DESC
+LIMIT @limit;`
+	return p.listFollows(ctx, ListFollowingQuery, follower, after, limit)
+}
+
+func (p *postgres) ListFollowers(ctx context.Context, followee string, after *Cursor, limit int) ([]Follow, error) {
+	const ListFollowersQuery = `SELECT *
+FROM prediction.follows
+WHERE
+  followee_pubkey = @pubkey
+  AND (NOT @paged OR (created_at, follower_pubkey) < (@after_created_at, @after_id))
+ORDER BY created_at DESC, follower_pubkey DESC
+LIMIT @limit;`
+	return p.listFollows(ctx, ListFollowersQuery, followee, after, limit)
+}
+
+func (p *postgres) listFollows(ctx context.Context, query, pubkey string, after *Cursor, limit int) ([]Follow, error) {
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, query, pageArgs(pgx.NamedArgs{"pubkey": pubkey}, after, limit))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Follow])
+}
+
+// ListFeed reads the latest page of every followed wallet through the creator and owner
+// indexes and merges them, so the cost grows with the followed wallets and not with
+// the size of the markets and positions tables
+func (p *postgres) ListFeed(ctx context.Context, follower string, after *Cursor, limit int) ([]Activity, error) {
+	const ListFeedQuery = `WITH followed AS (SELECT followee_pubkey
+                  FROM prediction.follows
+                  WHERE
+                    follower_pubkey = @follower)
+SELECT activity.*
+FROM (SELECT 'MARKET_CREATED'  AS kind,
+             m.id,
+             m.creator_pubkey  AS actor_pubkey,
+             m.id              AS market_id,
+             m.title           AS market_title,
+             NULL::TEXT        AS side,
+             NULL::SMALLINT    AS outcome_id,
+             NULL::BIGINT      AS amount,
+             m.created_at
+      FROM followed f
+             CROSS JOIN LATERAL (SELECT markets.id,
+                                        markets.creator_pubkey,
+                                        markets.title,
+                                        markets.created_at
+                                 FROM prediction.markets
+                                 WHERE
+                                   markets.creator_pubkey = f.followee_pubkey
+                                   AND markets.moderation_status IN ('VISIBLE', 'FLAGGED')
+                                   AND (NOT @paged OR (markets.created_at, markets.id) < (@after_created_at, @after_id))
+                                 ORDER BY markets.created_at DESC, markets.id DESC
+                                 LIMIT @limit) m
+      UNION ALL
+      SELECT 'POSITION_TAKEN',
+             p.id,
+             p.owner_pubkey,
+             p.market_id,
+             p.market_title,
+             p.side::TEXT,
+             p.outcome_id,
+             p.amount,
+             p.created_at
+      FROM followed f
+             CROSS JOIN LATERAL (SELECT positions.id,
+                                        positions.owner_pubkey,
+                                        positions.market_id,
+                                        markets.title AS market_title,
+                                        positions.side,
+                                        positions.outcome_id,
+                                        positions.amount,
+                                        positions.created_at
+                                 FROM prediction.positions
+                                        JOIN prediction.markets ON markets.id = positions.market_id
+                                 WHERE
+                                   positions.owner_pubkey = f.followee_pubkey
+                                   AND markets.moderation_status IN ('VISIBLE', 'FLAGGED')
+                                   AND (NOT @paged OR (positions.created_at, positions.id) < (@after_created_at, @after_id))
+                                 ORDER BY positions.created_at DESC, positions.id DESC
+                                 LIMIT @limit) p) activity
+ORDER BY activity.created_at DESC, activity.id DESC
+LIMIT @limit;`
+	conn := p.GetConnectionFromCtx(ctx)
+	rows, err := conn.Query(ctx, ListFeedQuery, pageArgs(pgx.NamedArgs{"follower": follower}, after, limit))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Activity])
+}
+
+// pageArgs adds the keyset pagination arguments to the query arguments
+func pageArgs(args pgx.NamedArgs, after *Cursor, limit int) pgx.NamedArgs {
+	args["paged"] = after != nil
+	args["after_created_at"] = time.Time{}
+	args["after_id"] = ""
+	args["limit"] = limit
+	if after != nil {
+		args["after_created_at"] = after.CreatedAt
+		args["after_id"] = after.ID
+	}
+	return args
+}
@@ -0,0 +1,24 @@
+package social
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	// Follow returns false when the wallet already follows the followee
+	Follow(ctx context.Context, follow Follow) (bool, error)
+	// Unfollow returns false when the wallet did not follow the followee
+	Unfollow(ctx context.Context, follower, followee string) (bool, error)
+	CountFollowing(ctx context.Context, follower string) (int64, error)
+	CountFollowers(ctx context.Context, followee string) (int64, error)
+	// ListFollowing returns a page of the wallets the follower follows, newest follows first
+	ListFollowing(ctx context.Context, follower string, after *Cursor, limit int) ([]Follow, error)
+	// ListFollowers returns a page of the wallets following the followee, newest follows first
+	ListFollowers(ctx context.Context, followee string, after *Cursor, limit int) ([]Follow, error)
+	// ListFeed merges markets created and positions taken by the wallets the follower
+	// follows, newest first, starting after the cursor when it is set
+	ListFeed(ctx context.Context, follower string, after *Cursor, limit int) ([]Activity, error)
+}
@@ -0,0 +1,44 @@
+package social
+
+import (
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/profile"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgtype/zeronull"
+	"time"
+)
+
+type ActivityKind string
+
+const (
+	ActivityMarketCreated ActivityKind = "MARKET_CREATED"
+	ActivityPositionTaken ActivityKind = "POSITION_TAKEN"
+)
+
+type Follow struct {
+	FollowerPubkey string    `db:"follower_pubkey" json:"follower_pubkey"`
+	FolloweePubkey string    `db:"followee_pubkey" json:"followee_pubkey"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// Activity is a feed entry, the id is the market id for created markets and the
+// position id for taken positions
+type Activity struct {
+	Kind        ActivityKind `db:"kind" json:"kind"`
+	ID          string       `db:"id" json:"id"`
+	ActorPubkey string       `db:"actor_pubkey" json:"actor_pubkey"`
+	MarketID    string       `db:"market_id" json:"market_id"`
+	MarketTitle string       `db:"market_title" json:"market_title"`
+	// Side, OutcomeID and Amount are set for taken positions
+	Side      zeronull.Text `db:"side" json:"side,omitempty"`
+	OutcomeID pgtype.Int2   `db:"outcome_id" json:"outcome_id"`
+	Amount    zeronull.Int8 `db:"amount" json:"amount,omitempty"`
+	CreatedAt time.Time     `db:"created_at" json:"created_at"`
+	// ActorProfile is filled by the api when the actor has a profile
+	ActorProfile *profile.Summary `db:"-" json:"actor_profile,omitempty"`
+}
+
+// Cursor points past the last entry of a page, entries are listed newest first
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
@@ -0,0 +1,161 @@
+package trending
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type postgres struct {
+	db.BaseRepository
+}
+
+func NewPostgres(pool *pgxpool.Pool) Repository {
+	return &postgres{
+		BaseRepository: db.NewPostgresBaseRepository(pool),
+	}
+}
+
+func (p *postgres) RecordView(ctx context.Context, market string, viewer string, viewedAt time.Time) (bool, error) {
+	const RecordViewQuery = `WITH market AS (SELECT id
+                FROM prediction.markets
+                WHERE
+                  id = $1
+                  AND moderation_status IN ('VISIBLE', 'FLAGGED')),
+     first_view AS (
+       INSERT INTO prediction.market_viewers
+         (market_id,
+          bucket,
+          viewer)
+         SELECT id,
+                date_trunc('hour', $2::TIMESTAMPTZ),
+                $3
+         FROM market
+         ON CONFLICT (market_id, bucket, viewer) DO NOTHING
+         RETURNING market_id, bucket),
+     counted AS (
+       INSERT INTO prediction.market_views
+         (market_id,
+          bucket,
+          views)
+         SELECT market_id,
+                bucket,
+                1
+         FROM first_view
+         ON CONFLICT (market_id, bucket) DO UPDATE
+           SET
+             views = market_views.views + 1)
+SELECT count(*) > 0
+FROM market;`
+	conn := p.GetConnectionFromCtx(ctx)
+	var listed bool
+	err := conn.QueryRow(ctx, RecordViewQuery, market, viewedAt, viewer).Scan(&listed)
+	return listed, err
+}
+
+// Refresh weighs every position, unique bettor, comment and view by 0.5^(age/halfLife),
+// views by the middle of their hour
+func (p *postgres) Refresh(ctx context.Context, weights Weights, halfLife time.Duration, since, now time.Time) (int64, error) {
+	const DeleteScoresQuery = `DELETE
+FROM prediction.market_trending;`
+	const InsertScoresQuery = `WITH bettors AS (SELECT market_id,
+                        sum(amount * power(0.5, extract(EPOCH FROM @now::TIMESTAMPTZ - created_at)::DOUBLE PRECISION /
+                                                @half_life::DOUBLE PRECISION))                AS volume,
+                        power(0.5, extract(EPOCH FROM @now::TIMESTAMPTZ - max(created_at))::DOUBLE PRECISION /
+                                   @half_life::DOUBLE PRECISION)                              AS bettor
+                 FROM prediction.positions
+                 WHERE
+                   created_at >= @since
+                 GROUP BY market_id, owner_pubkey),
+     trades AS (SELECT market_id,
+                       sum(volume) AS volume,
+                       sum(bettor) AS bettors
+                FROM bettors
+                GROUP BY market_id),
+     discussion AS (SELECT market_id,
+                           sum(power(0.5, extract(EPOCH FROM @now::TIMESTAMPTZ - created_at)::DOUBLE PRECISION /
+                                          @half_life::DOUBLE PRECISION)) AS comments
+                    FROM prediction.comments
+                    WHERE
+                      created_at >= @since
+                      AND status = 'VISIBLE'
+                    GROUP BY market_id),
+     visits AS (SELECT market_id,
+                       sum(views * power(0.5, extract(EPOCH FROM @now::TIMESTAMPTZ - bucket - INTERVAL '30 minutes')::DOUBLE PRECISION /
+                                              @half_life::DOUBLE PRECISION)) AS views
+                FROM prediction.market_views
+                WHERE
+                  bucket >= @since
+                GROUP BY market_id),
+     activity AS (SELECT m.id                     AS market_id,
+                         coalesce(t.volume, 0)    AS volume,
+                         coalesce(t.bettors, 0)   AS bettors,
+                         coalesce(d.comments, 0)  AS comments,
+                         coalesce(v.views, 0)     AS views
+                  FROM prediction.markets m
+                         LEFT JOIN trades t ON t.market_id = m.id
+                         LEFT JOIN discussion d ON d.market_id = m.id
+                         LEFT JOIN visits v ON v.market_id = m.id
+                  WHERE
+                    m.resolution = 'UNRESOLVED'
+                    AND m.open_through > @now
+                    AND m.moderation_status IN ('VISIBLE', 'FLAGGED')
+                    AND (t.market_id IS NOT NULL OR d.market_id IS NOT NULL OR v.market_id IS NOT NULL))
+INSERT
+INTO prediction.market_trending
+(market_id,
+ score,
+ volume,
+ bettors,
+ comments,
+ views,
+ computed_at)
+SELECT market_id,
+       @volume_weight * ln(1 + volume) + @bettor_weight * bettors +
+       @comment_weight * comments + @view_weight * ln(1 + views),
+       volume,
+       bettors,
+       comments,
+       views,
+       @now
+FROM activity;`
+	conn := p.GetConnectionFromCtx(ctx)
+	if _, err := conn.Exec(ctx, DeleteScoresQuery); err != nil {
+		return 0, err
+	}
+	tag, err := conn.Exec(ctx, InsertScoresQuery, pgx.NamedArgs{
+		"now":            now,
+		"since":          since,
+		"half_life":      halfLife.Seconds(),
+		"volume_weight":  weights.Volume,
+		"bettor_weight":  weights.Bettors,
+		"comment_weight": weights.Comments,
+		"view_weight":    weights.Views,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (p *postgres) PruneViews(ctx context.Context, before time.Time) (int64, error) {
+	const PruneViewsQuery = `DELETE
+FROM prediction.market_views
+WHERE
+  bucket < $1;`
+	const PruneViewersQuery = `DELETE
+FROM prediction.market_viewers
+WHERE
+  bucket < date_trunc('hour', now());`
+	conn := p.GetConnectionFromCtx(ctx)
+	tag, err := conn.Exec(ctx, PruneViewsQuery, before)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = conn.Exec(ctx, PruneViewersQuery); err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,20 @@
+package trending
+
+import (
+	"context"
+	"github.com/IndexStorm/hit-my-bet-back/pkg/db"
+	"time"
+)
+
+type Repository interface {
+	db.BaseRepository
+
+	// RecordView counts a view of the market once per viewer and hour, it returns false
+	// when the market is not listed
+	RecordView(ctx context.Context, market string, viewer string, viewedAt time.Time) (bool, error)
+	// Refresh recomputes the scores of open listed markets from their activity since the
+	// given time and returns how many markets have a score, run it in a transaction
+	Refresh(ctx context.Context, weights Weights, halfLife time.Duration, since, now time.Time) (int64, error)
+	// PruneViews deletes view counts older than the given time and viewers of past hours
+	PruneViews(ctx context.Context, before time.Time) (int64, error)
+}
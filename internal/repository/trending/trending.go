@@ -0,0 +1,23 @@
+package trending
+
+import "time"
+
+// Weights scale the components of the score
+type Weights struct {
+	Volume   float64
+	Bettors  float64
+	Comments float64
+	Views    float64
+}
+
+// Score is the popularity of a market when it was computed, each component is decayed
+// by its age
+type Score struct {
+	MarketID   string    `db:"market_id" json:"market_id"`
+	Score      float64   `db:"score" json:"score"`
+	Volume     float64   `db:"volume" json:"volume"`
+	Bettors    float64   `db:"bettors" json:"bettors"`
+	Comments   float64   `db:"comments" json:"comments"`
+	Views      float64   `db:"views" json:"views"`
+	ComputedAt time.Time `db:"computed_at" json:"computed_at"`
+}
@@ -0,0 +1,39 @@
+package rpcpool
+
+import (
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/config"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"slices"
+	"strings"
+)
+
+// NewSolanaClient creates a Solana RPC client backed by a pool of the configured endpoints
+func NewSolanaClient(cfg config.Solana, logger zerolog.Logger) (*rpc.Client, error) {
+	endpoints := make([]Endpoint, 0, len(cfg.RpcEndpoints)+1)
+	for url, weight := range cfg.RpcEndpoints {
+		endpoints = append(endpoints, Endpoint{URL: url, Weight: weight})
+	}
+	if len(endpoints) == 0 {
+		endpoints = append(endpoints, Endpoint{URL: cfg.RpcURL, Weight: 1})
+	}
+	slices.SortFunc(endpoints, func(a, b Endpoint) int {
+		return strings.Compare(a.URL, b.URL)
+	})
+	metrics, err := NewMetrics(otel.Meter("rpcpool"))
+	if err != nil {
+		return nil, fmt.Errorf("create rpc pool metrics: %w", err)
+	}
+	pool, err := New(Config{
+		Endpoints:        endpoints,
+		Hedge:            cfg.RpcHedge,
+		FailureThreshold: cfg.RpcFailureThreshold,
+		Cooldown:         cfg.RpcCooldown,
+	}, metrics, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create rpc pool: %w", err)
+	}
+	return rpc.NewWithCustomRPCClient(pool), nil
+}
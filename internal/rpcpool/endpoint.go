@@ -0,0 +1,165 @@
+package rpcpool
+
+import (
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// ewmaAlpha is the smoothing factor applied to latency and error rate samples
+const ewmaAlpha = 0.2
+
+type Endpoint struct {
+	URL string
+	// Name identifies the endpoint in logs and metrics, it defaults to the scheme and
+	// host of the URL since the path or query may carry an API key
+	Name   string
+	Weight uint
+}
+
+type endpoint struct {
+	Endpoint
+	client jsonrpc.RPCClient
+
+	mu            sync.Mutex
+	latency       time.Duration
+	errorRate     float64
+	failures      int
+	state         breakerState
+	openedAt      time.Time
+	probeInFlight bool
+}
+
+type endpointStats struct {
+	latency   time.Duration
+	errorRate float64
+	state     breakerState
+}
+
+func newEndpoint(e Endpoint) *endpoint {
+	if e.Name == "" {
+		e.Name = redactURL(e.URL)
+	}
+	return &endpoint{
+		Endpoint: e,
+		client:   jsonrpc.NewClient(e.URL),
+	}
+}
+
+// acquire reports whether a request may be sent to the endpoint, moving an open
+// breaker to half-open once the cooldown has passed
+func (e *endpoint) acquire(now time.Time, cooldown time.Duration) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch e.state {
+	case breakerOpen:
+		if now.Sub(e.openedAt) < cooldown {
+			return false
+		}
+		e.state = breakerHalfOpen
+		e.probeInFlight = true
+		return true
+	case breakerHalfOpen:
+		if e.probeInFlight {
+			return false
+		}
+		e.probeInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// score is the effective weight of the endpoint, lowered by latency and error rate
+func (e *endpoint) score() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.state == breakerOpen {
+		return 0
+	}
+	latencyPenalty := 1 + float64(e.latency.Milliseconds())/100
+	return float64(e.Weight) * (1 - e.errorRate) / latencyPenalty
+}
+
+func (e *endpoint) record(latency time.Duration, failed bool, threshold int, now time.Time) (opened bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(ewmaAlpha*float64(latency) + (1-ewmaAlpha)*float64(e.latency))
+	}
+	var sample float64
+	if failed {
+		sample = 1
+	}
+	e.errorRate = ewmaAlpha*sample + (1-ewmaAlpha)*e.errorRate
+	e.probeInFlight = false
+	if !failed {
+		e.failures = 0
+		e.state = breakerClosed
+		return false
+	}
+	e.failures++
+	if e.state == breakerHalfOpen || e.failures >= threshold {
+		e.state = breakerOpen
+		e.openedAt = now
+		return true
+	}
+	return false
+}
+
+// release frees a half-open probe slot for a request whose outcome says nothing
+// about the endpoint health, e.g. a cancelled hedge
+func (e *endpoint) release() {
+	e.mu.Lock()
+	e.probeInFlight = false
+	e.mu.Unlock()
+}
+
+func (e *endpoint) stats() endpointStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return endpointStats{latency: e.latency, errorRate: e.errorRate, state: e.state}
+}
+
+// redactURL keeps only the scheme and host of the endpoint URL
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "invalid"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// redact replaces the URL of the endpoint by its name in errors of the JSON-RPC client,
+// which put the full request URL in their message
+func (e *endpoint) redact(err error) error {
+	if err == nil || e.Name == e.URL || !strings.Contains(err.Error(), e.URL) {
+		return err
+	}
+	return &redactedError{err: err, message: strings.ReplaceAll(err.Error(), e.URL, e.Name)}
+}
+
+type redactedError struct {
+	err     error
+	message string
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
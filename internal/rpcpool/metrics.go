@@ -0,0 +1,90 @@
+package rpcpool
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"time"
+)
+
+type Metrics struct {
+	meter    metric.Meter
+	requests metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+func NewMetrics(meter metric.Meter) (*Metrics, error) {
+	requests, err := meter.Int64Counter("rpc.client.requests",
+		metric.WithDescription("Number of JSON-RPC requests sent per endpoint"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create requests counter: %w", err)
+	}
+	duration, err := meter.Float64Histogram("rpc.client.duration",
+		metric.WithDescription("Duration of JSON-RPC requests per endpoint"),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create duration histogram: %w", err)
+	}
+	return &Metrics{meter: meter, requests: requests, duration: duration}, nil
+}
+
+func (m *Metrics) record(
+	ctx context.Context,
+	endpoint, method string,
+	latency time.Duration,
+	err error,
+	failed bool,
+) {
+	outcome := "ok"
+	if failed {
+		outcome = "endpoint_error"
+	} else if err != nil {
+		outcome = "rpc_error"
+	}
+	attrs := metric.WithAttributes(
+		attribute.String("rpc.endpoint", endpoint),
+		attribute.String("rpc.method", method),
+	)
+	m.requests.Add(ctx, 1, attrs, metric.WithAttributes(attribute.String("rpc.outcome", outcome)))
+	m.duration.Record(ctx, float64(latency.Microseconds())/1000, attrs)
+}
+
+func (m *Metrics) observe(endpoints []*endpoint) error {
+	latency, err := m.meter.Float64ObservableGauge("rpc.endpoint.latency",
+		metric.WithDescription("Smoothed latency of the endpoint"),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return err
+	}
+	errorRate, err := m.meter.Float64ObservableGauge("rpc.endpoint.error_rate",
+		metric.WithDescription("Smoothed error rate of the endpoint"),
+	)
+	if err != nil {
+		return err
+	}
+	healthy, err := m.meter.Int64ObservableGauge("rpc.endpoint.healthy",
+		metric.WithDescription("1 when the endpoint circuit breaker is closed"),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = m.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, e := range endpoints {
+			stats := e.stats()
+			attrs := metric.WithAttributes(attribute.String("rpc.endpoint", e.Name))
+			o.ObserveFloat64(latency, float64(stats.latency.Microseconds())/1000, attrs)
+			o.ObserveFloat64(errorRate, stats.errorRate, attrs)
+			var value int64
+			if stats.state == breakerClosed {
+				value = 1
+			}
+			o.ObserveInt64(healthy, value, attrs)
+		}
+		return nil
+	}, latency, errorRate, healthy)
+	return err
+}
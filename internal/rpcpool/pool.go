@@ -0,0 +1,287 @@
+package rpcpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+var _ rpc.JSONRPCClient = (*Pool)(nil)
+
+var ErrNoEndpoints = errors.New("rpc pool has no endpoints")
+
+// hedgedMethods are sent to several endpoints at once, the first successful answer wins
+var hedgedMethods = map[string]struct{}{
+	"sendTransaction": {},
+}
+
+// rpcErrorCodes are JSON-RPC error codes that indicate a problem with the endpoint
+// rather than with the request itself
+var rpcErrorCodes = map[int]struct{}{
+	429:    {},
+	-32005: {}, // node is behind
+	-32603: {}, // internal error
+}
+
+type Config struct {
+	Endpoints        []Endpoint
+	Hedge            int
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// Pool is a JSON-RPC client balancing requests over several weighted endpoints.
+// Unhealthy endpoints are ejected by a circuit breaker until the cooldown passes.
+type Pool struct {
+	config    Config
+	endpoints []*endpoint
+	metrics   *Metrics
+	logger    zerolog.Logger
+}
+
+func New(config Config, metrics *Metrics, logger zerolog.Logger) (*Pool, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	if config.Hedge < 1 {
+		config.Hedge = 1
+	}
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = 1
+	}
+	endpoints := make([]*endpoint, len(config.Endpoints))
+	for i, e := range config.Endpoints {
+		if e.Weight == 0 {
+			e.Weight = 1
+		}
+		endpoints[i] = newEndpoint(e)
+	}
+	p := &Pool{
+		config:    config,
+		endpoints: endpoints,
+		metrics:   metrics,
+		logger:    logger,
+	}
+	if metrics != nil {
+		if err := metrics.observe(endpoints); err != nil {
+			return nil, fmt.Errorf("register endpoint metrics: %w", err)
+		}
+	}
+	return p, nil
+}
+
+func (p *Pool) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	if _, ok := hedgedMethods[method]; ok && p.config.Hedge > 1 {
+		return p.hedge(ctx, out, method, params)
+	}
+	return p.do(ctx, method, func(ctx context.Context, e *endpoint) error {
+		return e.client.CallForInto(ctx, out, method, params)
+	})
+}
+
+func (p *Pool) CallWithCallback(
+	ctx context.Context,
+	method string,
+	params []interface{},
+	callback func(*http.Request, *http.Response) error,
+) error {
+	return p.do(ctx, method, func(ctx context.Context, e *endpoint) error {
+		return e.client.CallWithCallback(ctx, method, params, callback)
+	})
+}
+
+func (p *Pool) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	var responses jsonrpc.RPCResponses
+	err := p.do(ctx, "batch", func(ctx context.Context, e *endpoint) error {
+		var err error
+		responses, err = e.client.CallBatch(ctx, requests)
+		return err
+	})
+	return responses, err
+}
+
+func (p *Pool) Close() error {
+	for _, e := range p.endpoints {
+		if closer, ok := e.client.(interface{ Close() error }); ok {
+			_ = closer.Close()
+		}
+	}
+	return nil
+}
+
+// do sends the request to the best endpoint, retrying on other endpoints
+// while the failure is caused by the endpoint
+func (p *Pool) do(ctx context.Context, method string, call func(context.Context, *endpoint) error) error {
+	tried := make(map[*endpoint]struct{}, len(p.endpoints))
+	var lastErr error
+	for range p.endpoints {
+		e := p.next(tried)
+		if e == nil {
+			break
+		}
+		tried[e] = struct{}{}
+		err := p.call(ctx, e, method, call)
+		if err == nil || !isEndpointFailure(err) || ctx.Err() != nil {
+			return err
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		return ErrNoEndpoints
+	}
+	return lastErr
+}
+
+func (p *Pool) hedge(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		data json.RawMessage
+		err  error
+	}
+	tried := make(map[*endpoint]struct{}, p.config.Hedge)
+	results := make(chan result, p.config.Hedge)
+	for i := range p.config.Hedge {
+		e := p.pick(tried)
+		if e == nil && i == 0 {
+			e = p.oldestEjected(tried)
+		}
+		if e == nil {
+			break
+		}
+		tried[e] = struct{}{}
+		go func() {
+			var data json.RawMessage
+			err := p.call(ctx, e, method, func(ctx context.Context, e *endpoint) error {
+				return e.client.CallForInto(ctx, &data, method, params)
+			})
+			results <- result{data: data, err: err}
+		}()
+	}
+	if len(tried) == 0 {
+		return ErrNoEndpoints
+	}
+	var errs []error
+	for range tried {
+		res := <-results
+		if res.err == nil {
+			return json.Unmarshal(res.data, out)
+		}
+		errs = append(errs, res.err)
+	}
+	// Prefer an answer from a healthy node over transport failures
+	for _, err := range errs {
+		if !isEndpointFailure(err) {
+			return err
+		}
+	}
+	return errs[0]
+}
+
+func (p *Pool) call(
+	ctx context.Context,
+	e *endpoint,
+	method string,
+	call func(context.Context, *endpoint) error,
+) error {
+	start := time.Now()
+	err := e.redact(call(ctx, e))
+	latency := time.Since(start)
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		e.release()
+		return err
+	}
+	failed := err != nil && isEndpointFailure(err)
+	if e.record(latency, failed, p.config.FailureThreshold, time.Now()) {
+		p.logger.Warn().Err(err).Str("endpoint", e.Name).Msg("rpcpool:endpoint ejected")
+	}
+	if p.metrics != nil {
+		p.metrics.record(ctx, e.Name, method, latency, err, failed)
+	}
+	return err
+}
+
+// next returns the endpoint to use for a request. When every breaker is open,
+// the endpoint that has been ejected the longest is used.
+func (p *Pool) next(exclude map[*endpoint]struct{}) *endpoint {
+	if e := p.pick(exclude); e != nil {
+		return e
+	}
+	return p.oldestEjected(exclude)
+}
+
+// pick selects an available endpoint at random proportionally to its score
+func (p *Pool) pick(exclude map[*endpoint]struct{}) *endpoint {
+	now := time.Now()
+	candidates := make([]*endpoint, 0, len(p.endpoints))
+	scores := make([]float64, 0, len(p.endpoints))
+	var total float64
+	for _, e := range p.endpoints {
+		if _, ok := exclude[e]; ok {
+			continue
+		}
+		if !e.acquire(now, p.config.Cooldown) {
+			continue
+		}
+		score := e.score()
+		candidates = append(candidates, e)
+		scores = append(scores, score)
+		total += score
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	chosen := candidates[0]
+	if total > 0 {
+		target := rand.Float64() * total
+		for i, score := range scores {
+			target -= score
+			if target <= 0 {
+				chosen = candidates[i]
+				break
+			}
+		}
+	}
+	for _, e := range candidates {
+		if e != chosen {
+			e.release()
+		}
+	}
+	return chosen
+}
+
+func (p *Pool) oldestEjected(exclude map[*endpoint]struct{}) *endpoint {
+	var oldest *endpoint
+	var oldestAt time.Time
+	for _, e := range p.endpoints {
+		if _, ok := exclude[e]; ok {
+			continue
+		}
+		e.mu.Lock()
+		openedAt := e.openedAt
+		e.mu.Unlock()
+		if oldest == nil || openedAt.Before(oldestAt) {
+			oldest, oldestAt = e, openedAt
+		}
+	}
+	return oldest
+}
+
+func isEndpointFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) {
+		_, ok := rpcErrorCodes[rpcErr.Code]
+		return ok
+	}
+	return true
+}
@@ -0,0 +1,332 @@
+package rpcpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"math"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testAPIKey = "secret-key"
+
+var errConnectionRefused = errors.New("connection refused")
+
+// fakeClient answers CallForInto with result or err after delay
+type fakeClient struct {
+	jsonrpc.RPCClient
+	url    string
+	delay  time.Duration
+	result string
+	err    error
+
+	calls     atomic.Int32
+	cancelled atomic.Int32
+}
+
+func (f *fakeClient) CallForInto(ctx context.Context, out interface{}, method string, _ []interface{}) error {
+	f.calls.Add(1)
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		f.cancelled.Add(1)
+		return ctx.Err()
+	}
+	if f.err != nil {
+		if _, ok := f.err.(*jsonrpc.RPCError); ok {
+			return f.err
+		}
+		// Like the JSON-RPC client, transport errors carry the request URL
+		return fmt.Errorf("rpc call %v() on %v: %w", method, f.url, f.err)
+	}
+	return json.Unmarshal([]byte(f.result), out)
+}
+
+func newTestPool(t *testing.T, config Config, clients ...*fakeClient) *Pool {
+	t.Helper()
+	for i := range clients {
+		config.Endpoints = append(config.Endpoints, Endpoint{
+			URL:    fmt.Sprintf("https://node-%d.example.com/v1?api-key=%s", i, testAPIKey),
+			Weight: 1,
+		})
+	}
+	pool, err := New(config, nil, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("create pool: %v", err)
+	}
+	for i, client := range clients {
+		client.url = pool.endpoints[i].URL
+		pool.endpoints[i].client = client
+	}
+	return pool
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://mainnet.helius-rpc.com/?api-key=" + testAPIKey, want: "https://mainnet.helius-rpc.com"},
+		{url: "https://solana.quiknode.pro/" + testAPIKey + "/", want: "https://solana.quiknode.pro"},
+		{url: "http://user:" + testAPIKey + "@localhost:8899", want: "http://localhost:8899"},
+		{url: "localhost:8899", want: "invalid"},
+		{url: "://", want: "invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := redactURL(tt.url); got != tt.want {
+				t.Errorf("redactURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndpointBreaker(t *testing.T) {
+	const (
+		threshold = 2
+		cooldown  = time.Minute
+	)
+	start := time.Now()
+	e := newEndpoint(Endpoint{URL: "https://node.example.com", Weight: 1})
+	steps := []struct {
+		name        string
+		at          time.Duration
+		record      *bool
+		wantAcquire bool
+		wantOpened  bool
+		wantState   breakerState
+	}{
+		{name: "closed", wantAcquire: true, wantState: breakerClosed},
+		{name: "first failure", record: ptr(true), wantState: breakerClosed},
+		{name: "success resets failures", record: ptr(false), wantState: breakerClosed},
+		{name: "failure after reset", record: ptr(true), wantState: breakerClosed},
+		{name: "threshold reached", record: ptr(true), wantOpened: true, wantState: breakerOpen},
+		{name: "open during cooldown", at: cooldown / 2, wantAcquire: false, wantState: breakerOpen},
+		{name: "probe after cooldown", at: cooldown, wantAcquire: true, wantState: breakerHalfOpen},
+		{name: "single probe in flight", at: cooldown, wantAcquire: false, wantState: breakerHalfOpen},
+		{name: "failed probe reopens", at: cooldown, record: ptr(true), wantOpened: true, wantState: breakerOpen},
+		{name: "open after failed probe", at: cooldown + cooldown/2, wantAcquire: false, wantState: breakerOpen},
+		{name: "second probe", at: 2 * cooldown, wantAcquire: true, wantState: breakerHalfOpen},
+		{name: "successful probe closes", at: 2 * cooldown, record: ptr(false), wantState: breakerClosed},
+		{name: "closed again", at: 2 * cooldown, wantAcquire: true, wantState: breakerClosed},
+	}
+	for _, step := range steps {
+		now := start.Add(step.at)
+		if step.record != nil {
+			if opened := e.record(time.Millisecond, *step.record, threshold, now); opened != step.wantOpened {
+				t.Fatalf("%s: opened = %t, want %t", step.name, opened, step.wantOpened)
+			}
+		} else if acquired := e.acquire(now, cooldown); acquired != step.wantAcquire {
+			t.Fatalf("%s: acquire = %t, want %t", step.name, acquired, step.wantAcquire)
+		}
+		if state := e.stats().state; state != step.wantState {
+			t.Fatalf("%s: state = %d, want %d", step.name, state, step.wantState)
+		}
+	}
+}
+
+func TestPoolPick(t *testing.T) {
+	type endpointSetup struct {
+		weight  uint
+		latency time.Duration
+		open    bool
+	}
+	tests := []struct {
+		name      string
+		endpoints []endpointSetup
+		want      []float64
+	}{
+		{
+			name:      "weights",
+			endpoints: []endpointSetup{{weight: 1}, {weight: 3}},
+			want:      []float64{0.25, 0.75},
+		},
+		{
+			name:      "latency lowers the score",
+			endpoints: []endpointSetup{{weight: 1, latency: 900 * time.Millisecond}, {weight: 1}},
+			want:      []float64{1. / 11, 10. / 11},
+		},
+		{
+			name:      "open breaker is skipped",
+			endpoints: []endpointSetup{{weight: 5, open: true}, {weight: 1}, {weight: 1}},
+			want:      []float64{0, 0.5, 0.5},
+		},
+	}
+	const picks = 20_000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{FailureThreshold: 1, Cooldown: time.Hour}
+			for i, setup := range tt.endpoints {
+				config.Endpoints = append(config.Endpoints, Endpoint{
+					URL:    fmt.Sprintf("https://node-%d.example.com", i),
+					Weight: setup.weight,
+				})
+			}
+			pool, err := New(config, nil, zerolog.Nop())
+			if err != nil {
+				t.Fatalf("create pool: %v", err)
+			}
+			for i, setup := range tt.endpoints {
+				if setup.latency > 0 {
+					pool.endpoints[i].record(setup.latency, false, 1, time.Now())
+				}
+				if setup.open {
+					pool.endpoints[i].record(time.Millisecond, true, 1, time.Now())
+				}
+			}
+			counts := make(map[*endpoint]int)
+			for range picks {
+				e := pool.pick(nil)
+				if e == nil {
+					t.Fatal("no endpoint picked")
+				}
+				counts[e]++
+			}
+			for i, want := range tt.want {
+				got := float64(counts[pool.endpoints[i]]) / picks
+				if math.Abs(got-want) > 0.02 {
+					t.Errorf("endpoint %d picked %.3f of the time, want %.3f", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPoolFailover(t *testing.T) {
+	invalidParams := &jsonrpc.RPCError{Code: -32602, Message: "invalid params"}
+	tests := []struct {
+		name      string
+		clients   []*fakeClient
+		want      string
+		wantErr   error
+		wantCalls int32 // unchecked when zero, the endpoints are tried in random order
+	}{
+		{
+			name:    "transport error is retried on the next endpoint",
+			clients: []*fakeClient{{err: errConnectionRefused}, {err: errConnectionRefused}, {result: `"ok"`}},
+			want:    "ok",
+		},
+		{
+			name:      "request error is not retried",
+			clients:   []*fakeClient{{err: invalidParams}, {err: invalidParams}},
+			wantErr:   invalidParams,
+			wantCalls: 1,
+		},
+		{
+			name:      "every endpoint failing",
+			clients:   []*fakeClient{{err: errConnectionRefused}, {err: errConnectionRefused}},
+			wantErr:   errConnectionRefused,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newTestPool(t, Config{FailureThreshold: 1, Cooldown: time.Hour}, tt.clients...)
+			var got string
+			err := pool.CallForInto(context.Background(), &got, "getSlot", nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), testAPIKey) {
+				t.Errorf("error leaks the endpoint url: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			var calls int32
+			for _, client := range tt.clients {
+				calls += client.calls.Load()
+			}
+			if tt.wantCalls > 0 && calls != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPoolFailoverEjects(t *testing.T) {
+	failing := &fakeClient{err: errConnectionRefused}
+	healthy := &fakeClient{result: `"ok"`}
+	pool := newTestPool(t, Config{FailureThreshold: 1, Cooldown: time.Hour}, failing, healthy)
+	for range 10 {
+		var got string
+		if err := pool.CallForInto(context.Background(), &got, "getSlot", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if calls := failing.calls.Load(); calls > 1 {
+		t.Errorf("ejected endpoint called %d times, want at most once", calls)
+	}
+}
+
+func TestPoolHedge(t *testing.T) {
+	invalidParams := &jsonrpc.RPCError{Code: -32602, Message: "invalid params"}
+	tests := []struct {
+		name          string
+		clients       []*fakeClient
+		want          string
+		wantErr       error
+		wantCancelled int32
+	}{
+		{
+			name: "fastest answer wins",
+			clients: []*fakeClient{
+				{delay: time.Hour, result: `"slow"`},
+				{result: `"fast"`},
+			},
+			want:          "fast",
+			wantCancelled: 1,
+		},
+		{
+			name: "success after a failure",
+			clients: []*fakeClient{
+				{err: errConnectionRefused},
+				{delay: 20 * time.Millisecond, result: `"sig"`},
+			},
+			want: "sig",
+		},
+		{
+			name: "node answer preferred over transport failure",
+			clients: []*fakeClient{
+				{err: errConnectionRefused},
+				{delay: 20 * time.Millisecond, err: invalidParams},
+			},
+			wantErr: invalidParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newTestPool(t, Config{Hedge: 2, FailureThreshold: 1, Cooldown: time.Hour}, tt.clients...)
+			var got string
+			err := pool.CallForInto(context.Background(), &got, "sendTransaction", nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			// Losing calls are cancelled once the answer is returned
+			time.Sleep(20 * time.Millisecond)
+			var cancelled int32
+			for i, client := range tt.clients {
+				if calls := client.calls.Load(); calls != 1 {
+					t.Errorf("endpoint %d called %d times, want once", i, calls)
+				}
+				cancelled += client.cancelled.Load()
+			}
+			if cancelled != tt.wantCancelled {
+				t.Errorf("got %d cancelled calls, want %d", cancelled, tt.wantCancelled)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"sync/atomic"
+	"time"
+)
+
+type Metrics struct {
+	runs     metric.Int64Counter
+	duration metric.Float64Histogram
+	leader   atomic.Bool
+}
+
+func NewMetrics(meter metric.Meter) (*Metrics, error) {
+	runs, err := meter.Int64Counter("scheduler.job.runs",
+		metric.WithDescription("Number of scheduled job runs per job and outcome"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create runs counter: %w", err)
+	}
+	duration, err := meter.Float64Histogram("scheduler.job.duration",
+		metric.WithDescription("Duration of scheduled job runs per job"),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create duration histogram: %w", err)
+	}
+	m := &Metrics{runs: runs, duration: duration}
+	leader, err := meter.Int64ObservableGauge("scheduler.leader",
+		metric.WithDescription("1 when this instance holds the scheduler leadership"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create leader gauge: %w", err)
+	}
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		var value int64
+		if m.leader.Load() {
+			value = 1
+		}
+		o.ObserveInt64(leader, value)
+		return nil
+	}, leader)
+	if err != nil {
+		return nil, fmt.Errorf("register leader gauge: %w", err)
+	}
+	return m, nil
+}
+
+func (m *Metrics) record(ctx context.Context, job string, latency time.Duration, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	attrs := metric.WithAttributes(attribute.String("job.name", job))
+	m.runs.Add(ctx, 1, attrs, metric.WithAttributes(attribute.String("job.outcome", outcome)))
+	m.duration.Record(ctx, float64(latency.Microseconds())/1000, attrs)
+}
+
+func (m *Metrics) setLeader(leader bool) {
+	m.leader.Store(leader)
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"sync"
+	"time"
+)
+
+// Job runs every Interval while this instance is the leader
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Config struct {
+	// LockKey identifies the advisory lock replicas compete for
+	LockKey int64
+	// LeaderCheckInterval is how often followers try to take the lock and
+	// the leader checks that its session, and so the lock, is still alive
+	LeaderCheckInterval time.Duration
+}
+
+// Scheduler runs jobs on a single replica. Replicas elect the leader with a session level
+// Postgres advisory lock held on a dedicated connection, the lock is released when the
+// connection closes so a crashed leader is replaced after one check interval.
+type Scheduler struct {
+	config  Config
+	pool    *pgxpool.Pool
+	jobs    []Job
+	metrics *Metrics
+	logger  zerolog.Logger
+}
+
+func New(config Config, pool *pgxpool.Pool, jobs []Job, metrics *Metrics, logger zerolog.Logger) *Scheduler {
+	return &Scheduler{
+		config:  config,
+		pool:    pool,
+		jobs:    jobs,
+		metrics: metrics,
+		logger:  logger,
+	}
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.LeaderCheckInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.lead(ctx); err != nil {
+			s.logger.Err(err).Msg("scheduler:lead failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead takes the lock if it is free and runs the jobs for as long as the lock is held
+func (s *Scheduler) lead(ctx context.Context) error {
+	const TryLockQuery = `SELECT pg_try_advisory_lock($1);`
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	var locked bool
+	if err = conn.QueryRow(ctx, TryLockQuery, s.config.LockKey).Scan(&locked); err != nil || !locked {
+		conn.Release()
+		if err != nil {
+			return fmt.Errorf("try lock: %w", err)
+		}
+		return nil
+	}
+	// Closing the session is what releases the lock, never return the connection to the pool
+	session := conn.Hijack()
+	defer session.Close(context.WithoutCancel(ctx))
+
+	s.logger.Info().Msg("scheduler:elected leader")
+	s.setLeader(true)
+	defer s.setLeader(false)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runJob(leaderCtx, job)
+		}()
+	}
+	defer wg.Wait()
+	defer cancel()
+
+	ticker := time.NewTicker(s.config.LeaderCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err = session.Ping(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("ping lock session: %w", err)
+		}
+	}
+}
+
+func (s *Scheduler) runJob(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		err := job.Run(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if s.metrics != nil {
+			s.metrics.record(ctx, job.Name, time.Since(start), err)
+		}
+		if err != nil {
+			s.logger.Err(err).Str("job", job.Name).Msg("scheduler:job failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) setLeader(leader bool) {
+	if s.metrics != nil {
+		s.metrics.setLeader(leader)
+	}
+}
@@ -0,0 +1,131 @@
+package settlement
+
+import (
+	"errors"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"math/bits"
+	"slices"
+	"strings"
+)
+
+const bpsDenominator = 10_000
+
+var (
+	ErrNotResolved = errors.New("market is not resolved")
+	ErrNotFinal    = errors.New("market resolution is not final")
+)
+
+// Fees are taken from the losing pool, in basis points
+type Fees struct {
+	ProtocolBps uint32
+	CreatorBps  uint32
+}
+
+// capped keeps the total fee within the losing pool
+func (f Fees) capped() Fees {
+	f.ProtocolBps = min(f.ProtocolBps, bpsDenominator)
+	f.CreatorBps = min(f.CreatorBps, bpsDenominator-f.ProtocolBps)
+	return f
+}
+
+// Calculate splits the pool of a resolved market between wallets. Winners get their stake
+// back plus a pro-rata share of the losing pool after protocol and creator fees. TIE markets
+// and markets without winning stake refund every wallet in full. Rounding dust goes to the protocol.
+// Categorical markets resolved to an outcome pay positions on that outcome, scalar markets
+// are split by CalculateScalar.
+func Calculate(
+	market prediction.Market,
+	positions []prediction.Position,
+	fees Fees,
+) (prediction.Settlement, []prediction.Payout, error) {
+	if market.Resolution == prediction.MarketResolutionValue {
+		return CalculateScalar(market, positions, fees)
+	}
+	var winning prediction.PositionSide
+	switch market.Resolution {
+	case prediction.MarketResolutionYes:
+		winning = prediction.PositionSideYes
+	case prediction.MarketResolutionNo:
+		winning = prediction.PositionSideNo
+	case prediction.MarketResolutionOutcome:
+		if !market.ValidResolution(market.Resolution, market.ResolvedOutcome, market.ResolvedValue) {
+			return prediction.Settlement{}, nil, ErrNotResolved
+		}
+		winning = prediction.PositionSideYes
+	case prediction.MarketResolutionTie:
+	default:
+		return prediction.Settlement{}, nil, ErrNotResolved
+	}
+	wins := func(position prediction.Position) bool {
+		if market.Resolution == prediction.MarketResolutionOutcome {
+			return position.OutcomeID == market.ResolvedOutcome
+		}
+		return winning != "" && position.Side == winning
+	}
+	fees = fees.capped()
+
+	type stake struct {
+		total   uint64
+		winning uint64
+	}
+	stakes := make(map[string]*stake)
+	var totalPool, winningPool uint64
+	for _, position := range positions {
+		amount := uint64(max(position.Amount, 0))
+		s, ok := stakes[position.OwnerPubkey]
+		if !ok {
+			s = new(stake)
+			stakes[position.OwnerPubkey] = s
+		}
+		s.total += amount
+		totalPool += amount
+		if wins(position) {
+			s.winning += amount
+			winningPool += amount
+		}
+	}
+
+	settlement := prediction.Settlement{
+		MarketID:    market.ID,
+		Resolution:  market.Resolution,
+		TotalPool:   int64(totalPool),
+		WinningPool: int64(winningPool),
+		FeeBps:      int32(fees.ProtocolBps),
+
+		CreatorFeeBps:    int32(fees.CreatorBps),
+		WinningOutcomeID: market.ResolvedOutcome,
+	}
+	payouts := make([]prediction.Payout, 0, len(stakes))
+	refund := winning == "" || winningPool == 0
+	losingPool := totalPool - winningPool
+	protocolFee := mulDiv(losingPool, uint64(fees.ProtocolBps), bpsDenominator)
+	creatorFee := mulDiv(losingPool, uint64(fees.CreatorBps), bpsDenominator)
+	distributable := losingPool - protocolFee - creatorFee
+	var distributed uint64
+	for owner, s := range stakes {
+		payout := prediction.Payout{MarketID: market.ID, OwnerPubkey: owner, Stake: int64(s.total)}
+		if refund {
+			payout.Payout = int64(s.total)
+		} else if s.winning > 0 {
+			share := mulDiv(s.winning, distributable, winningPool)
+			distributed += share
+			payout.Payout = int64(s.winning + share)
+		}
+		payouts = append(payouts, payout)
+	}
+	if !refund {
+		settlement.FeeAmount = int64(protocolFee + distributable - distributed)
+		settlement.CreatorFeeAmount = int64(creatorFee)
+	}
+	slices.SortFunc(payouts, func(a, b prediction.Payout) int {
+		return strings.Compare(a.OwnerPubkey, b.OwnerPubkey)
+	})
+	return settlement, payouts, nil
+}
+
+// mulDiv returns floor(a*b/c) for a*b/c < 2^64 without overflowing the intermediate product
+func mulDiv(a, b, c uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	quo, _ := bits.Div64(hi, lo, c)
+	return quo
+}
@@ -0,0 +1,210 @@
+package settlement
+
+import (
+	"errors"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/jackc/pgx/v5/pgtype"
+	"maps"
+	"testing"
+)
+
+func binaryMarket(resolution prediction.MarketResolution) prediction.Market {
+	return prediction.Market{
+		ID:         "binary",
+		Kind:       prediction.MarketKindBinary,
+		Resolution: resolution,
+	}
+}
+
+// categoricalMarket has three outcomes and is resolved to outcome, or TIE when outcome is negative
+func categoricalMarket(outcome int16) prediction.Market {
+	market := prediction.Market{
+		ID:         "categorical",
+		Kind:       prediction.MarketKindCategorical,
+		Resolution: prediction.MarketResolutionTie,
+		Outcomes: []prediction.MarketOutcome{
+			{ID: 0, Name: "red"},
+			{ID: 1, Name: "green"},
+			{ID: 2, Name: "blue"},
+		},
+	}
+	if outcome >= 0 {
+		market.Resolution = prediction.MarketResolutionOutcome
+		market.ResolvedOutcome = pgtype.Int2{Int16: outcome, Valid: true}
+	}
+	return market
+}
+
+func binaryPosition(owner string, side prediction.PositionSide, amount int64) prediction.Position {
+	return prediction.Position{MarketID: "binary", OwnerPubkey: owner, Side: side, Amount: amount}
+}
+
+func outcomePosition(owner string, outcome int16, amount int64) prediction.Position {
+	return prediction.Position{
+		MarketID:    "categorical",
+		OwnerPubkey: owner,
+		Side:        prediction.PositionSideYes,
+		OutcomeID:   pgtype.Int2{Int16: outcome, Valid: true},
+		Amount:      amount,
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	// alice is YES 600, bob NO 400
+	balanced := []prediction.Position{
+		binaryPosition("alice", prediction.PositionSideYes, 600),
+		binaryPosition("bob", prediction.PositionSideNo, 400),
+	}
+	// bob and dave back green, the resolved outcome
+	categorical := []prediction.Position{
+		outcomePosition("alice", 0, 300),
+		outcomePosition("bob", 1, 200),
+		outcomePosition("carol", 2, 500),
+		outcomePosition("dave", 1, 100),
+	}
+	fees := Fees{ProtocolBps: 200, CreatorBps: 100}
+	tests := []struct {
+		name      string
+		market    prediction.Market
+		positions []prediction.Position
+		fees      Fees
+		want      map[string]int64
+		// wantFee and wantCreatorFee are zero for refunds
+		wantFee        int64
+		wantCreatorFee int64
+	}{
+		{
+			// YES takes the NO pool of 400, less 8 protocol and 4 creator fee
+			name: "yes", market: binaryMarket(prediction.MarketResolutionYes), positions: balanced, fees: fees,
+			want:    map[string]int64{"alice": 988, "bob": 0},
+			wantFee: 8, wantCreatorFee: 4,
+		},
+		{
+			name: "no", market: binaryMarket(prediction.MarketResolutionNo), positions: balanced, fees: fees,
+			want:    map[string]int64{"alice": 0, "bob": 982},
+			wantFee: 12, wantCreatorFee: 6,
+		},
+		{
+			name: "tie refund", market: binaryMarket(prediction.MarketResolutionTie), positions: balanced, fees: fees,
+			want: map[string]int64{"alice": 600, "bob": 400},
+		},
+		{
+			name:   "no winning stake refund",
+			market: binaryMarket(prediction.MarketResolutionYes),
+			positions: []prediction.Position{
+				binaryPosition("bob", prediction.PositionSideNo, 400),
+				binaryPosition("dave", prediction.PositionSideNo, 100),
+				binaryPosition("bob", prediction.PositionSideNo, 50),
+			},
+			fees: fees,
+			want: map[string]int64{"bob": 450, "dave": 100},
+		},
+		{
+			// Fees on the losing pool of 10 round down to zero, shares of 10/3 and 20/3
+			// round down and the unit of dust goes to the protocol
+			name:   "dust to protocol",
+			market: binaryMarket(prediction.MarketResolutionYes),
+			positions: []prediction.Position{
+				binaryPosition("alice", prediction.PositionSideYes, 1),
+				binaryPosition("bob", prediction.PositionSideNo, 10),
+				binaryPosition("carol", prediction.PositionSideYes, 2),
+			},
+			fees:    Fees{ProtocolBps: 900, CreatorBps: 50},
+			want:    map[string]int64{"alice": 4, "bob": 0, "carol": 8},
+			wantFee: 1,
+		},
+		{
+			// alice loses her NO stake of 100 along with bob, the creator fee of 3.5 rounds down
+			name:   "hedged wallet",
+			market: binaryMarket(prediction.MarketResolutionYes),
+			positions: []prediction.Position{
+				binaryPosition("alice", prediction.PositionSideYes, 300),
+				binaryPosition("alice", prediction.PositionSideNo, 100),
+				binaryPosition("bob", prediction.PositionSideNo, 600),
+			},
+			fees:    Fees{ProtocolBps: 100, CreatorBps: 50},
+			want:    map[string]int64{"alice": 990, "bob": 0},
+			wantFee: 7, wantCreatorFee: 3,
+		},
+		{
+			// The creator fee is capped to the 10% of the losing pool left by the protocol fee
+			name: "capped fees", market: binaryMarket(prediction.MarketResolutionYes), positions: balanced,
+			fees:    Fees{ProtocolBps: 9_000, CreatorBps: 5_000},
+			want:    map[string]int64{"alice": 600, "bob": 0},
+			wantFee: 360, wantCreatorFee: 40,
+		},
+		{
+			name: "no fees", market: binaryMarket(prediction.MarketResolutionNo), positions: balanced,
+			want: map[string]int64{"alice": 0, "bob": 1_000},
+		},
+		{
+			// green splits the losing pool of 800 less 16 protocol and 8 creator fee, shares
+			// of 517.33 and 258.67 round down and the unit of dust goes to the protocol
+			name: "categorical outcome", market: categoricalMarket(1), positions: categorical, fees: fees,
+			want:    map[string]int64{"alice": 0, "bob": 717, "carol": 0, "dave": 358},
+			wantFee: 17, wantCreatorFee: 8,
+		},
+		{
+			name: "categorical tie refund", market: categoricalMarket(-1), positions: categorical, fees: fees,
+			want: map[string]int64{"alice": 300, "bob": 200, "carol": 500, "dave": 100},
+		},
+		{
+			name:   "categorical no winning stake refund",
+			market: categoricalMarket(2),
+			positions: []prediction.Position{
+				outcomePosition("alice", 0, 300),
+				outcomePosition("bob", 1, 200),
+			},
+			fees: fees,
+			want: map[string]int64{"alice": 300, "bob": 200},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settlement, payouts, err := Calculate(tt.market, tt.positions, tt.fees)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			var totalPool int64
+			for _, position := range tt.positions {
+				totalPool += position.Amount
+			}
+			if settlement.TotalPool != totalPool {
+				t.Fatalf("TotalPool = %d, want %d", settlement.TotalPool, totalPool)
+			}
+			got := make(map[string]int64, len(payouts))
+			var paid int64
+			for _, payout := range payouts {
+				got[payout.OwnerPubkey] = payout.Payout
+				paid += payout.Payout
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Fatalf("payouts = %v, want %v", got, tt.want)
+			}
+			if settlement.FeeAmount != tt.wantFee || settlement.CreatorFeeAmount != tt.wantCreatorFee {
+				t.Fatalf("fees = %d and %d, want %d and %d",
+					settlement.FeeAmount, settlement.CreatorFeeAmount, tt.wantFee, tt.wantCreatorFee)
+			}
+			if sum := paid + settlement.FeeAmount + settlement.CreatorFeeAmount; sum != settlement.TotalPool {
+				t.Fatalf("payouts %d + fees %d + %d = %d, want TotalPool %d", paid,
+					settlement.FeeAmount, settlement.CreatorFeeAmount, sum, settlement.TotalPool)
+			}
+		})
+	}
+}
+
+func TestCalculateNotResolved(t *testing.T) {
+	outOfRange := categoricalMarket(3)
+	binaryOutcome := categoricalMarket(1)
+	binaryOutcome.Kind = prediction.MarketKindBinary
+	for name, market := range map[string]prediction.Market{
+		"unresolved":                   binaryMarket(prediction.MarketResolutionUnresolved),
+		"unknown resolution":           binaryMarket("MAYBE"),
+		"outcome out of range":         outOfRange,
+		"outcome resolution of binary": binaryOutcome,
+	} {
+		if _, _, err := Calculate(market, nil, Fees{}); !errors.Is(err, ErrNotResolved) {
+			t.Fatalf("Calculate(%s) error = %v, want %v", name, err, ErrNotResolved)
+		}
+	}
+}
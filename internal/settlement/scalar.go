@@ -0,0 +1,113 @@
+package settlement
+
+import (
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"math"
+	"slices"
+	"strings"
+)
+
+// scalarPrecision is the resolution of the position of the value within the range
+const scalarPrecision = 1_000_000
+
+// CalculateScalar splits the pool of a scalar market resolved to a value. The value is
+// clamped to the range of the market and the LONG (YES) side is allotted the share of the
+// total pool given by where the value lands in the range, SHORT (NO) the rest, so the
+// bounds reduce to a binary YES or NO win. The side allotted more than it staked gains
+// the difference from the other side, protocol and creator fees are taken from that
+// transfer. Markets without stake on either side refund every wallet in full.
+// Rounding dust goes to the protocol.
+func CalculateScalar(
+	market prediction.Market,
+	positions []prediction.Position,
+	fees Fees,
+) (prediction.Settlement, []prediction.Payout, error) {
+	if !market.ValidResolution(market.Resolution, market.ResolvedOutcome, market.ResolvedValue) ||
+		market.Resolution != prediction.MarketResolutionValue ||
+		!market.ScalarLower.Valid || !market.ScalarUpper.Valid ||
+		market.ScalarLower.Float64 >= market.ScalarUpper.Float64 {
+		return prediction.Settlement{}, nil, ErrNotResolved
+	}
+	fees = fees.capped()
+
+	type stake struct {
+		long  uint64
+		short uint64
+	}
+	stakes := make(map[string]*stake)
+	var longPool, shortPool uint64
+	for _, position := range positions {
+		amount := uint64(max(position.Amount, 0))
+		s, ok := stakes[position.OwnerPubkey]
+		if !ok {
+			s = new(stake)
+			stakes[position.OwnerPubkey] = s
+		}
+		if position.Side == prediction.PositionSideNo {
+			s.short += amount
+			shortPool += amount
+		} else {
+			s.long += amount
+			longPool += amount
+		}
+	}
+	totalPool := longPool + shortPool
+	longAllotment := mulDiv(totalPool, scalarFraction(market), scalarPrecision)
+
+	// The gaining side receives transfer from the losing one
+	longGains := longAllotment >= longPool
+	gainingPool, losingPool, transfer := longPool, shortPool, longAllotment-longPool
+	if !longGains {
+		gainingPool, losingPool, transfer = shortPool, longPool, longPool-longAllotment
+	}
+	refund := longPool == 0 || shortPool == 0
+	protocolFee := mulDiv(transfer, uint64(fees.ProtocolBps), bpsDenominator)
+	creatorFee := mulDiv(transfer, uint64(fees.CreatorBps), bpsDenominator)
+	distributable := transfer - protocolFee - creatorFee
+
+	settlement := prediction.Settlement{
+		MarketID:    market.ID,
+		Resolution:  market.Resolution,
+		TotalPool:   int64(totalPool),
+		WinningPool: int64(gainingPool),
+		FeeBps:      int32(fees.ProtocolBps),
+
+		CreatorFeeBps: int32(fees.CreatorBps),
+		ResolvedValue: market.ResolvedValue,
+	}
+	payouts := make([]prediction.Payout, 0, len(stakes))
+	var paid uint64
+	for owner, s := range stakes {
+		total := s.long + s.short
+		payout := prediction.Payout{MarketID: market.ID, OwnerPubkey: owner, Stake: int64(total)}
+		if refund {
+			payout.Payout = int64(total)
+		} else {
+			gaining, losing := s.long, s.short
+			if !longGains {
+				gaining, losing = s.short, s.long
+			}
+			amount := gaining + mulDiv(gaining, distributable, gainingPool) +
+				mulDiv(losing, losingPool-transfer, losingPool)
+			paid += amount
+			payout.Payout = int64(amount)
+		}
+		payouts = append(payouts, payout)
+	}
+	if !refund {
+		settlement.FeeAmount = int64(totalPool - creatorFee - paid)
+		settlement.CreatorFeeAmount = int64(creatorFee)
+	}
+	slices.SortFunc(payouts, func(a, b prediction.Payout) int {
+		return strings.Compare(a.OwnerPubkey, b.OwnerPubkey)
+	})
+	return settlement, payouts, nil
+}
+
+// scalarFraction is where the resolved value lands in the range of the market,
+// in units of 1/scalarPrecision
+func scalarFraction(market prediction.Market) uint64 {
+	lower, upper := market.ScalarLower.Float64, market.ScalarUpper.Float64
+	value := math.Min(math.Max(market.ResolvedValue.Float64, lower), upper)
+	return uint64(math.Round((value - lower) / (upper - lower) * scalarPrecision))
+}
@@ -0,0 +1,160 @@
+package settlement
+
+import (
+	"errors"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/jackc/pgx/v5/pgtype"
+	"maps"
+	"testing"
+)
+
+func scalarMarket(value float64) prediction.Market {
+	return prediction.Market{
+		ID:            "scalar",
+		Kind:          prediction.MarketKindScalar,
+		Resolution:    prediction.MarketResolutionValue,
+		ScalarLower:   pgtype.Float8{Float64: 0, Valid: true},
+		ScalarUpper:   pgtype.Float8{Float64: 100, Valid: true},
+		ResolvedValue: pgtype.Float8{Float64: value, Valid: true},
+	}
+}
+
+func scalarPosition(owner string, side prediction.PositionSide, amount int64) prediction.Position {
+	return prediction.Position{MarketID: "scalar", OwnerPubkey: owner, Side: side, Amount: amount}
+}
+
+func TestCalculateScalar(t *testing.T) {
+	// alice is LONG 600, bob SHORT 400
+	balanced := []prediction.Position{
+		scalarPosition("alice", prediction.PositionSideYes, 600),
+		scalarPosition("bob", prediction.PositionSideNo, 400),
+	}
+	fees := Fees{ProtocolBps: 200, CreatorBps: 100}
+	tests := []struct {
+		name      string
+		value     float64
+		positions []prediction.Position
+		fees      Fees
+		want      map[string]int64
+		// wantFee and wantCreatorFee are zero for refunds
+		wantFee        int64
+		wantCreatorFee int64
+	}{
+		{
+			// SHORT takes the whole LONG pool of 600, less 12 protocol and 6 creator fee
+			name: "lower bound", value: 0, positions: balanced, fees: fees,
+			want:    map[string]int64{"alice": 0, "bob": 982},
+			wantFee: 12, wantCreatorFee: 6,
+		},
+		{
+			name: "upper bound", value: 100, positions: balanced, fees: fees,
+			want:    map[string]int64{"alice": 988, "bob": 0},
+			wantFee: 8, wantCreatorFee: 4,
+		},
+		{
+			// LONG is allotted 500 of the 1000 pool, so 100 moves to SHORT
+			name: "midpoint", value: 50, positions: balanced, fees: fees,
+			want:    map[string]int64{"alice": 500, "bob": 497},
+			wantFee: 2, wantCreatorFee: 1,
+		},
+		{
+			name: "above range", value: 150, positions: balanced, fees: fees,
+			want:    map[string]int64{"alice": 988, "bob": 0},
+			wantFee: 8, wantCreatorFee: 4,
+		},
+		{
+			name: "below range", value: -20, positions: balanced, fees: fees,
+			want:    map[string]int64{"alice": 0, "bob": 982},
+			wantFee: 12, wantCreatorFee: 6,
+		},
+		{
+			name:  "one-sided refund",
+			value: 80,
+			positions: []prediction.Position{
+				scalarPosition("alice", prediction.PositionSideYes, 600),
+				scalarPosition("carol", prediction.PositionSideYes, 150),
+				scalarPosition("alice", prediction.PositionSideYes, 50),
+			},
+			fees: fees,
+			want: map[string]int64{"alice": 650, "carol": 150},
+		},
+		{
+			// Fees on the transfer of 10 round down to zero, shares of 10/3 and 20/3
+			// round down and the unit of dust goes to the protocol
+			name:  "fee rounding",
+			value: 0,
+			positions: []prediction.Position{
+				scalarPosition("alice", prediction.PositionSideYes, 10),
+				scalarPosition("bob", prediction.PositionSideNo, 1),
+				scalarPosition("dave", prediction.PositionSideNo, 2),
+			},
+			fees:    Fees{ProtocolBps: 900, CreatorBps: 50},
+			want:    map[string]int64{"alice": 0, "bob": 4, "dave": 8},
+			wantFee: 1,
+		},
+		{
+			// alice holds both sides, LONG loses 83 of which 1 is protocol fee,
+			// the creator fee rounds down to zero and a unit of dust is left
+			name:  "hedged wallet",
+			value: 25,
+			positions: []prediction.Position{
+				scalarPosition("alice", prediction.PositionSideYes, 333),
+				scalarPosition("alice", prediction.PositionSideNo, 100),
+				scalarPosition("bob", prediction.PositionSideNo, 567),
+			},
+			fees:    Fees{ProtocolBps: 150, CreatorBps: 75},
+			want:    map[string]int64{"alice": 362, "bob": 636},
+			wantFee: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settlement, payouts, err := CalculateScalar(scalarMarket(tt.value), tt.positions, tt.fees)
+			if err != nil {
+				t.Fatalf("CalculateScalar() error = %v", err)
+			}
+			var totalPool int64
+			for _, position := range tt.positions {
+				totalPool += position.Amount
+			}
+			if settlement.TotalPool != totalPool {
+				t.Fatalf("TotalPool = %d, want %d", settlement.TotalPool, totalPool)
+			}
+			got := make(map[string]int64, len(payouts))
+			var paid int64
+			for _, payout := range payouts {
+				got[payout.OwnerPubkey] = payout.Payout
+				paid += payout.Payout
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Fatalf("payouts = %v, want %v", got, tt.want)
+			}
+			if settlement.FeeAmount != tt.wantFee || settlement.CreatorFeeAmount != tt.wantCreatorFee {
+				t.Fatalf("fees = %d and %d, want %d and %d",
+					settlement.FeeAmount, settlement.CreatorFeeAmount, tt.wantFee, tt.wantCreatorFee)
+			}
+			if sum := paid + settlement.FeeAmount + settlement.CreatorFeeAmount; sum != settlement.TotalPool {
+				t.Fatalf("payouts %d + fees %d + %d = %d, want TotalPool %d", paid,
+					settlement.FeeAmount, settlement.CreatorFeeAmount, sum, settlement.TotalPool)
+			}
+		})
+	}
+}
+
+func TestCalculateScalarNotResolved(t *testing.T) {
+	unresolved := scalarMarket(50)
+	unresolved.Resolution = prediction.MarketResolutionUnresolved
+	noRange := scalarMarket(50)
+	noRange.ScalarUpper = pgtype.Float8{Float64: 0, Valid: true}
+	binary := scalarMarket(50)
+	binary.Kind = prediction.MarketKindBinary
+	for name, market := range map[string]prediction.Market{
+		"unresolved":  unresolved,
+		"empty range": noRange,
+		"binary":      binary,
+	} {
+		if _, _, err := CalculateScalar(market, nil, Fees{}); !errors.Is(err, ErrNotResolved) {
+			t.Fatalf("CalculateScalar(%s) error = %v, want %v", name, err, ErrNotResolved)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package settlement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IndexStorm/hit-my-bet-back/internal/fees"
+	"github.com/IndexStorm/hit-my-bet-back/internal/notify"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/dispute"
+	"github.com/IndexStorm/hit-my-bet-back/internal/repository/prediction"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// Settler computes settlement snapshots of markets once their resolution is final, records
+// their fees in the ledger and serves the stored snapshots after
+type Settler struct {
+	predictionRepo prediction.Repository
+	disputeRepo    dispute.Repository
+	accountant     *fees.Accountant
+	notifier       *notify.Notifier
+}
+
+func NewSettler(
+	predictionRepo prediction.Repository,
+	disputeRepo dispute.Repository,
+	accountant *fees.Accountant,
+	notifier *notify.Notifier,
+) *Settler {
+	return &Settler{
+		predictionRepo: predictionRepo,
+		disputeRepo:    disputeRepo,
+		accountant:     accountant,
+		notifier:       notifier,
+	}
+}
+
+func (s *Settler) Settle(ctx context.Context, market prediction.Market) (prediction.Settlement, []prediction.Payout, error) {
+	settlement, err := s.predictionRepo.GetSettlement(ctx, market.ID)
+	if err == nil {
+		return s.withPayouts(ctx, settlement)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return prediction.Settlement{}, nil, fmt.Errorf("get settlement: %w", err)
+	}
+	// Oracles propose resolutions of markets that are still unresolved on chain
+	resolution, err := s.disputeRepo.GetResolution(ctx, market.ID)
+	if errors.Is(err, pgx.ErrNoRows) && market.Resolution == prediction.MarketResolutionUnresolved {
+		return prediction.Settlement{}, nil, ErrNotResolved
+	} else if errors.Is(err, pgx.ErrNoRows) {
+		return prediction.Settlement{}, nil, ErrNotFinal
+	} else if err != nil {
+		return prediction.Settlement{}, nil, fmt.Errorf("get resolution: %w", err)
+	}
+	if !resolution.Final() {
+		return prediction.Settlement{}, nil, ErrNotFinal
+	}
+	// An arbiter may have overturned the outcome reported on chain
+	market.Resolution = resolution.FinalOutcome
+	market.ResolvedOutcome = resolution.FinalOutcomeID
+	market.ResolvedValue = resolution.FinalValue
+	positions, err := s.predictionRepo.GetMarketPositions(ctx, market.ID)
+	if err != nil {
+		return prediction.Settlement{}, nil, fmt.Errorf("get positions: %w", err)
+	}
+	schedule, err := s.accountant.Schedule(ctx, market)
+	if err != nil {
+		return prediction.Settlement{}, nil, err
+	}
+	positionFees, err := s.accountant.PositionFees(ctx, market)
+	if err != nil {
+		return prediction.Settlement{}, nil, err
+	}
+	// The pool is split over stakes net of the position fees already taken from them,
+	// payouts still report the gross stake of each wallet
+	stakes := make(map[string]int64)
+	for i, position := range positions {
+		stakes[position.OwnerPubkey] += position.Amount
+		positions[i].Amount = max(position.Amount-positionFees[position.PositionPubkey], 0)
+	}
+	settlement, payouts, err := Calculate(market, positions, Fees{
+		ProtocolBps: uint32(max(schedule.SettlementProtocolBps, 0)),
+		CreatorBps:  uint32(max(schedule.SettlementCreatorBps, 0)),
+	})
+	if err != nil {
+		return prediction.Settlement{}, nil, err
+	}
+	for i := range payouts {
+		payouts[i].Stake = stakes[payouts[i].OwnerPubkey]
+	}
+	settlement.CreatedAt = time.Now()
+	err = s.predictionRepo.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.predictionRepo.SaveSettlement(ctx, settlement, payouts); err != nil {
+			return err
+		}
+		// Another instance may have settled the market first, account whatever was stored
+		if settlement, err = s.predictionRepo.GetSettlement(ctx, market.ID); err != nil {
+			return fmt.Errorf("get settlement: %w", err)
+		}
+		if err := s.accountant.AccrueSettlement(ctx, market, settlement); err != nil {
+			return err
+		}
+		return s.notifier.PositionsSettled(ctx, market, payouts)
+	})
+	if err != nil {
+		return prediction.Settlement{}, nil, fmt.Errorf("save settlement: %w", err)
+	}
+	return s.withPayouts(ctx, settlement)
+}
+
+func (s *Settler) withPayouts(
+	ctx context.Context,
+	settlement prediction.Settlement,
+) (prediction.Settlement, []prediction.Payout, error) {
+	payouts, err := s.predictionRepo.GetSettlementPayouts(ctx, settlement.MarketID)
+	if err != nil {
+		return prediction.Settlement{}, nil, fmt.Errorf("get payouts: %w", err)
+	}
+	return settlement, payouts, nil
+}
@@ -0,0 +1,103 @@
+package sns
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"strings"
+)
+
+const (
+	// hashPrefix is prepended to names before hashing them into registry seeds
+	hashPrefix = "SPL Name Service"
+	// registryHeaderLength covers the parent, owner and class keys of a name registry account
+	registryHeaderLength = 96
+	maxLabelLength       = 63
+)
+
+var (
+	// NameServiceProgramID owns every name registry account
+	NameServiceProgramID = solana.MustPublicKeyFromBase58("namesLPneVptA9Z5rqUDD9tMTWEJwofgaYwp8cawRkX")
+	// RootDomain is the parent registry of .sol domains
+	RootDomain = solana.MustPublicKeyFromBase58("58PwtjSDuFHuUkYjH9BYnnQKHfwo9reZhC2zMJv9JPkx")
+
+	ErrInvalidName  = errors.New("name is not a valid .sol domain")
+	ErrNameNotFound = errors.New("name is not registered")
+)
+
+// AccountReader is the part of the RPC client the resolver needs
+type AccountReader interface {
+	GetAccountInfo(ctx context.Context, account solana.PublicKey) (*rpc.GetAccountInfoResult, error)
+}
+
+// Resolver resolves Solana Name Service .sol domains and their subdomains to the
+// wallets owning them
+type Resolver struct {
+	reader AccountReader
+}
+
+func NewResolver(reader AccountReader) *Resolver {
+	return &Resolver{reader: reader}
+}
+
+// Normalize lowercases the name and checks it is "name.sol" or "sub.name.sol"
+func Normalize(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	labels := strings.Split(strings.TrimSuffix(name, ".sol"), ".")
+	if !strings.HasSuffix(name, ".sol") || len(labels) > 2 {
+		return "", ErrInvalidName
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > maxLabelLength {
+			return "", ErrInvalidName
+		}
+	}
+	return name, nil
+}
+
+// Owner returns the wallet owning the registry of the name
+func (r *Resolver) Owner(ctx context.Context, name string) (solana.PublicKey, error) {
+	key, err := DomainKey(name)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	info, err := r.reader.GetAccountInfo(ctx, key)
+	if errors.Is(err, rpc.ErrNotFound) {
+		return solana.PublicKey{}, ErrNameNotFound
+	} else if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("get name registry: %w", err)
+	}
+	if info == nil || info.Value == nil {
+		return solana.PublicKey{}, ErrNameNotFound
+	}
+	data := info.Value.Data.GetBinary()
+	if len(data) < registryHeaderLength || !info.Value.Owner.Equals(NameServiceProgramID) {
+		return solana.PublicKey{}, ErrNameNotFound
+	}
+	return solana.PublicKeyFromBytes(data[32:64]), nil
+}
+
+// DomainKey derives the registry account of the name, subdomains live under the registry
+// of their parent domain and hash their label with a leading zero byte
+func DomainKey(name string) (solana.PublicKey, error) {
+	name, err := Normalize(name)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	labels := strings.Split(strings.TrimSuffix(name, ".sol"), ".")
+	domain, err := nameKey(labels[len(labels)-1], RootDomain)
+	if err != nil || len(labels) == 1 {
+		return domain, err
+	}
+	return nameKey("\x00"+labels[0], domain)
+}
+
+func nameKey(label string, parent solana.PublicKey) (solana.PublicKey, error) {
+	hashed := sha256.Sum256([]byte(hashPrefix + label))
+	var class solana.PublicKey
+	key, _, err := solana.FindProgramAddress([][]byte{hashed[:], class[:], parent[:]}, NameServiceProgramID)
+	return key, err
+}
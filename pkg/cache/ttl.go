@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLoadPanicked is returned to callers waiting on a load that panicked
+var ErrLoadPanicked = errors.New("cache loader panicked")
+
+// TTL is an in-memory cache whose entries expire a fixed duration after being loaded.
+// Concurrent loads of the same missing key share a single call to the loader.
+type TTL[K comparable, V any] struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu       sync.Mutex
+	entries  map[K]entry[V]
+	inflight map[K]*call[V]
+}
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func NewTTL[K comparable, V any](ttl time.Duration, maxEntries int) *TTL[K, V] {
+	return &TTL[K, V]{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[K]entry[V]),
+		inflight:   make(map[K]*call[V]),
+	}
+}
+
+// GetOrLoad returns the cached value of the key or loads and caches it. Errors are not cached.
+// The load is shared with other callers, so it is not cancelled with the ctx of the first one.
+func (c *TTL[K, V]) GetOrLoad(ctx context.Context, key K, load func(context.Context) (V, error)) (V, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && time.Now().Before(e.expiresAt) {
+		c.mu.Unlock()
+		return e.value, nil
+	}
+	if inflight, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-inflight.done:
+			return inflight.value, inflight.err
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+	current := &call[V]{done: make(chan struct{}), err: ErrLoadPanicked}
+	c.inflight[key] = current
+	c.mu.Unlock()
+
+	// Waiters are released even when the loader panics
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, key)
+		if current.err == nil {
+			c.evict()
+			c.entries[key] = entry[V]{value: current.value, expiresAt: time.Now().Add(c.ttl)}
+		}
+		c.mu.Unlock()
+		close(current.done)
+	}()
+	current.value, current.err = load(context.WithoutCancel(ctx))
+	return current.value, current.err
+}
+
+func (c *TTL[K, V]) Invalidate(key K) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
+
+// evict makes room for one entry, dropping expired entries first. Must be called with mu held.
+func (c *TTL[K, V]) evict() {
+	if c.maxEntries <= 0 || len(c.entries) < c.maxEntries {
+		return
+	}
+	now := time.Now()
+	for key, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	for key := range c.entries {
+		if len(c.entries) < c.maxEntries {
+			return
+		}
+		delete(c.entries, key)
+	}
+}